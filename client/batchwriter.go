@@ -0,0 +1,350 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+// BatchWriter option 기본값
+const (
+	DefaultBatchSize     = 1000
+	DefaultBatchBytes    = 512 * 1024
+	DefaultFlushInterval = time.Second
+	DefaultMaxInFlight   = 4
+	DefaultBufferSize    = 10 * DefaultBatchSize
+)
+
+// batchObjOverhead는 InputDataObj 하나가 tx에서 차지하는 rowKey, json field 이름 등의 고정 크기.
+const batchObjOverhead = 128
+
+// ErrBatchWriterClosed는 Close된 BatchWriter에 Write할 때의 error.
+var ErrBatchWriterClosed = errors.New("batch writer is closed")
+
+// BatchWriterOption은 BatchWriter의 설정을 바꾸는 functional option.
+type BatchWriterOption func(*BatchWriter)
+
+// WithBatchSize는 tx 하나에 담을 최대 InputDataObj 개수를 설정함.
+func WithBatchSize(size int) BatchWriterOption {
+	return func(writer *BatchWriter) {
+		writer.batchSize = size
+	}
+}
+
+// WithBatchBytes는 tx 하나의 최대 크기(byte)를 설정함. tendermint mempool의 max_tx_bytes보다 작아야 함.
+func WithBatchBytes(bytes int) BatchWriterOption {
+	return func(writer *BatchWriter) {
+		writer.batchBytes = bytes
+	}
+}
+
+// WithFlushInterval은 batch가 차지 않아도 flush하는 주기를 설정함. 0보다 커야 함.
+func WithFlushInterval(interval time.Duration) BatchWriterOption {
+	return func(writer *BatchWriter) {
+		writer.flushInterval = interval
+	}
+}
+
+// WithMaxInFlight는 동시에 commit을 기다리는 최대 tx 개수를 설정함. 0보다 커야 함.
+func WithMaxInFlight(maxInFlight int) BatchWriterOption {
+	return func(writer *BatchWriter) {
+		writer.maxInFlight = maxInFlight
+	}
+}
+
+// WithBufferSize는 flush되지 않은 InputDataObj를 담아둘 buffer 크기를 설정함. buffer가 가득 차면 Write가 block됨.
+func WithBufferSize(size int) BatchWriterOption {
+	return func(writer *BatchWriter) {
+		writer.bufferSize = size
+	}
+}
+
+// WithErrorHandler는 batch write가 실패했을 때 호출할 callback을 설정함. callback은 write 중인 goroutine에서 호출됨.
+func WithErrorHandler(handler func(dataObjs []InputDataObj, err error)) BatchWriterOption {
+	return func(writer *BatchWriter) {
+		writer.onError = handler
+	}
+}
+
+// writeRequest는 BatchWriter의 write loop로 보내는 요청. done이 nil이면 dataObj write, 아니면 flush 요청임.
+type writeRequest struct {
+	dataObj InputDataObj
+	done    chan error
+}
+
+// BatchWriter는 InputDataObj를 모아 개수, 크기, 주기에 따라 하나의 tx로 Put하는 buffered writer.
+// 최대 maxInFlight개의 tx를 동시에 보내며 buffer가 가득 차면 Write가 block됨.
+type BatchWriter struct {
+	client ContextClient
+
+	batchSize     int
+	batchBytes    int
+	flushInterval time.Duration
+	maxInFlight   int
+	bufferSize    int
+	onError       func(dataObjs []InputDataObj, err error)
+
+	input chan writeRequest
+
+	// quit은 Close가 write loop에 멈춤을 알리고, stopped는 write loop가 마지막 flush 후 멈췄음을 알림.
+	// closeErr는 마지막 flush의 error이며 stopped가 닫힌 뒤에만 read함.
+	quit     chan struct{}
+	stopped  chan struct{}
+	closeErr error
+
+	// write loop에서만 접근. batchRowKeys는 batch에 담긴 데이터의 rowKey별 index.
+	batch        []InputDataObj
+	batchRowKeys map[string]int
+	batchSz      int
+	semaphore    chan struct{}
+	inFlight     sync.WaitGroup
+
+	mtx    sync.RWMutex
+	closed bool
+
+	// errMtx는 이전 Flush 이후 처음 실패한 write의 error를 보호함.
+	errMtx sync.Mutex
+	err    error
+}
+
+// NewBatchWriter는 client로 write하는 BatchWriter를 생성하고 write loop를 시작함. 사용이 끝나면 Close를 호출해야 함.
+// batch 크기, flush 주기, 동시 tx 개수가 0 이하이거나 buffer 크기가 음수이면 error를 return.
+func NewBatchWriter(client ContextClient, options ...BatchWriterOption) (*BatchWriter, error) {
+	writer := &BatchWriter{
+		client:        client,
+		batchSize:     DefaultBatchSize,
+		batchBytes:    DefaultBatchBytes,
+		flushInterval: DefaultFlushInterval,
+		maxInFlight:   DefaultMaxInFlight,
+		bufferSize:    DefaultBufferSize,
+	}
+	for _, option := range options {
+		option(writer)
+	}
+	switch {
+	case writer.batchSize <= 0:
+		return nil, errors.Errorf("batch size must be positive, got %v", writer.batchSize)
+	case writer.batchBytes <= 0:
+		return nil, errors.Errorf("batch bytes must be positive, got %v", writer.batchBytes)
+	case writer.flushInterval <= 0:
+		return nil, errors.Errorf("flush interval must be positive, got %v", writer.flushInterval)
+	case writer.maxInFlight <= 0:
+		return nil, errors.Errorf("max in flight must be positive, got %v", writer.maxInFlight)
+	case writer.bufferSize < 0:
+		return nil, errors.Errorf("buffer size must not be negative, got %v", writer.bufferSize)
+	}
+	writer.input = make(chan writeRequest, writer.bufferSize)
+	writer.semaphore = make(chan struct{}, writer.maxInFlight)
+	writer.quit = make(chan struct{})
+	writer.stopped = make(chan struct{})
+
+	go writer.loop()
+
+	return writer, nil
+}
+
+// Write는 dataObj를 buffer에 추가함. buffer가 가득 차 있으면 자리가 날 때까지 혹은 ctx가 만료될 때까지 block됨.
+// write 결과는 Flush, Close의 return 값과 error handler로 전달됨.
+func (writer *BatchWriter) Write(ctx context.Context, dataObj InputDataObj) error {
+	writer.mtx.RLock()
+	defer writer.mtx.RUnlock()
+
+	if writer.closed {
+		return ErrBatchWriterClosed
+	}
+
+	select {
+	case writer.input <- writeRequest{dataObj: dataObj}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush는 buffer의 데이터를 모두 보내고 진행 중인 tx가 commit될 때까지 기다림.
+// 이전 Flush 이후 실패한 write가 있으면 첫 error를 return.
+func (writer *BatchWriter) Flush(ctx context.Context) error {
+	writer.mtx.RLock()
+	defer writer.mtx.RUnlock()
+
+	if writer.closed {
+		return ErrBatchWriterClosed
+	}
+	return writer.request(ctx)
+}
+
+// Close는 write loop에 멈춤을 알리고 buffer의 데이터와 진행 중인 tx가 모두 끝날 때까지 기다림.
+// ctx가 먼저 만료되어도 write loop는 남은 데이터를 보낸 뒤 멈춤. Close 이후의 Write, Flush는 ErrBatchWriterClosed를 return.
+func (writer *BatchWriter) Close(ctx context.Context) error {
+	writer.mtx.Lock()
+	if writer.closed {
+		writer.mtx.Unlock()
+		return ErrBatchWriterClosed
+	}
+	writer.closed = true
+	close(writer.quit)
+	writer.mtx.Unlock()
+
+	select {
+	case <-writer.stopped:
+		return writer.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (writer *BatchWriter) request(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case writer.input <- writeRequest{done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (writer *BatchWriter) loop() {
+	ticker := time.NewTicker(writer.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case req := <-writer.input:
+			writer.handle(req)
+		case <-ticker.C:
+			writer.dispatch()
+		case <-writer.quit:
+			writer.stop()
+			return
+		}
+	}
+}
+
+// handle은 write 요청이면 dataObj를 batch에 추가하고 flush 요청이면 batch를 보낸 뒤 진행 중인 tx를 기다려 결과를 알림.
+func (writer *BatchWriter) handle(req writeRequest) {
+	if req.done == nil {
+		writer.add(req.dataObj)
+		return
+	}
+
+	writer.dispatch()
+	writer.inFlight.Wait()
+	req.done <- writer.takeErr()
+}
+
+// stop은 buffer에 남은 요청을 처리하고 마지막 batch를 보낸 뒤 진행 중인 tx를 기다림.
+// Close가 closed를 설정한 뒤 quit을 닫으므로 이후 buffer에 새 요청이 들어오지 않음.
+func (writer *BatchWriter) stop() {
+	for {
+		select {
+		case req := <-writer.input:
+			writer.handle(req)
+		default:
+			writer.dispatch()
+			writer.inFlight.Wait()
+			writer.closeErr = writer.takeErr()
+			close(writer.stopped)
+			return
+		}
+	}
+}
+
+// add는 dataObj를 현재 batch에 추가하고 개수나 크기 제한에 도달하면 batch를 보냄.
+// 현재 batch에 같은 데이터가 이미 담겨 있으면 추가하지 않음. 같은 rowKey의 다른 데이터(e.g. overwrite로 고친 데이터)가 담겨 있으면
+// 하나의 tx에 담을 수 없으므로 현재 batch를 보내고 commit될 때까지 기다린 뒤 새 batch에 추가함.
+func (writer *BatchWriter) add(dataObj InputDataObj) {
+	rowKey := string(inputRowKey(dataObj))
+	if i, ok := writer.batchRowKeys[rowKey]; ok {
+		if sameInputData(writer.batch[i], dataObj) {
+			return
+		}
+		writer.dispatch()
+		writer.inFlight.Wait()
+	}
+	size := estimateSize(dataObj)
+	if len(writer.batch) > 0 && writer.batchSz+size > writer.batchBytes {
+		writer.dispatch()
+	}
+
+	if writer.batchRowKeys == nil {
+		writer.batchRowKeys = make(map[string]int)
+	}
+	writer.batchRowKeys[rowKey] = len(writer.batch)
+	writer.batch = append(writer.batch, dataObj)
+	writer.batchSz += size
+	if len(writer.batch) >= writer.batchSize || writer.batchSz >= writer.batchBytes {
+		writer.dispatch()
+	}
+}
+
+// dispatch는 현재 batch를 별도 goroutine에서 Put함. maxInFlight개의 tx가 진행 중이면 하나가 끝날 때까지 block되며
+// 그동안 buffer가 차서 Write가 block됨.
+func (writer *BatchWriter) dispatch() {
+	if len(writer.batch) == 0 {
+		return
+	}
+	batch := writer.batch
+	writer.batch = nil
+	writer.batchRowKeys = nil
+	writer.batchSz = 0
+
+	writer.semaphore <- struct{}{}
+	writer.inFlight.Add(1)
+	go func() {
+		defer func() {
+			<-writer.semaphore
+			writer.inFlight.Done()
+		}()
+
+		if err := writer.put(batch); err != nil {
+			writer.setErr(err)
+			if writer.onError != nil {
+				writer.onError(batch, err)
+			}
+		}
+	}()
+}
+
+func (writer *BatchWriter) put(batch []InputDataObj) error {
+	res, err := writer.client.PutContext(context.Background(), batch)
+	if err != nil {
+		return errors.Wrap(err, "batch put failed")
+	}
+	switch {
+	case res.CheckTx.IsErr():
+		return errors.Errorf("batch put failed on CheckTx: %s", res.CheckTx.Log)
+	case res.DeliverTx.IsErr():
+		return errors.Errorf("batch put failed on DeliverTx: %s", res.DeliverTx.Log)
+	}
+
+	return nil
+}
+
+func (writer *BatchWriter) setErr(err error) {
+	writer.errMtx.Lock()
+	defer writer.errMtx.Unlock()
+
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *BatchWriter) takeErr() error {
+	writer.errMtx.Lock()
+	defer writer.errMtx.Unlock()
+
+	err := writer.err
+	writer.err = nil
+	return err
+}
+
+// estimateSize는 dataObj가 tx에서 차지하는 대략적인 크기를 return. Qualifier와 Data는 base64로 encoding됨.
+func estimateSize(dataObj InputDataObj) int {
+	return batchObjOverhead + len(dataObj.OwnerId) + (len(dataObj.Qualifier)+len(dataObj.Data)+2)/3*4
+}
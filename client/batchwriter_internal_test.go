@@ -0,0 +1,284 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient는 PutContext로 받은 batch를 기록하는 Client.
+type fakeClient struct {
+	ContextClient
+
+	mtx     sync.Mutex
+	batches [][]InputDataObj
+	putErr  error
+	block   chan struct{}
+}
+
+func (c *fakeClient) PutContext(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTxCommit, error) {
+	if c.block != nil {
+		<-c.block
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.batches = append(c.batches, dataObjs)
+	if c.putErr != nil {
+		return nil, c.putErr
+	}
+	return &ctypes.ResultBroadcastTxCommit{DeliverTx: abciTypes.ResponseDeliverTx{Code: code.CodeTypeOK}}, nil
+}
+
+func (c *fakeClient) batchSizes() []int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var sizes []int
+	for _, batch := range c.batches {
+		sizes = append(sizes, len(batch))
+	}
+	// batch는 동시에 Put되므로 순서와 무관하게 비교
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+// givenTimestamp는 givenInputDataObj마다 서로 다른 timestamp를 부여하기 위한 counter.
+var givenTimestamp = uint64(time.Now().UnixNano())
+
+func givenInputDataObj(data []byte) InputDataObj {
+	return InputDataObj{Timestamp: atomic.AddUint64(&givenTimestamp, 1), OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: data}
+}
+
+func TestBatchWriter_flushByCount(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{}
+	writer, err := NewBatchWriter(client, WithBatchSize(3), WithFlushInterval(time.Hour))
+	require.Nil(err)
+
+	//when
+	for i := 0; i < 7; i++ {
+		require.Nil(writer.Write(context.Background(), givenInputDataObj([]byte("data"))))
+	}
+	require.Nil(writer.Flush(context.Background()))
+
+	//then
+	require.Equal([]int{3, 3, 1}, client.batchSizes())
+	require.Nil(writer.Close(context.Background()))
+}
+
+func TestBatchWriter_duplicate(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{}
+	writer, err := NewBatchWriter(client, WithFlushInterval(time.Hour))
+	require.Nil(err)
+	dataObj := givenInputDataObj([]byte("data"))
+	otherObj := dataObj
+	otherObj.Data = []byte("other")
+
+	//when
+	// 같은 데이터를 반복하여 write함
+	for i := 0; i < 3; i++ {
+		require.Nil(writer.Write(context.Background(), dataObj))
+	}
+	require.Nil(writer.Write(context.Background(), otherObj))
+	require.Nil(writer.Close(context.Background()))
+
+	//then
+	// batch에는 같은 데이터가 한 번만 담김
+	require.Equal([][]InputDataObj{{dataObj, otherObj}}, client.batches)
+}
+
+func TestBatchWriter_conflict(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{}
+	writer, err := NewBatchWriter(client, WithFlushInterval(time.Hour))
+	require.Nil(err)
+	dataObj := givenInputDataObj([]byte("data"))
+	dataObj.Id = []byte("row1")
+	otherObj := givenInputDataObj([]byte("other"))
+	correctionObj := dataObj
+	correctionObj.Data = []byte("corrected")
+	correctionObj.Conflict = "overwrite"
+
+	//when
+	// 같은 rowKey의 다른 데이터를 write함
+	require.Nil(writer.Write(context.Background(), dataObj))
+	require.Nil(writer.Write(context.Background(), otherObj))
+	require.Nil(writer.Write(context.Background(), correctionObj))
+	err = writer.Close(context.Background())
+
+	//then
+	// 이전 batch를 먼저 보낸 뒤 새 batch에 담으므로 어떤 데이터도 버려지지 않음
+	require.Nil(err)
+	require.Equal([][]InputDataObj{{dataObj, otherObj}, {correctionObj}}, client.batches)
+}
+
+func TestNewBatchWriter_invalidOptions(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	options := []BatchWriterOption{WithBatchSize(0), WithBatchBytes(0), WithFlushInterval(0), WithMaxInFlight(0), WithBufferSize(-1)}
+
+	for _, option := range options {
+		//when
+		writer, err := NewBatchWriter(&fakeClient{}, option)
+
+		//then
+		require.NotNil(err)
+		require.Nil(writer)
+	}
+}
+
+func TestBatchWriter_flushByBytes(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{}
+	data := make([]byte, 300)
+	writer, err := NewBatchWriter(client, WithBatchBytes(2*estimateSize(givenInputDataObj(data))), WithFlushInterval(time.Hour))
+	require.Nil(err)
+
+	//when
+	for i := 0; i < 5; i++ {
+		require.Nil(writer.Write(context.Background(), givenInputDataObj(data)))
+	}
+	require.Nil(writer.Close(context.Background()))
+
+	//then
+	require.Equal([]int{2, 2, 1}, client.batchSizes())
+}
+
+func TestBatchWriter_flushByInterval(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{}
+	writer, err := NewBatchWriter(client, WithFlushInterval(10*time.Millisecond))
+	require.Nil(err)
+	defer writer.Close(context.Background())
+
+	//when
+	require.Nil(writer.Write(context.Background(), givenInputDataObj([]byte("data"))))
+
+	//then
+	time.Sleep(100 * time.Millisecond)
+	require.Equal([]int{1}, client.batchSizes())
+}
+
+func TestBatchWriter_error(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{putErr: errors.New("put failed")}
+	var failedObjs []InputDataObj
+	var handlerMtx sync.Mutex
+	writer, err := NewBatchWriter(client, WithBatchSize(2), WithFlushInterval(time.Hour), WithErrorHandler(func(dataObjs []InputDataObj, err error) {
+		handlerMtx.Lock()
+		defer handlerMtx.Unlock()
+		failedObjs = append(failedObjs, dataObjs...)
+	}))
+	require.Nil(err)
+
+	//when
+	for i := 0; i < 3; i++ {
+		require.Nil(writer.Write(context.Background(), givenInputDataObj([]byte("data"))))
+	}
+	err = writer.Flush(context.Background())
+
+	//then
+	require.NotNil(err)
+	handlerMtx.Lock()
+	require.Equal(3, len(failedObjs))
+	handlerMtx.Unlock()
+
+	// error는 한 번만 return
+	require.Nil(writer.Flush(context.Background()))
+	require.Nil(writer.Close(context.Background()))
+}
+
+func TestBatchWriter_backpressure(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{block: make(chan struct{})}
+	writer, err := NewBatchWriter(client, WithBatchSize(1), WithMaxInFlight(1), WithBufferSize(1), WithFlushInterval(time.Hour))
+	require.Nil(err)
+
+	//when
+	// 첫 번째는 in-flight, 두 번째는 dispatch 대기, 세 번째는 buffer
+	for i := 0; i < 3; i++ {
+		require.Nil(writer.Write(context.Background(), givenInputDataObj([]byte("data"))))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = writer.Write(ctx, givenInputDataObj([]byte("data")))
+
+	//then
+	require.Equal(context.DeadlineExceeded, err)
+
+	close(client.block)
+	require.Nil(writer.Close(context.Background()))
+	require.Equal([]int{1, 1, 1}, client.batchSizes())
+}
+
+func TestBatchWriter_Close(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{}
+	writer, err := NewBatchWriter(client, WithFlushInterval(time.Hour))
+	require.Nil(err)
+	require.Nil(writer.Write(context.Background(), givenInputDataObj([]byte("data"))))
+
+	//when
+	require.Nil(writer.Close(context.Background()))
+
+	//then
+	require.Equal([]int{1}, client.batchSizes())
+	require.Equal(ErrBatchWriterClosed, writer.Write(context.Background(), givenInputDataObj([]byte("data"))))
+	require.Equal(ErrBatchWriterClosed, writer.Flush(context.Background()))
+	require.Equal(ErrBatchWriterClosed, writer.Close(context.Background()))
+}
+
+func TestBatchWriter_Close_timeout(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client := &fakeClient{block: make(chan struct{})}
+	writer, err := NewBatchWriter(client, WithBatchSize(1), WithMaxInFlight(1), WithFlushInterval(time.Hour))
+	require.Nil(err)
+	for i := 0; i < 3; i++ {
+		require.Nil(writer.Write(context.Background(), givenInputDataObj([]byte("data"))))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	//when
+	err = writer.Close(ctx)
+
+	//then
+	// ctx가 만료되어 Close가 먼저 return해도 write loop는 남은 데이터를 보낸 뒤 멈춤
+	require.Equal(context.DeadlineExceeded, err)
+	close(client.block)
+	select {
+	case <-writer.stopped:
+	case <-time.After(time.Second):
+		require.Fail("write loop is not stopped")
+	}
+	require.Equal([]int{1, 1, 1}, client.batchSizes())
+}
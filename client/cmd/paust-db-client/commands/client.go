@@ -1,14 +1,21 @@
 package commands
 
 import (
+	"bufio"
+	"context"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/paust-team/paust-db/client"
 	"github.com/paust-team/paust-db/client/util"
 	"github.com/paust-team/paust-db/consts"
 	"github.com/spf13/cobra"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -72,6 +79,18 @@ var putCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		id, err := cmd.Flags().GetBytesBase64("id")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		conflict, err := cmd.Flags().GetString("conflict")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
 		endpoint, err := cmd.Flags().GetString("endpoint")
 		if err != nil {
 			fmt.Println(err)
@@ -115,7 +134,10 @@ var putCmd = &cobra.Command{
 				fmt.Printf("wrong ownerId length. Expect %v or below, got %v", consts.OwnerIdLenLimit, len(ownerId))
 				os.Exit(1)
 			}
-			if timestamp == 0 {
+			// id가 주어지면 id의 timestamp를 사용함
+			if id != nil {
+				timestamp = 0
+			} else if timestamp == 0 {
 				fmt.Printf("timestamp must not be 0.")
 				os.Exit(1)
 			}
@@ -124,7 +146,20 @@ var putCmd = &cobra.Command{
 				fmt.Println(err)
 				os.Exit(1)
 			}
-			inputDataObjs = append(inputDataObjs, client.InputDataObj{Timestamp: timestamp, OwnerId: ownerId, Qualifier: qualifier, Data: data})
+			inputDataObjs = append(inputDataObjs, client.InputDataObj{Timestamp: timestamp, OwnerId: ownerId, Qualifier: qualifier, Data: data, Id: id})
+		}
+
+		// conflict policy가 명시되지 않은 데이터에 --conflict를 적용함
+		setConflict := func(dataObjs []client.InputDataObj) {
+			for i := range dataObjs {
+				if dataObjs[i].Conflict == "" {
+					dataObjs[i].Conflict = conflict
+				}
+			}
+		}
+		setConflict(inputDataObjs)
+		for _, dataObjs := range inputDataObjMap {
+			setConflict(dataObjs)
 		}
 
 		HTTPClient := client.NewHTTPClient(endpoint)
@@ -207,9 +242,17 @@ var queryCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		HTTPClient := client.NewHTTPClient(endpoint)
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		queryObj := client.InputQueryObj{Start: start, End: end, OwnerId: ownerId, Qualifier: qualifier, Height: height}
+		setFilterFlags(cmd, &queryObj)
+		HTTPClient := newQueryClient(cmd, endpoint)
 		startTime := time.Now()
-		res, err := HTTPClient.Query(client.InputQueryObj{Start: start, End: end, OwnerId: ownerId, Qualifier: qualifier})
+		res, err := HTTPClient.Query(queryObj)
 		endTime := time.Now()
 		if err != nil {
 			fmt.Printf("Query err: %v\n", err)
@@ -226,6 +269,87 @@ var queryCmd = &cobra.Command{
 	},
 }
 
+var queryDataCmd = &cobra.Command{
+	Use:   "querydata start end",
+	Args:  cobra.ExactArgs(2),
+	Short: "Query DB for metadata and data",
+	Long: `Query DB for metadata and data together.
+'start' and 'end' are unix timestamp in nanosecond.
+If chunk is greater than 0, results are read and printed in chunks of the given size.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		start, err := strconv.ParseUint(args[0], 0, 64)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		end, err := strconv.ParseUint(args[1], 0, 64)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		ownerId, err := cmd.Flags().GetString("ownerId")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		qualifier, err := cmd.Flags().GetString("qualifier")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		chunkSize, err := cmd.Flags().GetInt("chunk")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		endpoint, err := cmd.Flags().GetString("endpoint")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		HTTPClient := newQueryClient(cmd, endpoint)
+		queryObj := client.InputQueryObj{Start: start, End: end, OwnerId: ownerId, Qualifier: qualifier, Height: height}
+		setFilterFlags(cmd, &queryObj)
+		printData := func(outputDataObjs []client.OutputDataObj) error {
+			jsonBytes, err := json.MarshalIndent(outputDataObjs, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(jsonBytes))
+			return nil
+		}
+
+		startTime := time.Now()
+		if chunkSize > 0 {
+			err = HTTPClient.QueryDataStream(context.Background(), queryObj, chunkSize, printData)
+		} else {
+			var res *client.ResultQueryData
+			if res, err = HTTPClient.QueryData(context.Background(), queryObj); err == nil {
+				err = printData(res.Data)
+			}
+		}
+		endTime := time.Now()
+		if err != nil {
+			fmt.Printf("QueryData err: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("query data success. elapsed time: %v\n", endTime.Sub(startTime).Round(time.Millisecond).String())
+	},
+}
+
 var fetchCmd = &cobra.Command{
 	Use:   "fetch [id...]",
 	Short: "Fetch DB for real data",
@@ -284,7 +408,14 @@ var fetchCmd = &cobra.Command{
 			}
 		}
 
-		HTTPClient := client.NewHTTPClient(endpoint)
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		inputFetchObj.Height = height
+		HTTPClient := newQueryClient(cmd, endpoint)
 		startTime := time.Now()
 		res, err := HTTPClient.Fetch(*inputFetchObj)
 		endTime := time.Now()
@@ -303,22 +434,387 @@ var fetchCmd = &cobra.Command{
 	},
 }
 
+var seriesCmd = &cobra.Command{
+	Use:   "series",
+	Args:  cobra.NoArgs,
+	Short: "List series",
+	Long: `List series matching ownerId and qualifier.
+A series is the set of data sharing the same ownerId and qualifier.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ownerId, err := cmd.Flags().GetString("ownerId")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		qualifier, err := cmd.Flags().GetString("qualifier")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		endpoint, err := cmd.Flags().GetString("endpoint")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		HTTPClient := client.NewHTTPClient(endpoint)
+		res, err := HTTPClient.Series(context.Background(), client.InputSeriesObj{OwnerId: ownerId, Qualifier: qualifier, Height: height})
+		if err != nil {
+			fmt.Printf("Series err: %v\n", err)
+			os.Exit(1)
+		}
+
+		jsonBytes, err := json.Marshal(res.Data)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("series success.")
+		fmt.Println(string(jsonBytes))
+	},
+}
+
+var countCmd = &cobra.Command{
+	Use:   "count start end",
+	Args:  cobra.ExactArgs(2),
+	Short: "Count data in DB",
+	Long: `Count data and distinct series, ownerIds and qualifiers in DB.
+'start' and 'end' are unix timestamp in nanosecond.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		start, err := strconv.ParseUint(args[0], 0, 64)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		end, err := strconv.ParseUint(args[1], 0, 64)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		ownerId, err := cmd.Flags().GetString("ownerId")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		qualifier, err := cmd.Flags().GetString("qualifier")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		endpoint, err := cmd.Flags().GetString("endpoint")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		queryObj := client.InputQueryObj{Start: start, End: end, OwnerId: ownerId, Qualifier: qualifier, Height: height}
+		setFilterFlags(cmd, &queryObj)
+		HTTPClient := client.NewHTTPClient(endpoint)
+		res, err := HTTPClient.Count(context.Background(), queryObj)
+		if err != nil {
+			fmt.Printf("Count err: %v\n", err)
+			os.Exit(1)
+		}
+
+		jsonBytes, err := json.Marshal(res)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("count success.")
+		fmt.Println(string(jsonBytes))
+	},
+}
+
+var latestCmd = &cobra.Command{
+	Use:   "latest",
+	Args:  cobra.NoArgs,
+	Short: "Read the latest data of each series",
+	Long: `Read the data with the newest timestamp of each series matching any of the given ownerIds and qualifiers.
+Empty ownerIds or qualifiers match all values.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ownerIds, err := cmd.Flags().GetStringSlice("ownerId")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		qualifiers, err := cmd.Flags().GetStringArray("qualifier")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		endpoint, err := cmd.Flags().GetString("endpoint")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		HTTPClient := newQueryClient(cmd, endpoint)
+		res, err := HTTPClient.Latest(context.Background(), client.InputLatestObj{OwnerIds: ownerIds, Qualifiers: qualifiers, Height: height})
+		if err != nil {
+			fmt.Printf("Latest err: %v\n", err)
+			os.Exit(1)
+		}
+
+		jsonBytes, err := json.Marshal(res.Data)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("latest success.")
+		fmt.Println(string(jsonBytes))
+	},
+}
+
+var qlCmd = &cobra.Command{
+	Use:   "ql [query]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Run query language statements",
+	Long: `Run a query language statement such as
+SELECT avg(value) FROM qualifier.type='temperature' WHERE owner='x' AND time >= '2019-01-01T00:00:00Z' AND time < '2019-01-02T00:00:00Z' GROUP BY time(1m)
+If no query is given, read statements line by line from stdin until 'exit' or EOF.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		endpoint, err := cmd.Flags().GetString("endpoint")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		HTTPClient := newQueryClient(cmd, endpoint)
+		runQL := func(query string) error {
+			res, err := HTTPClient.QL(context.Background(), client.InputQLObj{Query: query, Height: height})
+			if err != nil {
+				return err
+			}
+
+			res.Proof = nil
+			jsonBytes, err := json.Marshal(res)
+			if err != nil {
+				return err
+			}
+			fmt.Println("ql success.")
+			fmt.Println(string(jsonBytes))
+			return nil
+		}
+
+		if len(args) == 1 {
+			if err := runQL(args[0]); err != nil {
+				fmt.Printf("QL err: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// interactive mode에서는 error가 나도 다음 statement를 계속 read함
+		scanner := bufio.NewScanner(os.Stdin)
+		for fmt.Print("paust-db> "); scanner.Scan(); fmt.Print("paust-db> ") {
+			query := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ";"))
+			switch strings.ToLower(query) {
+			case "":
+				continue
+			case "exit", "quit":
+				return
+			}
+			if err := runQL(query); err != nil {
+				fmt.Printf("QL err: %v\n", err)
+			}
+		}
+		fmt.Println()
+		if err := scanner.Err(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+var rangeCmd = &cobra.Command{
+	Use:   "range start end",
+	Args:  cobra.ExactArgs(2),
+	Short: "Read numeric data from chunk storage",
+	Long: `Read numeric data of each series from chunk storage.
+'start' and 'end' are unix timestamp in nanosecond.
+If step is given, data are aggregated into buckets of the step.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		start, err := strconv.ParseUint(args[0], 0, 64)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		end, err := strconv.ParseUint(args[1], 0, 64)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		ownerId, err := cmd.Flags().GetString("ownerId")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		qualifier, err := cmd.Flags().GetString("qualifier")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		step, err := cmd.Flags().GetDuration("step")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		endpoint, err := cmd.Flags().GetString("endpoint")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		HTTPClient := client.NewHTTPClient(endpoint)
+		res, err := HTTPClient.Range(context.Background(), client.InputRangeObj{Start: start, End: end, OwnerId: ownerId, Qualifier: qualifier, Step: uint64(step), Height: height})
+		if err != nil {
+			fmt.Printf("Range err: %v\n", err)
+			os.Exit(1)
+		}
+
+		jsonBytes, err := json.Marshal(res.Data)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("range success.")
+		fmt.Println(string(jsonBytes))
+	},
+}
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe",
+	Args:  cobra.NoArgs,
+	Short: "Subscribe to newly committed data",
+	Long: `Subscribe to newly committed data matching ownerId and qualifier.
+Each data is printed as a JSON object until interrupted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ownerId, err := cmd.Flags().GetString("ownerId")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		qualifier, err := cmd.Flags().GetString("qualifier")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		endpoint, err := cmd.Flags().GetString("endpoint")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			cancel()
+		}()
+
+		HTTPClient := client.NewHTTPClient(endpoint)
+		subscription, err := HTTPClient.Subscribe(ctx, client.InputSubscribeObj{OwnerId: ownerId, Qualifier: qualifier})
+		if err != nil {
+			fmt.Printf("Subscribe err: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("subscribe success.")
+		for outputDataObj := range subscription.Out() {
+			jsonBytes, err := json.Marshal(outputDataObj)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Println(string(jsonBytes))
+		}
+		if subscription.Err() == client.ErrSubscriptionDropped {
+			fmt.Println("Subscribe err: subscription dropped because the output is too slow")
+			os.Exit(1)
+		}
+	},
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check status of paust-db",
 	Run: func(cmd *cobra.Command, args []string) {
-		endpoint, err := cmd.Flags().GetString("endpoint")
+		master, err := cmd.Flags().GetString("master")
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 
-		HTTPClient := client.NewHTTPClient(endpoint)
-		_, err = HTTPClient.Query(client.InputQueryObj{Start: 1, End: 2, OwnerId: "", Qualifier: ""})
+		status, err := client.GetMasterStatus(master)
 		if err != nil {
 			fmt.Println("not running")
-		} else {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if status.Ready {
 			fmt.Println("running")
+		} else {
+			fmt.Println("not ready")
+		}
+		fmt.Printf("db open: %v\n", status.DBOpen)
+		fmt.Printf("db writable: %v\n", status.DBWritable)
+		fmt.Printf("abci handshaked: %v\n", status.Handshaked)
+		fmt.Printf("last height: %v\n", status.LastHeight)
+		if status.LastBlockTime.IsZero() {
+			fmt.Println("last block time: -")
+		} else {
+			fmt.Printf("last block time: %v (%v ago)\n", status.LastBlockTime.Format(time.RFC3339), status.SinceLastBlock)
+		}
+		if !status.Ready {
+			os.Exit(1)
 		}
 	},
 }
@@ -331,16 +827,149 @@ func init() {
 	putCmd.Flags().StringP("directory", "d", "", "Directory path")
 	putCmd.Flags().BoolP("stdin", "s", false, "Input json data from standard input")
 	putCmd.Flags().BoolP("recursive", "r", false, "Write all files and folders recursively")
+	putCmd.Flags().BytesBase64("id", nil, "Base64 encoded id of the data to overwrite. Timestamp is taken from the id")
+	putCmd.Flags().String("conflict", "", "Conflict policy when the id already exists(reject|overwrite|keep-first)")
 	putCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
 	fetchCmd.Flags().BoolP("stdin", "s", false, "Input json data from standard input")
 	fetchCmd.Flags().StringP("file", "f", "", "File path")
 	fetchCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	fetchCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	fetchCmd.Flags().String("chain-id", "", "Verify results with light client of the given chain id")
+	fetchCmd.Flags().String("trust-dir", "", "Directory to store trusted headers of light client")
+	fetchCmd.Flags().Int64("trust-height", 0, "Height of the header to trust when no trusted header is stored")
+	fetchCmd.Flags().String("trust-hash", "", "Hex encoded hash of the header at trust-height, obtained from a source other than the endpoint")
 	queryCmd.Flags().StringP("ownerId", "o", "", "Data owner id 64 characters or below")
 	queryCmd.Flags().StringP("qualifier", "q", "", "Data qualifier(JSON object)")
 	queryCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
-	statusCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	queryCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	queryCmd.Flags().String("chain-id", "", "Verify results with light client of the given chain id")
+	queryCmd.Flags().String("trust-dir", "", "Directory to store trusted headers of light client")
+	queryCmd.Flags().Int64("trust-height", 0, "Height of the header to trust when no trusted header is stored")
+	queryCmd.Flags().String("trust-hash", "", "Hex encoded hash of the header at trust-height, obtained from a source other than the endpoint")
+	queryDataCmd.Flags().StringP("ownerId", "o", "", "Data owner id 64 characters or below")
+	queryDataCmd.Flags().StringP("qualifier", "q", "", "Data qualifier(JSON object)")
+	queryDataCmd.Flags().IntP("chunk", "c", 0, "Read and print results in chunks of the given size. 0 reads all at once")
+	queryDataCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	queryDataCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	queryDataCmd.Flags().String("chain-id", "", "Verify results with light client of the given chain id")
+	queryDataCmd.Flags().String("trust-dir", "", "Directory to store trusted headers of light client")
+	queryDataCmd.Flags().Int64("trust-height", 0, "Height of the header to trust when no trusted header is stored")
+	queryDataCmd.Flags().String("trust-hash", "", "Hex encoded hash of the header at trust-height, obtained from a source other than the endpoint")
+	seriesCmd.Flags().StringP("ownerId", "o", "", "Data owner id 64 characters or below")
+	seriesCmd.Flags().StringP("qualifier", "q", "", "Data qualifier(JSON object)")
+	seriesCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	seriesCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	countCmd.Flags().StringP("ownerId", "o", "", "Data owner id 64 characters or below")
+	countCmd.Flags().StringP("qualifier", "q", "", "Data qualifier(JSON object)")
+	countCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	countCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	latestCmd.Flags().StringSliceP("ownerId", "o", nil, "Data owner ids 64 characters or below. Repeat or separate with comma")
+	latestCmd.Flags().StringArrayP("qualifier", "q", nil, "Data qualifiers(JSON object). Repeat for each qualifier")
+	latestCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	latestCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	latestCmd.Flags().String("chain-id", "", "Verify results with light client of the given chain id")
+	latestCmd.Flags().String("trust-dir", "", "Directory to store trusted headers of light client")
+	latestCmd.Flags().Int64("trust-height", 0, "Height of the header to trust when no trusted header is stored")
+	latestCmd.Flags().String("trust-hash", "", "Hex encoded hash of the header at trust-height, obtained from a source other than the endpoint")
+	rangeCmd.Flags().StringP("ownerId", "o", "", "Data owner id 64 characters or below")
+	rangeCmd.Flags().StringP("qualifier", "q", "", "Data qualifier(JSON object)")
+	rangeCmd.Flags().Duration("step", 0, "Aggregate data into buckets of the given duration. 0 reads raw points")
+	rangeCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	rangeCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	qlCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	qlCmd.Flags().Int64("height", 0, "Block height of the state to read. 0 reads the latest state")
+	qlCmd.Flags().String("chain-id", "", "Verify results with light client of the given chain id")
+	qlCmd.Flags().String("trust-dir", "", "Directory to store trusted headers of light client")
+	qlCmd.Flags().Int64("trust-height", 0, "Height of the header to trust when no trusted header is stored")
+	qlCmd.Flags().String("trust-hash", "", "Hex encoded hash of the header at trust-height, obtained from a source other than the endpoint")
+	subscribeCmd.Flags().StringP("ownerId", "o", "", "Data owner id 64 characters or below")
+	subscribeCmd.Flags().StringP("qualifier", "q", "", "Data qualifier(JSON object)")
+	subscribeCmd.Flags().StringP("endpoint", "e", "localhost:26657", "Endpoint of paust-db")
+	statusCmd.Flags().StringP("master", "m", "localhost:26661", "HTTP endpoint of paust-db master")
+	for _, cmd := range []*cobra.Command{queryCmd, queryDataCmd, countCmd} {
+		addFilterFlags(cmd)
+	}
 	ClientCmd.AddCommand(putCmd)
 	ClientCmd.AddCommand(queryCmd)
+	ClientCmd.AddCommand(queryDataCmd)
 	ClientCmd.AddCommand(fetchCmd)
+	ClientCmd.AddCommand(seriesCmd)
+	ClientCmd.AddCommand(countCmd)
+	ClientCmd.AddCommand(latestCmd)
+	ClientCmd.AddCommand(rangeCmd)
+	ClientCmd.AddCommand(qlCmd)
+	ClientCmd.AddCommand(subscribeCmd)
 	ClientCmd.AddCommand(statusCmd)
 }
+
+// newQueryClient는 chain-id flag가 있으면 query 결과를 light client로 검증하는 HTTPClient를, 없으면 일반 HTTPClient를 생성함.
+func newQueryClient(cmd *cobra.Command, endpoint string) *client.HTTPClient {
+	chainID, err := cmd.Flags().GetString("chain-id")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if chainID == "" {
+		return client.NewHTTPClient(endpoint)
+	}
+
+	trustDir, err := cmd.Flags().GetString("trust-dir")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	trustHeight, err := cmd.Flags().GetInt64("trust-height")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	trustHashHex, err := cmd.Flags().GetString("trust-hash")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	trustHash, err := hex.DecodeString(trustHashHex)
+	if err != nil {
+		fmt.Printf("trust-hash decode err: %v\n", err)
+		os.Exit(1)
+	}
+
+	HTTPClient, err := client.NewVerifyingHTTPClient(chainID, endpoint, trustDir, client.TrustOptions{Height: trustHeight, Hash: trustHash})
+	if err != nil {
+		fmt.Printf("NewVerifyingHTTPClient err: %v\n", err)
+		os.Exit(1)
+	}
+	return HTTPClient
+}
+
+// addFilterFlags는 query 계열 command에 여러 ownerId, qualifier와 ownerId prefix 조건 flag를 추가함.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("ownerIds", nil, "Data owner ids to match any of. Repeat or separate with comma")
+	cmd.Flags().StringArray("qualifiers", nil, "Data qualifiers(JSON object) to match any of. Repeat for each qualifier")
+	cmd.Flags().String("ownerIdPrefix", "", "Prefix that data owner ids must start with")
+}
+
+// setFilterFlags는 addFilterFlags로 추가한 flag 값을 queryObj에 담음.
+func setFilterFlags(cmd *cobra.Command, queryObj *client.InputQueryObj) {
+	ownerIds, err := cmd.Flags().GetStringSlice("ownerIds")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	qualifiers, err := cmd.Flags().GetStringArray("qualifiers")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	ownerIdPrefix, err := cmd.Flags().GetString("ownerIdPrefix")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	queryObj.OwnerIds, queryObj.Qualifiers, queryObj.OwnerIdPrefix = ownerIds, qualifiers, ownerIdPrefix
+}
@@ -1,43 +1,284 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"github.com/paust-team/paust-db/consts"
 	"github.com/paust-team/paust-db/types"
 	"github.com/pkg/errors"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
 	rpcClient "github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
-	"math/rand"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"math"
+	"strings"
+	"sync"
 	"time"
 )
 
 // HTTPClient is a HTTP jsonrpc implementation of Client.
 type HTTPClient struct {
+	// caller는 rpc 호출을, rpcClient는 websocket event subscription을 담당함.
+	caller    rpcCaller
 	rpcClient rpcClient.Client
+
+	timeout    time.Duration
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	// appHashProvider가 있으면 모든 query 결과를 proof로 검증함.
+	appHashProvider AppHashProvider
+
+	// eventMtx는 tendermint event subscription의 시작과 해제를, subMtx는 eventSubs와 각 eventSubscription의 subs를 보호함.
+	// eventSubs는 event query마다의 eventSubscription.
+	eventMtx  sync.Mutex
+	subMtx    sync.Mutex
+	eventSubs map[string]*eventSubscription
 }
 
+var (
+	_ Client         = (*HTTPClient)(nil)
+	_ ContextClient  = (*HTTPClient)(nil)
+	_ DecodedQuerier = (*HTTPClient)(nil)
+	_ HistoryFetcher = (*HTTPClient)(nil)
+	_ SeriesQuerier  = (*HTTPClient)(nil)
+	_ RangeQuerier   = (*HTTPClient)(nil)
+	_ QLQuerier      = (*HTTPClient)(nil)
+	_ TxStatusClient = (*HTTPClient)(nil)
+	_ Subscriber     = (*HTTPClient)(nil)
+)
+
 // NewHTTPClient creates HTTPClient with the given remote address.
-func NewHTTPClient(remote string) *HTTPClient {
+// options가 없으면 DefaultTimeout, DefaultMaxRetries, DefaultMinBackoff, DefaultMaxBackoff를 사용함.
+func NewHTTPClient(remote string, options ...Option) *HTTPClient {
 	c := rpcClient.NewHTTP(remote, consts.WsEndpoint)
-	rand.Seed(time.Now().UnixNano())
 
-	return &HTTPClient{
-		rpcClient: c,
+	client := &HTTPClient{
+		caller:     newJSONRPCCaller(remote),
+		rpcClient:  c,
+		timeout:    DefaultTimeout,
+		maxRetries: DefaultMaxRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, option := range options {
+		option(client)
 	}
+
+	return client
 }
 
 func (client *HTTPClient) Put(dataObjs []InputDataObj) (*ctypes.ResultBroadcastTxCommit, error) {
+	return client.PutContext(context.Background(), dataObjs)
+}
+
+func (client *HTTPClient) PutContext(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTxCommit, error) {
+	// 재시도해도 같은 rowKey로 write되도록 tx는 한 번만 만듦
+	tx, err := makeTx(dataObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.broadcastTxCommit(ctx, tx)
+}
+
+// broadcastTxCommit은 tx를 broadcast하고 commit 결과를 return. 일시적인 error는 같은 tx로 재시도함.
+func (client *HTTPClient) broadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	res, err := client.retry(ctx, func(ctx context.Context) (interface{}, error) {
+		bres, err := client.caller.BroadcastTxCommit(ctx, tx)
+		if err != nil && strings.Contains(err.Error(), txInCacheErrMsg) {
+			// 이전 시도의 tx가 이미 broadcast된 경우
+			return client.committedTx(ctx, tx)
+		}
+		return bres, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ctypes.ResultBroadcastTxCommit), nil
+}
+
+func (client *HTTPClient) PutSync(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTx, error) {
+	tx, err := makeTx(dataObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.broadcastTx(ctx, tx, client.caller.BroadcastTxSync)
+}
+
+func (client *HTTPClient) PutAsync(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTx, error) {
+	tx, err := makeTx(dataObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.broadcastTx(ctx, tx, client.caller.BroadcastTxAsync)
+}
+
+// broadcastTx는 BroadcastTxSync 혹은 BroadcastTxAsync로 tx를 broadcast함. 일시적인 error는 같은 tx로 재시도하며
+// 이전 시도의 tx가 이미 mempool에 있으면 성공으로 간주함.
+func (client *HTTPClient) broadcastTx(ctx context.Context, tx tmtypes.Tx, broadcast func(context.Context, tmtypes.Tx) (*ctypes.ResultBroadcastTx, error)) (*ctypes.ResultBroadcastTx, error) {
+	res, err := client.retry(ctx, func(ctx context.Context) (interface{}, error) {
+		bres, err := broadcast(ctx, tx)
+		if err != nil && strings.Contains(err.Error(), txInCacheErrMsg) {
+			return &ctypes.ResultBroadcastTx{Hash: tx.Hash()}, nil
+		}
+		return bres, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ctypes.ResultBroadcastTx), nil
+}
+
+func (client *HTTPClient) TxStatus(ctx context.Context, hash []byte) (*TxStatus, error) {
+	res, err := client.retry(ctx, func(ctx context.Context) (interface{}, error) {
+		res, err := client.caller.Tx(ctx, hash, false)
+		if err != nil && strings.Contains(err.Error(), txNotFoundErrMsg) {
+			return &TxStatus{Hash: hash}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return &TxStatus{Hash: res.Hash, Committed: true, Height: res.Height, DeliverTx: res.TxResult}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*TxStatus), nil
+}
+
+func (client *HTTPClient) WaitForCommit(ctx context.Context, hash []byte) (*TxStatus, error) {
+	return waitForCommit(ctx, hash, client.TxStatus)
+}
+
+// CommitPollInterval은 WaitForCommit이 tx의 commit 여부를 조회하는 주기.
+const CommitPollInterval = 500 * time.Millisecond
+
+// waitForCommit은 tx가 commit되거나 ctx가 만료될 때까지 txStatus로 commit 여부를 조회함.
+func waitForCommit(ctx context.Context, hash []byte, txStatus func(context.Context, []byte) (*TxStatus, error)) (*TxStatus, error) {
+	ticker := time.NewTicker(CommitPollInterval)
+	defer ticker.Stop()
+	for {
+		status, err := txStatus(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if status.Committed {
+			return status, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return status, errors.Wrapf(ctx.Err(), "tx %X is not committed", hash)
+		}
+	}
+}
+
+// txSearchPerPage는 FindTxs가 tx_search 한 번에 read하는 tx 개수. tendermint가 허용하는 최대값임.
+const txSearchPerPage = 100
+
+func (client *HTTPClient) FindTxs(ctx context.Context, id []byte) ([]*ctypes.ResultTx, error) {
+	if len(id) != consts.RowKeyLen && len(id) != consts.LegacyRowKeyLen {
+		return nil, errors.Errorf("wrong id length. Expect %v or %v, got %v", consts.RowKeyLen, consts.LegacyRowKeyLen, len(id))
+	}
+	timestamp := binary.BigEndian.Uint64(id[0:8])
+	if timestamp > math.MaxInt64 {
+		return nil, errors.Errorf("timestamp %v is out of tx_search range", timestamp)
+	}
+
+	// mintime, maxtime tag로 id의 timestamp를 포함하는 put tx를 찾은 뒤 tx 내용으로 id를 확인함
+	query := fmt.Sprintf("%s='%s' AND %s<=%d AND %s>=%d", consts.ActionTagKey, consts.PutAction, consts.MinTimestampTagKey, timestamp, consts.MaxTimestampTagKey, timestamp)
+	var txs []*ctypes.ResultTx
+	for page, read := 1, 0; ; page++ {
+		page := page
+		res, err := client.retry(ctx, func(ctx context.Context) (interface{}, error) {
+			return client.caller.TxSearch(ctx, query, false, page, txSearchPerPage)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "tx search failed")
+		}
+
+		result := res.(*ctypes.ResultTxSearch)
+		for _, tx := range result.Txs {
+			if txContainsRowKey(tx.Tx, id) {
+				txs = append(txs, tx)
+			}
+		}
+		read += len(result.Txs)
+		if len(result.Txs) == 0 || read >= result.TotalCount {
+			break
+		}
+	}
+
+	return txs, nil
+}
+
+// txContainsRowKey는 put tx가 rowKey 데이터를 담고 있는지 return.
+func txContainsRowKey(tx tmtypes.Tx, rowKey []byte) bool {
 	var baseDataObjs []types.BaseDataObj
-	for _, dataObj := range dataObjs {
+	if err := json.Unmarshal(tx, &baseDataObjs); err != nil {
+		return false
+	}
+	for _, baseDataObj := range baseDataObjs {
+		if bytes.Equal(baseDataObj.MetaData.RowKey, rowKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// committedTx는 이미 broadcast된 tx의 commit 결과를 ResultBroadcastTxCommit로 return.
+// 아직 commit되지 않았으면 errTxPending을 return.
+func (client *HTTPClient) committedTx(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	res, err := client.caller.Tx(ctx, tx.Hash(), false)
+	if err != nil {
+		return nil, errors.Wrap(errTxPending, err.Error())
+	}
+
+	return &ctypes.ResultBroadcastTxCommit{DeliverTx: res.TxResult, Hash: res.Hash, Height: res.Height}, nil
+}
+
+// makeTx는 dataObjs에 content hash rowKey를 부여하여 tx로 변환함. Id가 주어진 데이터는 Id를 rowKey로 사용함.
+// 같은 rowKey에 같은 데이터가 두 번 담겨 있으면 한 번만 담고 다른 데이터가 담겨 있으면 error를 return.
+func makeTx(dataObjs []InputDataObj) (tmtypes.Tx, error) {
+	var baseDataObjs []types.BaseDataObj
+	rowKeys := make(map[string]int)
+	for i, dataObj := range dataObjs {
+		if dataObj.Id != nil {
+			if len(dataObj.Id) < consts.TimestampLen {
+				return nil, errors.Errorf("wrong id length. Expect %v or above, got %v", consts.TimestampLen, len(dataObj.Id))
+			}
+			idTimestamp := binary.BigEndian.Uint64(dataObj.Id[0:consts.TimestampLen])
+			if dataObj.Timestamp == 0 {
+				dataObj.Timestamp = idTimestamp
+			} else if dataObj.Timestamp != idTimestamp {
+				return nil, errors.Errorf("timestamp %v does not match timestamp %v of id", dataObj.Timestamp, idTimestamp)
+			}
+		}
 		if dataObj.Timestamp == 0 {
 			return nil, errors.Errorf("timestamp must not be 0.")
 		}
 		if len(dataObj.OwnerId) > consts.OwnerIdLenLimit || len(dataObj.OwnerId) == 0 {
 			return nil, errors.Errorf("%s: wrong ownerId length. Expect %v or below, got %v", dataObj.OwnerId, consts.OwnerIdLenLimit, len(dataObj.OwnerId))
 		}
-		rowKey := types.GetRowKey(dataObj.Timestamp, uint16(rand.Intn(65536)))
-		baseDataObjs = append(baseDataObjs, types.BaseDataObj{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: dataObj.OwnerId, Qualifier: []byte(dataObj.Qualifier)}, RealData: types.RealDataObj{RowKey: rowKey, Data: dataObj.Data}})
+		rowKey := inputRowKey(dataObj)
+		if j, ok := rowKeys[string(rowKey)]; ok {
+			if sameInputData(dataObjs[j], dataObj) {
+				continue
+			}
+			return nil, errors.Errorf("data at index %v conflicts with index %v", i, j)
+		}
+		rowKeys[string(rowKey)] = i
+		baseDataObjs = append(baseDataObjs, types.BaseDataObj{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: dataObj.OwnerId, Qualifier: []byte(dataObj.Qualifier)}, RealData: types.RealDataObj{RowKey: rowKey, Data: dataObj.Data}, Conflict: dataObj.Conflict})
 	}
 
 	jsonBytes, err := json.Marshal(baseDataObjs)
@@ -45,32 +286,62 @@ func (client *HTTPClient) Put(dataObjs []InputDataObj) (*ctypes.ResultBroadcastT
 		return nil, errors.Wrap(err, "marshal failed")
 	}
 
-	bres, err := client.rpcClient.BroadcastTxCommit(jsonBytes)
-	return bres, err
+	return jsonBytes, nil
+}
+
+// inputRowKey는 dataObj의 rowKey를 return. Id가 주어지지 않으면 content hash rowKey를 생성함.
+func inputRowKey(dataObj InputDataObj) []byte {
+	if dataObj.Id != nil {
+		return dataObj.Id
+	}
+	return types.NewRowKey(dataObj.Timestamp, dataObj.OwnerId, []byte(dataObj.Qualifier), dataObj.Data)
+}
+
+// sameInputData는 두 dataObj의 ownerId, qualifier, data가 모두 같은지 return.
+func sameInputData(a InputDataObj, b InputDataObj) bool {
+	return a.OwnerId == b.OwnerId && a.Qualifier == b.Qualifier && bytes.Equal(a.Data, b.Data)
 }
 
 func (client *HTTPClient) Query(queryObj InputQueryObj) (*ctypes.ResultABCIQuery, error) {
+	return client.QueryContext(context.Background(), queryObj)
+}
 
-	if len(queryObj.OwnerId) > consts.OwnerIdLenLimit {
-		return nil, errors.Errorf("wrong ownerId length. Expect %v or below, got %v", consts.OwnerIdLenLimit, len(queryObj.OwnerId))
+func (client *HTTPClient) QueryContext(ctx context.Context, queryObj InputQueryObj) (*ctypes.ResultABCIQuery, error) {
+	jsonBytes, err := makeQueryData(queryObj)
+	if err != nil {
+		return nil, err
 	}
 
-	if queryObj.Start >= queryObj.End {
-		err := errors.New("query end must be greater than start")
+	res, err := client.abciQuery(ctx, consts.QueryPath, jsonBytes, queryObj.Height)
+	if err != nil {
 		return nil, err
 	}
 
-	jsonBytes, err := json.Marshal(types.QueryObj{Start: queryObj.Start, End: queryObj.End, OwnerId: queryObj.OwnerId, Qualifier: []byte(queryObj.Qualifier)})
+	deserializedValue, err := deSerializeKeyObj(res.Response.Value, true)
 	if err != nil {
-		return nil, errors.Wrap(err, "marshal failed")
+		return nil, errors.Wrap(err, "deserialize key object failed")
 	}
 
-	res, err := client.rpcClient.ABCIQuery(consts.QueryPath, jsonBytes)
+	res.Response.Value = deserializedValue
+	return res, nil
+}
+
+func (client *HTTPClient) Fetch(fetchObj InputFetchObj) (*ctypes.ResultABCIQuery, error) {
+	return client.FetchContext(context.Background(), fetchObj)
+}
+
+func (client *HTTPClient) FetchContext(ctx context.Context, fetchObj InputFetchObj) (*ctypes.ResultABCIQuery, error) {
+	jsonBytes, err := makeFetchData(fetchObj)
 	if err != nil {
 		return nil, err
 	}
 
-	deserializedValue, err := deSerializeKeyObj(res.Response.Value, true)
+	res, err := client.abciQuery(ctx, consts.FetchPath, jsonBytes, fetchObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	deserializedValue, err := deSerializeKeyObj(res.Response.Value, false)
 	if err != nil {
 		return nil, errors.Wrap(err, "deserialize key object failed")
 	}
@@ -79,7 +350,241 @@ func (client *HTTPClient) Query(queryObj InputQueryObj) (*ctypes.ResultABCIQuery
 	return res, nil
 }
 
-func (client *HTTPClient) Fetch(fetchObj InputFetchObj) (*ctypes.ResultABCIQuery, error) {
+func (client *HTTPClient) QueryDecoded(ctx context.Context, queryObj InputQueryObj) (*ResultQuery, error) {
+	jsonBytes, err := makeQueryData(queryObj)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.abciQuery(ctx, consts.QueryPath, jsonBytes, queryObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeQueryResult(res.Response)
+}
+
+func (client *HTTPClient) FetchDecoded(ctx context.Context, fetchObj InputFetchObj) (*ResultFetch, error) {
+	jsonBytes, err := makeFetchData(fetchObj)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.abciQuery(ctx, consts.FetchPath, jsonBytes, fetchObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeFetchResult(res.Response)
+}
+
+func (client *HTTPClient) FetchHistory(ctx context.Context, fetchObj InputFetchObj) (*ResultHistory, error) {
+	jsonBytes, err := makeFetchData(fetchObj)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.abciQuery(ctx, consts.HistoryPath, jsonBytes, fetchObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeHistoryResult(res.Response)
+}
+
+func (client *HTTPClient) Series(ctx context.Context, seriesObj InputSeriesObj) (*ResultSeries, error) {
+	if len(seriesObj.OwnerId) > consts.OwnerIdLenLimit {
+		return nil, errors.Errorf("wrong ownerId length. Expect %v or below, got %v", consts.OwnerIdLenLimit, len(seriesObj.OwnerId))
+	}
+	jsonBytes, err := json.Marshal(types.SeriesQueryObj{OwnerId: seriesObj.OwnerId, Qualifier: []byte(seriesObj.Qualifier)})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	res, err := client.abciQuery(ctx, consts.SeriesPath, jsonBytes, seriesObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSeriesResult(res.Response)
+}
+
+func (client *HTTPClient) Count(ctx context.Context, queryObj InputQueryObj) (*ResultCount, error) {
+	jsonBytes, err := makeQueryData(queryObj)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.abciQuery(ctx, consts.CountPath, jsonBytes, queryObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCountResult(res.Response)
+}
+
+func (client *HTTPClient) Latest(ctx context.Context, latestObj InputLatestObj) (*ResultLatest, error) {
+	latestQueryObj := types.LatestQueryObj{OwnerIds: latestObj.OwnerIds}
+	for _, ownerId := range latestObj.OwnerIds {
+		if len(ownerId) > consts.OwnerIdLenLimit {
+			return nil, errors.Errorf("wrong ownerId length. Expect %v or below, got %v", consts.OwnerIdLenLimit, len(ownerId))
+		}
+	}
+	for _, qualifier := range latestObj.Qualifiers {
+		latestQueryObj.Qualifiers = append(latestQueryObj.Qualifiers, []byte(qualifier))
+	}
+	jsonBytes, err := json.Marshal(latestQueryObj)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	res, err := client.abciQuery(ctx, consts.LatestPath, jsonBytes, latestObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeLatestResult(res.Response)
+}
+
+func (client *HTTPClient) Range(ctx context.Context, rangeObj InputRangeObj) (*ResultRange, error) {
+	if len(rangeObj.OwnerId) > consts.OwnerIdLenLimit {
+		return nil, errors.Errorf("wrong ownerId length. Expect %v or below, got %v", consts.OwnerIdLenLimit, len(rangeObj.OwnerId))
+	}
+	jsonBytes, err := json.Marshal(types.RangeQueryObj{Start: rangeObj.Start, End: rangeObj.End, OwnerId: rangeObj.OwnerId, Qualifier: []byte(rangeObj.Qualifier), Step: rangeObj.Step})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	res, err := client.abciQuery(ctx, consts.RangePath, jsonBytes, rangeObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeRangeResult(res.Response)
+}
+
+func (client *HTTPClient) QL(ctx context.Context, qlObj InputQLObj) (*ResultQL, error) {
+	if qlObj.Query == "" {
+		return nil, errors.New("query is empty")
+	}
+	jsonBytes, err := json.Marshal(types.QLQueryObj{Query: qlObj.Query})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	res, err := client.abciQuery(ctx, consts.QLPath, jsonBytes, qlObj.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeQLResult(res.Response)
+}
+
+func (client *HTTPClient) QueryData(ctx context.Context, queryObj InputQueryObj) (*ResultQueryData, error) {
+	res, _, err := client.queryDataChunk(ctx, queryObj, 0, nil)
+	return res, err
+}
+
+func (client *HTTPClient) QueryDataStream(ctx context.Context, queryObj InputQueryObj, chunkSize int, fn func(chunk []OutputDataObj) error) error {
+	return queryDataStream(ctx, chunkSize, fn, func(after []byte) (*ResultQueryData, []byte, error) {
+		return client.queryDataChunk(ctx, queryObj, chunkSize, after)
+	})
+}
+
+// queryDataChunk는 after 다음부터 최대 limit개의 데이터를 read하고 다음 chunk의 cursor를 함께 return.
+func (client *HTTPClient) queryDataChunk(ctx context.Context, queryObj InputQueryObj, limit int, after []byte) (*ResultQueryData, []byte, error) {
+	jsonBytes, err := makeQueryDataData(queryObj, limit, after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := client.abciQuery(ctx, consts.QueryDataPath, jsonBytes, queryObj.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decodeQueryDataResult(res.Response)
+}
+
+// queryDataStream은 queryDataChunk로 chunk를 차례대로 read하여 fn에 넘김. fn이 error를 return하면 중단함.
+func queryDataStream(ctx context.Context, chunkSize int, fn func(chunk []OutputDataObj) error, queryDataChunk func(after []byte) (*ResultQueryData, []byte, error)) error {
+	if chunkSize <= 0 {
+		return errors.Errorf("chunk size must be positive, got %v", chunkSize)
+	}
+
+	var after []byte
+	for {
+		res, next, err := queryDataChunk(after)
+		if err != nil {
+			return err
+		}
+		if len(res.Data) > 0 {
+			if err := fn(res.Data); err != nil {
+				return err
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		after = next
+	}
+}
+
+// makeQueryData는 queryObj를 검사하고 server의 query model로 변환함.
+func makeQueryData(queryObj InputQueryObj) ([]byte, error) {
+	convertedQueryObj, err := toQueryObj(queryObj)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(convertedQueryObj)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	return jsonBytes, nil
+}
+
+// makeQueryDataData는 queryObj를 검사하고 limit, after cursor와 함께 server의 querydata model로 변환함.
+func makeQueryDataData(queryObj InputQueryObj, limit int, after []byte) ([]byte, error) {
+	convertedQueryObj, err := toQueryObj(queryObj)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(types.QueryDataObj{QueryObj: convertedQueryObj, Limit: limit, After: after})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal failed")
+	}
+
+	return jsonBytes, nil
+}
+
+// toQueryObj는 queryObj의 OwnerId 길이와 time range를 검사하고 server의 QueryObj로 변환함.
+func toQueryObj(queryObj InputQueryObj) (types.QueryObj, error) {
+	for _, ownerId := range append([]string{queryObj.OwnerId, queryObj.OwnerIdPrefix}, queryObj.OwnerIds...) {
+		if len(ownerId) > consts.OwnerIdLenLimit {
+			return types.QueryObj{}, errors.Errorf("wrong ownerId length. Expect %v or below, got %v", consts.OwnerIdLenLimit, len(ownerId))
+		}
+	}
+
+	if queryObj.Start >= queryObj.End {
+		err := errors.New("query end must be greater than start")
+		return types.QueryObj{}, err
+	}
+
+	convertedQueryObj := types.QueryObj{Start: queryObj.Start, End: queryObj.End, OwnerId: queryObj.OwnerId, Qualifier: []byte(queryObj.Qualifier), OwnerIds: queryObj.OwnerIds, OwnerIdPrefix: queryObj.OwnerIdPrefix}
+	for _, qualifier := range queryObj.Qualifiers {
+		convertedQueryObj.Qualifiers = append(convertedQueryObj.Qualifiers, []byte(qualifier))
+	}
+	return convertedQueryObj, nil
+}
+
+// makeFetchData는 fetchObj를 server의 fetch model로 변환함.
+func makeFetchData(fetchObj InputFetchObj) ([]byte, error) {
 	var convertedFetchObj types.FetchObj
 	for _, id := range fetchObj.Ids {
 		convertedFetchObj.RowKeys = append(convertedFetchObj.RowKeys, id)
@@ -90,18 +595,216 @@ func (client *HTTPClient) Fetch(fetchObj InputFetchObj) (*ctypes.ResultABCIQuery
 		return nil, errors.Wrap(err, "marshal failed")
 	}
 
-	res, err := client.rpcClient.ABCIQuery(consts.FetchPath, jsonBytes)
+	return jsonBytes, nil
+}
+
+// decodeQueryResult는 server의 query response를 한 번만 unmarshal하여 ResultQuery로 변환함.
+func decodeQueryResult(res abciTypes.ResponseQuery) (*ResultQuery, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("query failed: %s", res.Log)
+	}
+
+	var metaDataObjs []types.MetaDataObj
+	if err := json.Unmarshal(res.Value, &metaDataObjs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	return &ResultQuery{Height: res.Height, Proof: res.Proof, Data: toOutputQueryObjs(metaDataObjs)}, nil
+}
+
+// decodeFetchResult는 server의 fetch response를 한 번만 unmarshal하여 ResultFetch로 변환함.
+func decodeFetchResult(res abciTypes.ResponseQuery) (*ResultFetch, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("fetch failed: %s", res.Log)
+	}
+
+	var realDataObjs []types.RealDataObj
+	if err := json.Unmarshal(res.Value, &realDataObjs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	return &ResultFetch{Height: res.Height, Proof: res.Proof, Data: toOutputFetchObjs(realDataObjs)}, nil
+}
+
+// decodeHistoryResult는 server의 history response를 ResultHistory로 변환함.
+func decodeHistoryResult(res abciTypes.ResponseQuery) (*ResultHistory, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("fetch history failed: %s", res.Log)
+	}
+
+	var historyObjs []types.HistoryObj
+	if err := json.Unmarshal(res.Value, &historyObjs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	var outputHistoryObjs []OutputHistoryObj
+	for _, historyObj := range historyObjs {
+		outputHistoryObjs = append(outputHistoryObjs, OutputHistoryObj{Id: historyObj.RowKey, Timestamp: binary.BigEndian.Uint64(historyObj.RowKey[0:8]), OwnerId: historyObj.OwnerId, Qualifier: string(historyObj.Qualifier), Data: historyObj.Data, Height: historyObj.Height})
+	}
+
+	return &ResultHistory{Height: res.Height, Data: outputHistoryObjs}, nil
+}
+
+// decodeSeriesResult는 server의 series response를 ResultSeries로 변환함.
+func decodeSeriesResult(res abciTypes.ResponseQuery) (*ResultSeries, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("series failed: %s", res.Log)
+	}
+
+	var seriesObjs []types.SeriesObj
+	if err := json.Unmarshal(res.Value, &seriesObjs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	var outputSeriesObjs []OutputSeriesObj
+	for _, seriesObj := range seriesObjs {
+		outputSeriesObjs = append(outputSeriesObjs, OutputSeriesObj{Id: seriesObj.Id, OwnerId: seriesObj.OwnerId, Qualifier: string(seriesObj.Qualifier)})
+	}
+
+	return &ResultSeries{Height: res.Height, Data: outputSeriesObjs}, nil
+}
+
+// decodeCountResult는 server의 count response를 ResultCount로 변환함.
+func decodeCountResult(res abciTypes.ResponseQuery) (*ResultCount, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("count failed: %s", res.Log)
+	}
+
+	var countResObj types.CountResObj
+	if err := json.Unmarshal(res.Value, &countResObj); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	return &ResultCount{Height: res.Height, Count: countResObj.Count, Series: countResObj.Series, Owners: countResObj.Owners, Qualifiers: countResObj.Qualifiers}, nil
+}
+
+// decodeLatestResult는 server의 latest response를 ResultLatest로 변환함.
+func decodeLatestResult(res abciTypes.ResponseQuery) (*ResultLatest, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("latest failed: %s", res.Log)
+	}
+
+	var baseDataObjs []types.BaseDataObj
+	if err := json.Unmarshal(res.Value, &baseDataObjs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	var outputDataObjs []OutputDataObj
+	for _, baseDataObj := range baseDataObjs {
+		outputDataObjs = append(outputDataObjs, toOutputDataObj(baseDataObj))
+	}
+
+	return &ResultLatest{Height: res.Height, Proof: res.Proof, Data: outputDataObjs}, nil
+}
+
+// decodeQLResult는 server의 ql response를 ResultQL로 변환함.
+func decodeQLResult(res abciTypes.ResponseQuery) (*ResultQL, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("ql failed: %s", res.Log)
+	}
+
+	var qlResObj types.QLResObj
+	if err := json.Unmarshal(res.Value, &qlResObj); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	qlResult := &ResultQL{Height: res.Height, Proof: res.Proof, Columns: qlResObj.Columns}
+	for _, qlGroupObj := range qlResObj.Groups {
+		qlResult.Groups = append(qlResult.Groups, OutputQLGroupObj{Time: qlGroupObj.Time, OwnerId: qlGroupObj.OwnerId, Values: qlGroupObj.Values})
+	}
+	for _, baseDataObj := range qlResObj.Data {
+		qlResult.Data = append(qlResult.Data, toOutputDataObj(baseDataObj))
+	}
+
+	return qlResult, nil
+}
+
+// decodeRangeResult는 server의 range response를 ResultRange로 변환함.
+func decodeRangeResult(res abciTypes.ResponseQuery) (*ResultRange, error) {
+	if res.IsErr() {
+		return nil, errors.Errorf("range failed: %s", res.Log)
+	}
+
+	var rangeResObjs []types.RangeResObj
+	if err := json.Unmarshal(res.Value, &rangeResObjs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	var outputRangeObjs []OutputRangeObj
+	for _, rangeResObj := range rangeResObjs {
+		seriesObj := rangeResObj.Series
+		outputRangeObj := OutputRangeObj{Series: OutputSeriesObj{Id: seriesObj.Id, OwnerId: seriesObj.OwnerId, Qualifier: string(seriesObj.Qualifier)}, Resolution: rangeResObj.Resolution}
+		for _, pointObj := range rangeResObj.Points {
+			outputRangeObj.Points = append(outputRangeObj.Points, OutputPointObj{Timestamp: pointObj.Timestamp, Value: pointObj.Value})
+		}
+		for _, bucketObj := range rangeResObj.Buckets {
+			outputRangeObj.Buckets = append(outputRangeObj.Buckets, OutputBucketObj(bucketObj))
+		}
+		outputRangeObjs = append(outputRangeObjs, outputRangeObj)
+	}
+
+	return &ResultRange{Height: res.Height, Data: outputRangeObjs}, nil
+}
+
+// decodeQueryDataResult는 server의 querydata response를 ResultQueryData와 다음 chunk의 cursor로 변환함.
+func decodeQueryDataResult(res abciTypes.ResponseQuery) (*ResultQueryData, []byte, error) {
+	if res.IsErr() {
+		return nil, nil, errors.Errorf("query data failed: %s", res.Log)
+	}
+
+	var queryDataResObj types.QueryDataResObj
+	if err := json.Unmarshal(res.Value, &queryDataResObj); err != nil {
+		return nil, nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	var outputDataObjs []OutputDataObj
+	for _, baseDataObj := range queryDataResObj.Data {
+		outputDataObjs = append(outputDataObjs, toOutputDataObj(baseDataObj))
+	}
+
+	return &ResultQueryData{Height: res.Height, Proof: res.Proof, Data: outputDataObjs}, queryDataResObj.Next, nil
+}
+
+func toOutputDataObj(baseDataObj types.BaseDataObj) OutputDataObj {
+	metaDataObj := baseDataObj.MetaData
+	return OutputDataObj{Id: metaDataObj.RowKey, Timestamp: binary.BigEndian.Uint64(metaDataObj.RowKey[0:8]), OwnerId: metaDataObj.OwnerId, Qualifier: string(metaDataObj.Qualifier), Data: baseDataObj.RealData.Data}
+}
+
+func toOutputQueryObjs(metaDataObjs []types.MetaDataObj) []OutputQueryObj {
+	var outputQueryObjs []OutputQueryObj
+	for _, metaDataObj := range metaDataObjs {
+		outputQueryObjs = append(outputQueryObjs, OutputQueryObj{Id: metaDataObj.RowKey, Timestamp: binary.BigEndian.Uint64(metaDataObj.RowKey[0:8]), OwnerId: metaDataObj.OwnerId, Qualifier: string(metaDataObj.Qualifier)})
+	}
+	return outputQueryObjs
+}
+
+func toOutputFetchObjs(realDataObjs []types.RealDataObj) []OutputFetchObj {
+	var outputFetchObjs []OutputFetchObj
+	for _, realDataObj := range realDataObjs {
+		outputFetchObjs = append(outputFetchObjs, OutputFetchObj{Id: realDataObj.RowKey, Timestamp: binary.BigEndian.Uint64(realDataObj.RowKey[0:8]), Data: realDataObj.Data})
+	}
+	return outputFetchObjs
+}
+
+// abciQuery는 ABCIQuery를 재시도와 함께 호출함. query는 상태를 바꾸지 않으므로 항상 재시도해도 안전함.
+// height가 0보다 크면 해당 height에 commit된 상태를 read하며 WithProofVerification이 설정되어 있으면 proof를 함께 요청하여 결과를 검증함.
+func (client *HTTPClient) abciQuery(ctx context.Context, path string, data []byte, height int64) (*ctypes.ResultABCIQuery, error) {
+	prove := client.appHashProvider != nil
+	res, err := client.retry(ctx, func(ctx context.Context) (interface{}, error) {
+		return client.caller.ABCIQueryWithOptions(ctx, path, data, rpcClient.ABCIQueryOptions{Height: height, Prove: prove})
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	deserializedValue, err := deSerializeKeyObj(res.Response.Value, false)
-	if err != nil {
-		return nil, errors.Wrap(err, "deserialize key object failed")
+	result := res.(*ctypes.ResultABCIQuery)
+	if prove {
+		if err := client.verifyResponse(ctx, path, result.Response); err != nil {
+			return nil, errors.Wrap(err, "proof verification failed")
+		}
 	}
 
-	res.Response.Value = deserializedValue
-	return res, nil
+	return result, nil
 }
 
 func deSerializeKeyObj(obj []byte, isMeta bool) ([]byte, error) {
@@ -111,10 +814,7 @@ func deSerializeKeyObj(obj []byte, isMeta bool) ([]byte, error) {
 			return nil, errors.Wrap(err, "unmarshal failed")
 		}
 
-		var deserializedMeta []OutputQueryObj
-		for _, metaDataObj := range metaDataObjs {
-			deserializedMeta = append(deserializedMeta, OutputQueryObj{Id: metaDataObj.RowKey, Timestamp: binary.BigEndian.Uint64(metaDataObj.RowKey[0:8]), OwnerId: metaDataObj.OwnerId, Qualifier: string(metaDataObj.Qualifier)})
-		}
+		deserializedMeta := toOutputQueryObjs(metaDataObjs)
 		deserializedObj, err := json.MarshalIndent(deserializedMeta, "", "    ")
 		if err != nil {
 			return nil, errors.Wrap(err, "marshal failed")
@@ -126,10 +826,7 @@ func deSerializeKeyObj(obj []byte, isMeta bool) ([]byte, error) {
 			return nil, errors.Wrap(err, "unmarshal failed")
 		}
 
-		var deserializedReal []OutputFetchObj
-		for _, realDataObj := range realDataObjs {
-			deserializedReal = append(deserializedReal, OutputFetchObj{Id: realDataObj.RowKey, Timestamp: binary.BigEndian.Uint64(realDataObj.RowKey[0:8]), Data: realDataObj.Data})
-		}
+		deserializedReal := toOutputFetchObjs(realDataObjs)
 		deserializedObj, err := json.MarshalIndent(deserializedReal, "", "    ")
 		if err != nil {
 			return nil, errors.Wrap(err, "marshal failed")
@@ -2,8 +2,12 @@ package client
 
 import (
 	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
 	"github.com/paust-team/paust-db/types"
 	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"strings"
 	"testing"
 )
 
@@ -41,3 +45,216 @@ func TestHTTPClient_deSerializeKeyObj(t *testing.T) {
 
 	require.EqualValues(outputFetchObjs, deserializedBytes)
 }
+
+func TestHTTPClient_decodeResult(t *testing.T) {
+	require := require.New(t)
+	var timestamp1 uint64 = 1547772882435375000
+	rowKey1 := types.GetRowKey(timestamp1, 0)
+
+	// query result decode
+	metaDataObjs, err := json.Marshal([]types.MetaDataObj{{RowKey: rowKey1, OwnerId: TestOwnerId, Qualifier: []byte(TestQualifier)}})
+	require.Nil(err, "json marshal err: %+v", err)
+
+	queryResult, err := decodeQueryResult(abciTypes.ResponseQuery{Value: metaDataObjs, Height: 3})
+	require.Nil(err, "decodeQueryResult err: %+v", err)
+	require.Equal(&ResultQuery{Height: 3, Data: []OutputQueryObj{{Id: rowKey1, Timestamp: timestamp1, OwnerId: TestOwnerId, Qualifier: TestQualifier}}}, queryResult)
+
+	// fetch result decode
+	realDataObjs, err := json.Marshal([]types.RealDataObj{{RowKey: rowKey1, Data: []byte("testData1")}})
+	require.Nil(err, "json marshal err: %+v", err)
+
+	fetchResult, err := decodeFetchResult(abciTypes.ResponseQuery{Value: realDataObjs, Height: 3})
+	require.Nil(err, "decodeFetchResult err: %+v", err)
+	require.Equal(&ResultFetch{Height: 3, Data: []OutputFetchObj{{Id: rowKey1, Timestamp: timestamp1, Data: []byte("testData1")}}}, fetchResult)
+
+	// server error
+	_, err = decodeQueryResult(abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: "invalid query"})
+	require.NotNil(err)
+}
+
+func TestHTTPClient_makeTx(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	dataObj := InputDataObj{Timestamp: 1547772882435375000, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("data")}
+
+	//when
+	tx, err := makeTx([]InputDataObj{dataObj})
+
+	//then
+	// rowKey는 timestamp와 데이터 내용으로 정해지므로 다시 만들어도 같음
+	require.Nil(err)
+	var baseDataObjs []types.BaseDataObj
+	require.Nil(json.Unmarshal(tx, &baseDataObjs))
+	require.Equal(types.NewRowKey(dataObj.Timestamp, dataObj.OwnerId, []byte(dataObj.Qualifier), dataObj.Data), baseDataObjs[0].MetaData.RowKey)
+	sameTx, err := makeTx([]InputDataObj{dataObj})
+	require.Nil(err)
+	require.Equal(tx, sameTx)
+
+	// 같은 내용의 데이터가 두 번 담기면 한 번만 담음
+	duplicateTx, err := makeTx([]InputDataObj{dataObj, dataObj})
+	require.Nil(err)
+	require.Equal(tx, duplicateTx)
+}
+
+func TestHTTPClient_makeTx_overwrite(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	id := types.NewRowKey(1547772882435375000, TestOwnerId, []byte(TestQualifier), []byte("data"))
+	dataObj := InputDataObj{Id: id, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("corrected"), Conflict: consts.ConflictOverwrite}
+
+	//when
+	tx, err := makeTx([]InputDataObj{dataObj})
+
+	//then
+	// Id가 주어지면 내용과 관계없이 Id를 rowKey로 사용하고 conflict policy를 함께 전달함
+	require.Nil(err)
+	var baseDataObjs []types.BaseDataObj
+	require.Nil(json.Unmarshal(tx, &baseDataObjs))
+	require.Equal(id, baseDataObjs[0].MetaData.RowKey)
+	require.Equal(id, baseDataObjs[0].RealData.RowKey)
+	require.Equal(consts.ConflictOverwrite, baseDataObjs[0].Conflict)
+
+	// 같은 Id에 다른 데이터가 두 번 담기면 error
+	otherObj := dataObj
+	otherObj.Data = []byte("other")
+	_, err = makeTx([]InputDataObj{dataObj, otherObj})
+	require.NotNil(err)
+
+	// Timestamp가 Id의 timestamp와 다르면 error
+	dataObj.Timestamp = 1547772882435375001
+	_, err = makeTx([]InputDataObj{dataObj})
+	require.NotNil(err)
+}
+
+func TestHTTPClient_decodeSeriesResult(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	seriesObjs, err := json.Marshal([]types.SeriesObj{{Id: 1, OwnerId: TestOwnerId, Qualifier: []byte(TestQualifier)}})
+	require.Nil(err)
+
+	//when
+	seriesResult, err := decodeSeriesResult(abciTypes.ResponseQuery{Value: seriesObjs, Height: 3})
+
+	//then
+	require.Nil(err)
+	require.Equal(&ResultSeries{Height: 3, Data: []OutputSeriesObj{{Id: 1, OwnerId: TestOwnerId, Qualifier: TestQualifier}}}, seriesResult)
+
+	// server error
+	_, err = decodeSeriesResult(abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: "invalid series query"})
+	require.NotNil(err)
+}
+
+func TestHTTPClient_decodeRangeResult(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	seriesObj := types.SeriesObj{Id: 1, OwnerId: TestOwnerId, Qualifier: []byte(TestQualifier)}
+	rangeResObjs, err := json.Marshal([]types.RangeResObj{
+		{Series: seriesObj, Points: []types.PointObj{{Timestamp: 1547772882435375000, Value: 21.5}}},
+		{Series: seriesObj, Resolution: 60000000000, Buckets: []types.BucketObj{{Start: 1547772882435375000, Count: 2, Sum: 3, Min: 1, Max: 2, First: 2, Last: 1}}},
+	})
+	require.Nil(err)
+
+	//when
+	rangeResult, err := decodeRangeResult(abciTypes.ResponseQuery{Value: rangeResObjs, Height: 3})
+
+	//then
+	require.Nil(err)
+	outputSeriesObj := OutputSeriesObj{Id: 1, OwnerId: TestOwnerId, Qualifier: TestQualifier}
+	require.Equal(&ResultRange{Height: 3, Data: []OutputRangeObj{
+		{Series: outputSeriesObj, Points: []OutputPointObj{{Timestamp: 1547772882435375000, Value: 21.5}}},
+		{Series: outputSeriesObj, Resolution: 60000000000, Buckets: []OutputBucketObj{{Start: 1547772882435375000, Count: 2, Sum: 3, Min: 1, Max: 2, First: 2, Last: 1}}},
+	}}, rangeResult)
+
+	// chunk storage가 꺼져 있는 등 server error
+	_, err = decodeRangeResult(abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: "chunk storage is disabled"})
+	require.NotNil(err)
+}
+
+func TestHTTPClient_decodeLatestResult(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	rowKey := types.NewRowKey(1547772882435375000, TestOwnerId, []byte(TestQualifier), []byte("data"))
+	baseDataObjs, err := json.Marshal([]types.BaseDataObj{{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId, Qualifier: []byte(TestQualifier)}, RealData: types.RealDataObj{RowKey: rowKey, Data: []byte("data")}}})
+	require.Nil(err)
+
+	//when
+	latestResult, err := decodeLatestResult(abciTypes.ResponseQuery{Value: baseDataObjs, Height: 3})
+
+	//then
+	require.Nil(err)
+	require.Equal(&ResultLatest{Height: 3, Data: []OutputDataObj{{Id: rowKey, Timestamp: 1547772882435375000, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("data")}}}, latestResult)
+
+	// server error
+	_, err = decodeLatestResult(abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: "invalid latest query"})
+	require.NotNil(err)
+}
+
+func TestHTTPClient_decodeCountResult(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	countResObj, err := json.Marshal(types.CountResObj{Count: 4, Series: 3, Owners: 2, Qualifiers: 2})
+	require.Nil(err)
+
+	//when
+	countResult, err := decodeCountResult(abciTypes.ResponseQuery{Value: countResObj, Height: 3})
+
+	//then
+	require.Nil(err)
+	require.Equal(&ResultCount{Height: 3, Count: 4, Series: 3, Owners: 2, Qualifiers: 2}, countResult)
+
+	// server error
+	_, err = decodeCountResult(abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: "query end must be greater than start"})
+	require.NotNil(err)
+}
+
+func TestHTTPClient_decodeQLResult(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	rowKey := types.NewRowKey(1547772882435375000, TestOwnerId, []byte(TestQualifier), []byte("1.5"))
+	groupsResObj, err := json.Marshal(types.QLResObj{Columns: []string{"avg(value)", "count(value)"}, Groups: []types.QLGroupObj{{Time: 1547772840000000000, OwnerId: TestOwnerId, Values: []float64{1.5, 2}}}})
+	require.Nil(err)
+	dataResObj, err := json.Marshal(types.QLResObj{Data: []types.BaseDataObj{{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId, Qualifier: []byte(TestQualifier)}, RealData: types.RealDataObj{RowKey: rowKey, Data: []byte("1.5")}}}})
+	require.Nil(err)
+
+	//when
+	qlResult, err := decodeQLResult(abciTypes.ResponseQuery{Value: groupsResObj, Height: 3})
+
+	//then
+	require.Nil(err)
+	require.Equal(&ResultQL{Height: 3, Columns: []string{"avg(value)", "count(value)"}, Groups: []OutputQLGroupObj{{Time: 1547772840000000000, OwnerId: TestOwnerId, Values: []float64{1.5, 2}}}}, qlResult)
+
+	// SELECT * 결과
+	qlResult, err = decodeQLResult(abciTypes.ResponseQuery{Value: dataResObj, Height: 3})
+	require.Nil(err)
+	require.Equal(&ResultQL{Height: 3, Data: []OutputDataObj{{Id: rowKey, Timestamp: 1547772882435375000, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("1.5")}}}, qlResult)
+
+	// server error
+	_, err = decodeQLResult(abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: "parse query err: time range is required"})
+	require.NotNil(err)
+}
+
+func TestHTTPClient_toQueryObj_filters(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	queryObj := InputQueryObj{Start: 1, End: 2, OwnerIds: []string{TestOwnerId, "owner2"}, Qualifiers: []string{TestQualifier}, OwnerIdPrefix: "owner"}
+
+	//when
+	actual, err := toQueryObj(queryObj)
+
+	//then
+	require.Nil(err)
+	require.Equal(types.QueryObj{Start: 1, End: 2, Qualifier: []byte{}, OwnerIds: []string{TestOwnerId, "owner2"}, Qualifiers: [][]byte{[]byte(TestQualifier)}, OwnerIdPrefix: "owner"}, actual)
+
+	// OwnerIds 중 하나라도 길이 제한을 넘으면 error
+	queryObj.OwnerIds = append(queryObj.OwnerIds, strings.Repeat("a", consts.OwnerIdLenLimit+1))
+	_, err = toQueryObj(queryObj)
+	require.NotNil(err)
+}
@@ -1,6 +1,7 @@
 package client_test
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/paust-team/paust-db/client"
 	"github.com/paust-team/paust-db/types"
@@ -67,3 +68,72 @@ func (suite *ClientTestSuite) TestClient_Fetch() {
 		suite.EqualValues(expectedValue, qres.Value)
 	}
 }
+
+func (suite *ClientTestSuite) TestClient_QueryDecoded_FetchDecoded() {
+	require := require.New(suite.T())
+
+	timestamp := uint64(time.Now().UnixNano())
+	data := []byte(cmn.RandStr(8))
+	rowKey := types.GetRowKey(timestamp, 0)
+	tx, err := json.Marshal([]types.BaseDataObj{{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId, Qualifier: []byte(TestQualifier)}, RealData: types.RealDataObj{RowKey: rowKey, Data: data}}})
+	require.Nil(err, "json marshal err: %+v", err)
+
+	c := rpcClient.NewLocal(node)
+	bres, err := c.BroadcastTxCommit(tx)
+
+	require.Nil(err, "err: %+v", err)
+	require.True(bres.DeliverTx.IsOK())
+
+	/*
+		QueryDecoded
+	*/
+	qres, err := suite.dbClient.QueryDecoded(context.Background(), client.InputQueryObj{Start: timestamp, End: timestamp + 1, OwnerId: TestOwnerId, Qualifier: TestQualifier})
+	require.Nil(err, "err: %+v", err)
+	require.Equal([]client.OutputQueryObj{{Id: rowKey, Timestamp: timestamp, OwnerId: TestOwnerId, Qualifier: TestQualifier}}, qres.Data)
+
+	/*
+		FetchDecoded
+	*/
+	fres, err := suite.dbClient.FetchDecoded(context.Background(), client.InputFetchObj{Ids: [][]byte{rowKey}})
+	require.Nil(err, "err: %+v", err)
+	require.Equal([]client.OutputFetchObj{{Id: rowKey, Timestamp: timestamp, Data: data}}, fres.Data)
+}
+
+func (suite *ClientTestSuite) TestClient_QueryData() {
+	require := require.New(suite.T())
+
+	timestamp := uint64(time.Now().UnixNano())
+	var dataObjs []client.InputDataObj
+	for i := 0; i < 5; i++ {
+		dataObjs = append(dataObjs, client.InputDataObj{Timestamp: timestamp + uint64(i), OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte(cmn.RandStr(8))})
+	}
+	bres, err := suite.dbClient.Put(dataObjs)
+	require.Nil(err, "err: %+v", err)
+	require.True(bres.DeliverTx.IsOK())
+	queryObj := client.InputQueryObj{Start: timestamp, End: timestamp + 5, OwnerId: TestOwnerId, Qualifier: TestQualifier}
+
+	/*
+		QueryData
+	*/
+	res, err := suite.dbClient.QueryData(context.Background(), queryObj)
+	require.Nil(err, "err: %+v", err)
+	require.Equal(5, len(res.Data))
+	for i, outputDataObj := range res.Data {
+		require.Equal(dataObjs[i].Timestamp, outputDataObj.Timestamp)
+		require.Equal(dataObjs[i].Data, outputDataObj.Data)
+	}
+
+	/*
+		QueryDataStream
+	*/
+	var chunkSizes []int
+	var streamed []client.OutputDataObj
+	err = suite.dbClient.QueryDataStream(context.Background(), queryObj, 2, func(chunk []client.OutputDataObj) error {
+		chunkSizes = append(chunkSizes, len(chunk))
+		streamed = append(streamed, chunk...)
+		return nil
+	})
+	require.Nil(err, "err: %+v", err)
+	require.Equal([]int{2, 2, 1}, chunkSizes)
+	require.Equal(res.Data, streamed)
+}
@@ -23,7 +23,7 @@ const (
 type ClientTestSuite struct {
 	suite.Suite
 
-	dbClient client.Client
+	dbClient *client.HTTPClient
 }
 
 func (suite *ClientTestSuite) SetupSuite() {
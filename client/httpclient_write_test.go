@@ -1,8 +1,10 @@
 package client_test
 
 import (
+	"context"
 	"github.com/paust-team/paust-db/client"
 	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
 	cmn "github.com/tendermint/tendermint/libs/common"
 	"time"
 )
@@ -23,3 +25,23 @@ func (suite *ClientTestSuite) TestClient_Put() {
 
 	require.Equal(0, mempool.Size())
 }
+
+func (suite *ClientTestSuite) TestClient_PutSync() {
+	require := require.New(suite.T())
+
+	timestamp := uint64(time.Now().UnixNano())
+	data := []byte(cmn.RandStr(8))
+	dataObjs := []client.InputDataObj{{Timestamp: timestamp, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: data}}
+	bres, err := suite.dbClient.PutSync(context.Background(), dataObjs)
+
+	require.Nil(err, "err: %+v", err)
+	require.Equal(abci.CodeTypeOK, bres.Code)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	status, err := suite.dbClient.WaitForCommit(ctx, bres.Hash)
+
+	require.Nil(err, "err: %+v", err)
+	require.True(status.Committed)
+	require.True(status.DeliverTx.IsOK())
+}
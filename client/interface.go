@@ -2,6 +2,7 @@
 package client
 
 import (
+	"context"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
 
@@ -19,3 +20,95 @@ type Client interface {
 	// ResultABCIQuery.Response.Value에 실제 read한 데이터가 OutputFetchObj의 slice로 담겨있음.
 	Fetch(fetchObj InputFetchObj) (*ctypes.ResultABCIQuery, error)
 }
+
+// ContextClient는 ctx로 제한 시간과 취소를 지정하며 일시적인 rpc error를 재시도하는 client임
+type ContextClient interface {
+	// PutContext는 ctx가 만료되기 전까지 Put을 수행함. 일시적인 rpc error는 같은 rowKey로 재시도함.
+	PutContext(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTxCommit, error)
+
+	// QueryContext는 ctx가 만료되기 전까지 Query를 수행함. 일시적인 rpc error는 재시도함.
+	QueryContext(ctx context.Context, queryObj InputQueryObj) (*ctypes.ResultABCIQuery, error)
+
+	// FetchContext는 ctx가 만료되기 전까지 Fetch를 수행함. 일시적인 rpc error는 재시도함.
+	FetchContext(ctx context.Context, fetchObj InputFetchObj) (*ctypes.ResultABCIQuery, error)
+}
+
+// DecodedQuerier는 query 결과를 decode된 type으로 return하는 client임
+type DecodedQuerier interface {
+	// QueryDecoded는 QueryContext와 같은 데이터를 read하되 ResultABCIQuery 대신 OutputQueryObj slice와 height, proof를 ResultQuery로 return.
+	// server가 error code를 return하면 error를 return.
+	QueryDecoded(ctx context.Context, queryObj InputQueryObj) (*ResultQuery, error)
+
+	// FetchDecoded는 FetchContext와 같은 데이터를 read하되 ResultABCIQuery 대신 OutputFetchObj slice와 height, proof를 ResultFetch로 return.
+	// server가 error code를 return하면 error를 return.
+	FetchDecoded(ctx context.Context, fetchObj InputFetchObj) (*ResultFetch, error)
+
+	// QueryData는 InputQueryObj 조건에 맞는 데이터의 metadata와 실제 데이터를 한 번의 요청으로 read하여 ResultQueryData로 return.
+	QueryData(ctx context.Context, queryObj InputQueryObj) (*ResultQueryData, error)
+
+	// QueryDataStream은 QueryData와 같은 데이터를 최대 chunkSize개씩 나누어 read하고 chunk마다 fn을 호출함.
+	// 범위가 넓어 한 번에 read하기 어려운 경우 사용하며 fn이 error를 return하면 중단하고 그 error를 return.
+	QueryDataStream(ctx context.Context, queryObj InputQueryObj, chunkSize int, fn func(chunk []OutputDataObj) error) error
+}
+
+// HistoryFetcher는 overwrite로 대체된 데이터를 read하는 client임
+type HistoryFetcher interface {
+	// FetchHistory는 InputFetchObj의 id마다 overwrite로 대체된 이전 데이터를 대체된 height 순서로 read하여 ResultHistory로 return.
+	// history는 app hash에 포함되지 않으므로 proof 검증을 하지 않음.
+	FetchHistory(ctx context.Context, fetchObj InputFetchObj) (*ResultHistory, error)
+}
+
+// SeriesQuerier는 series 단위로 데이터를 조회하는 client임
+type SeriesQuerier interface {
+	// Series는 InputSeriesObj의 OwnerId, Qualifier와 일치하는 series를 id 순서로 read하여 ResultSeries로 return.
+	// series는 ownerId와 qualifier가 같은 데이터의 묶음이며 처음 write될 때 id를 부여받음.
+	Series(ctx context.Context, seriesObj InputSeriesObj) (*ResultSeries, error)
+
+	// Count는 InputQueryObj 조건에 맞는 데이터 수와 서로 다른 series, ownerId, qualifier 수를 ResultCount로 return.
+	// 데이터를 내려받지 않고 server에서 scan하며 세므로 QueryDecoded로 데이터를 read하여 세는 것보다 가벼움.
+	Count(ctx context.Context, queryObj InputQueryObj) (*ResultCount, error)
+
+	// Latest는 InputLatestObj의 OwnerIds, Qualifiers와 일치하는 series마다 가장 최신 timestamp 데이터의 metadata와 실제 데이터를 read하여 ResultLatest로 return.
+	// 여러 series의 현재 값을 한 번의 요청으로 read할 때 사용함.
+	Latest(ctx context.Context, latestObj InputLatestObj) (*ResultLatest, error)
+}
+
+// RangeQuerier는 chunk storage의 숫자 데이터를 read하는 client임
+type RangeQuerier interface {
+	// Range는 InputRangeObj 조건에 맞는 series마다 time range의 숫자 데이터를 read하여 ResultRange로 return.
+	// server의 chunk storage가 켜져 있어야 하며 chunk는 app hash에 포함되지 않으므로 proof 검증을 하지 않음.
+	Range(ctx context.Context, rangeObj InputRangeObj) (*ResultRange, error)
+}
+
+// QLQuerier는 query language로 데이터를 read, 집계하는 client임
+type QLQuerier interface {
+	// QL은 InputQLObj의 query를 server에서 parse하여 실행하고 결과를 ResultQL로 return.
+	// 여러 ownerId, qualifier 조건과 qualifier JSON의 key 조건을 조합하여 데이터를 read하거나 숫자 데이터를 group마다 집계할 때 사용함.
+	QL(ctx context.Context, qlObj InputQLObj) (*ResultQL, error)
+}
+
+// TxStatusClient는 commit을 기다리지 않고 write한 tx의 상태를 확인하는 client임
+type TxStatusClient interface {
+	// PutSync는 CheckTx 결과까지만 기다리는 BroadcastTxSync로 데이터를 write하고 그 결과를 ResultBroadcastTx로 return.
+	// ResultBroadcastTx.Hash로 TxStatus, WaitForCommit에서 commit 여부를 확인할 수 있음.
+	PutSync(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTx, error)
+
+	// PutAsync는 CheckTx 결과를 기다리지 않는 BroadcastTxAsync로 데이터를 write하고 tx hash를 ResultBroadcastTx로 return.
+	PutAsync(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTx, error)
+
+	// TxStatus는 hash에 해당하는 tx의 commit 여부를 조회함. 아직 commit되지 않았으면 TxStatus.Committed가 false임.
+	TxStatus(ctx context.Context, hash []byte) (*TxStatus, error)
+
+	// WaitForCommit은 hash에 해당하는 tx가 commit될 때까지 기다린 뒤 TxStatus를 return. ctx가 만료되면 error를 return.
+	WaitForCommit(ctx context.Context, hash []byte) (*TxStatus, error)
+
+	// FindTxs는 id(rowKey)의 데이터를 write한 tx를 tendermint tx_search로 찾아 return. tendermint가 paust-db tag를 index하고 있어야 함.
+	FindTxs(ctx context.Context, id []byte) ([]*ctypes.ResultTx, error)
+}
+
+// Subscriber는 commit되는 데이터를 구독하는 client임
+type Subscriber interface {
+	// Subscribe는 이후 commit된 데이터 중 InputSubscribeObj의 OwnerId, Qualifier와 일치하는 데이터를 Subscription의 Out channel로 전달함.
+	// ctx가 만료되거나 channel을 읽지 않아 buffer가 가득 차면 구독을 해제하고 channel을 닫으며 Subscription의 Err로 이유를 구분할 수 있음.
+	Subscribe(ctx context.Context, filterObj InputSubscribeObj) (*Subscription, error)
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/lite"
+	lclient "github.com/tendermint/tendermint/lite/client"
+	rpcClient "github.com/tendermint/tendermint/rpc/client"
+)
+
+// DefaultTrustCacheSize는 LiteAppHashProvider가 메모리에 유지하는 trusted full commit의 최대 개수.
+const DefaultTrustCacheSize = 100
+
+// TrustOptions는 light client가 처음 신뢰할 block. Hash는 Height block header의 hash이며
+// genesis 파일, 신뢰할 수 있는 node 등 검증할 remote node가 아닌 경로로 얻어야 함.
+type TrustOptions struct {
+	Height int64
+	Hash   []byte
+}
+
+// LiteAppHashProvider는 tendermint light client로 검증한 block header에서 app hash를 읽는 AppHashProvider.
+// trusted header의 validator set에서 시작하여 validator set의 변경을 따라가며 header의 서명을 검증하므로
+// trusted header를 얻은 뒤에는 query를 보내는 node를 신뢰하지 않아도 됨.
+type LiteAppHashProvider struct {
+	rpcClient rpcClient.Client
+	verifier  *lite.DynamicVerifier
+}
+
+// NewLiteAppHashProvider는 chainID chain의 remote node를 source로 하는 LiteAppHashProvider를 생성함.
+// trusted full commit은 trustDir에 저장하며 trustDir이 비어 있으면 메모리에만 유지함.
+// 저장된 trusted full commit이 없으면 trust의 height block을 remote node에서 read하여 header hash가 trust의 hash와 같을 때만 신뢰하여 시작하며
+// trust가 주어지지 않았으면 error를 return.
+func NewLiteAppHashProvider(chainID, remote, trustDir string, trust TrustOptions) (*LiteAppHashProvider, error) {
+	trusted := lite.PersistentProvider(lite.NewDBProvider("trusted.mem", dbm.NewMemDB()).SetLimit(DefaultTrustCacheSize))
+	if trustDir != "" {
+		trusted = lite.NewMultiProvider(trusted, lite.NewDBProvider("trusted.lvl", dbm.NewDB("trust-base", dbm.GoLevelDBBackend, trustDir)))
+	}
+
+	return newLiteAppHashProvider(chainID, rpcClient.NewHTTP(remote, consts.WsEndpoint), trusted, trust)
+}
+
+func newLiteAppHashProvider(chainID string, client rpcClient.Client, trusted lite.PersistentProvider, trust TrustOptions) (*LiteAppHashProvider, error) {
+	source := lclient.NewProvider(chainID, client)
+	if _, err := trusted.LatestFullCommit(chainID, 1, 1<<63-1); err != nil {
+		if trust.Height <= 0 || len(trust.Hash) == 0 {
+			return nil, errors.New("no trusted header is stored. trust height and hash are required")
+		}
+		fullCommit, err := source.LatestFullCommit(chainID, trust.Height, trust.Height)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch initial full commit failed")
+		}
+		if err := fullCommit.ValidateFull(chainID); err != nil {
+			return nil, errors.Wrap(err, "initial full commit validation failed")
+		}
+		if hash := fullCommit.SignedHeader.Hash(); !bytes.Equal(hash, trust.Hash) {
+			return nil, errors.Errorf("header hash of height %v is %X, expected trusted hash %X", trust.Height, hash, trust.Hash)
+		}
+		if err := trusted.SaveFullCommit(fullCommit); err != nil {
+			return nil, errors.Wrap(err, "save initial full commit failed")
+		}
+	}
+
+	return &LiteAppHashProvider{
+		rpcClient: client,
+		verifier:  lite.NewDynamicVerifier(chainID, trusted, source),
+	}, nil
+}
+
+// AppHash는 light client로 검증한 height+1 block header의 app hash를 return. height+1 block이 아직 commit되지 않았으면 commit될 때까지 기다림.
+func (provider *LiteAppHashProvider) AppHash(ctx context.Context, height int64) ([]byte, error) {
+	res, err := waitCommit(ctx, provider.rpcClient, height+1)
+	if err != nil {
+		return nil, err
+	}
+	if res.Height != height+1 {
+		return nil, errors.Errorf("wrong header height. Expect %v, got %v", height+1, res.Height)
+	}
+	if err := provider.verifier.Verify(res.SignedHeader); err != nil {
+		return nil, errors.Wrapf(err, "header of height %v verification failed", res.Height)
+	}
+
+	return res.Header.AppHash, nil
+}
+
+// NewVerifyingHTTPClient는 모든 query 결과를 light client로 검증한 app hash와 proof로 검증하는 HTTPClient를 생성함.
+// trust에서 시작하여 remote node의 header를 검증하므로 remote node를 신뢰하지 않아도 됨. trustDir, trust는 NewLiteAppHashProvider와 같음.
+func NewVerifyingHTTPClient(chainID, remote, trustDir string, trust TrustOptions, options ...Option) (*HTTPClient, error) {
+	provider, err := NewLiteAppHashProvider(chainID, remote, trustDir, trust)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHTTPClient(remote, append(options, WithProofVerification(provider))...), nil
+}
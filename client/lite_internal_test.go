@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/lite"
+	rpcClient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"testing"
+)
+
+const testChainID = "test-chain"
+
+// fakeChainRPCClient는 주어진 signed header와 validator set을 return하는 rpc client.
+type fakeChainRPCClient struct {
+	rpcClient.Client
+
+	headers    map[int64]tmtypes.SignedHeader
+	validators *tmtypes.ValidatorSet
+}
+
+func (c *fakeChainRPCClient) Status() (*ctypes.ResultStatus, error) {
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{LatestBlockHeight: int64(len(c.headers))}}, nil
+}
+
+func (c *fakeChainRPCClient) Commit(height *int64) (*ctypes.ResultCommit, error) {
+	header, ok := c.headers[*height]
+	if !ok {
+		return nil, errors.Errorf("Height must be less than or equal to the current blockchain height")
+	}
+	return &ctypes.ResultCommit{SignedHeader: header, CanonicalCommit: true}, nil
+}
+
+func (c *fakeChainRPCClient) Validators(height *int64) (*ctypes.ResultValidators, error) {
+	return &ctypes.ResultValidators{BlockHeight: *height, Validators: c.validators.Validators}, nil
+}
+
+func TestLiteAppHashProvider_AppHash(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	keys := lite.GenSecpPrivKeys(4)
+	validators := keys.ToValidators(10, 0)
+	rpc := &fakeChainRPCClient{headers: make(map[int64]tmtypes.SignedHeader), validators: validators}
+	for height := int64(1); height <= 3; height++ {
+		rpc.headers[height] = keys.GenSignedHeader(testChainID, height, nil, validators, validators, []byte{byte(height)}, nil, nil, 0, len(keys))
+	}
+	trust := TrustOptions{Height: 1, Hash: rpc.headers[1].Hash()}
+	provider, err := newLiteAppHashProvider(testChainID, rpc, lite.NewDBProvider("trusted.mem", dbm.NewMemDB()), trust)
+	require.Nil(err)
+
+	//when
+	appHash, err := provider.AppHash(context.Background(), 2)
+
+	//then
+	// height 2의 app hash는 height 3 header에 담김
+	require.Nil(err)
+	require.Equal([]byte{3}, appHash)
+
+	// trusted validator가 서명하지 않은 header는 거부함
+	otherKeys := lite.GenSecpPrivKeys(4)
+	otherValidators := otherKeys.ToValidators(10, 0)
+	rpc.headers[4] = otherKeys.GenSignedHeader(testChainID, 4, nil, otherValidators, otherValidators, []byte("forged"), nil, nil, 0, len(otherKeys))
+	_, err = provider.AppHash(context.Background(), 3)
+	require.NotNil(err)
+
+	// 아직 commit되지 않은 header는 ctx가 만료될 때까지 기다림
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = provider.AppHash(ctx, 10)
+	require.NotNil(err)
+}
+
+func TestLiteAppHashProvider_trust(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	// remote node가 자신이 만든 validator set의 header를 제공함
+	keys := lite.GenSecpPrivKeys(4)
+	validators := keys.ToValidators(10, 0)
+	trustedHeader := keys.GenSignedHeader(testChainID, 1, nil, validators, validators, []byte{1}, nil, nil, 0, len(keys))
+	forgedKeys := lite.GenSecpPrivKeys(4)
+	forgedValidators := forgedKeys.ToValidators(10, 0)
+	rpc := &fakeChainRPCClient{headers: make(map[int64]tmtypes.SignedHeader), validators: forgedValidators}
+	rpc.headers[1] = forgedKeys.GenSignedHeader(testChainID, 1, nil, forgedValidators, forgedValidators, []byte{1}, nil, nil, 0, len(forgedKeys))
+
+	//when
+	_, noTrustErr := newLiteAppHashProvider(testChainID, rpc, lite.NewDBProvider("trusted.mem", dbm.NewMemDB()), TrustOptions{})
+	_, forgedErr := newLiteAppHashProvider(testChainID, rpc, lite.NewDBProvider("trusted.mem", dbm.NewMemDB()), TrustOptions{Height: 1, Hash: trustedHeader.Hash()})
+
+	//then
+	// 저장된 trusted header가 없으면 trust 없이는 시작하지 않으며 trust와 hash가 다른 header는 신뢰하지 않음
+	require.NotNil(noTrustErr)
+	require.NotNil(forgedErr)
+	require.Contains(forgedErr.Error(), "expected trusted hash")
+
+	// 저장된 trusted header가 있으면 trust 없이 시작함
+	rpc.validators = validators
+	rpc.headers[1] = trustedHeader
+	trusted := lite.NewDBProvider("trusted.mem", dbm.NewMemDB())
+	_, err := newLiteAppHashProvider(testChainID, rpc, trusted, TrustOptions{Height: 1, Hash: trustedHeader.Hash()})
+	require.Nil(err)
+	_, err = newLiteAppHashProvider(testChainID, rpc, trusted, TrustOptions{})
+	require.Nil(err)
+}
@@ -0,0 +1,480 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MultiHTTPClient health check 기본값
+const (
+	DefaultHealthCheckInterval = 5 * time.Second
+	DefaultHealthCheckTimeout  = 2 * time.Second
+)
+
+// Policy는 healthy endpoint 중 read 요청을 먼저 보낼 endpoint를 고르는 방식.
+// 구현은 여러 goroutine에서 동시에 호출될 수 있어야 함.
+type Policy interface {
+	// Select는 healthy endpoint index 목록 중 하나를 return. healthy는 비어 있지 않음.
+	Select(healthy []int) int
+}
+
+// RoundRobinPolicy는 healthy endpoint를 차례대로 고르는 Policy.
+type RoundRobinPolicy struct {
+	next uint64
+}
+
+// Select implements Policy.
+func (policy *RoundRobinPolicy) Select(healthy []int) int {
+	n := atomic.AddUint64(&policy.next, 1) - 1
+	return healthy[n%uint64(len(healthy))]
+}
+
+// MultiOption은 MultiHTTPClient의 설정을 바꾸는 functional option.
+type MultiOption func(*MultiHTTPClient)
+
+// WithPolicy는 read 요청의 endpoint 선택 Policy를 설정함. 기본값은 RoundRobinPolicy.
+func WithPolicy(policy Policy) MultiOption {
+	return func(client *MultiHTTPClient) {
+		client.policy = policy
+	}
+}
+
+// WithHealthCheckInterval은 endpoint 상태 확인 주기를 설정함. 0보다 커야 함.
+func WithHealthCheckInterval(interval time.Duration) MultiOption {
+	return func(client *MultiHTTPClient) {
+		client.healthCheckInterval = interval
+	}
+}
+
+// WithHealthCheckTimeout은 endpoint 상태 확인 한 번의 제한 시간을 설정함. 상태 확인 주기보다 길면 주기로 제한함.
+func WithHealthCheckTimeout(timeout time.Duration) MultiOption {
+	return func(client *MultiHTTPClient) {
+		client.healthCheckTimeout = timeout
+	}
+}
+
+// WithClientOptions는 endpoint별 HTTPClient에 적용할 timeout, retry option을 설정함.
+func WithClientOptions(options ...Option) MultiOption {
+	return func(client *MultiHTTPClient) {
+		client.clientOptions = options
+	}
+}
+
+// MultiHTTPClient는 여러 endpoint를 사용하는 Client.
+// 주기적으로 endpoint의 status를 확인하여 read는 healthy endpoint 사이에 Policy로 분산하고,
+// write는 endpoint 순서대로 healthy endpoint에 보내며 일시적인 error가 나면 다음 endpoint로 failover함.
+type MultiHTTPClient struct {
+	remotes   []string
+	endpoints []*HTTPClient
+
+	policy              Policy
+	healthCheckInterval time.Duration
+	healthCheckTimeout  time.Duration
+	clientOptions       []Option
+
+	mtx     sync.RWMutex
+	healthy []bool
+
+	quit     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+var (
+	_ Client         = (*MultiHTTPClient)(nil)
+	_ ContextClient  = (*MultiHTTPClient)(nil)
+	_ DecodedQuerier = (*MultiHTTPClient)(nil)
+	_ HistoryFetcher = (*MultiHTTPClient)(nil)
+	_ SeriesQuerier  = (*MultiHTTPClient)(nil)
+	_ RangeQuerier   = (*MultiHTTPClient)(nil)
+	_ QLQuerier      = (*MultiHTTPClient)(nil)
+	_ TxStatusClient = (*MultiHTTPClient)(nil)
+	_ Subscriber     = (*MultiHTTPClient)(nil)
+)
+
+// NewMultiHTTPClient는 remotes의 endpoint를 사용하는 MultiHTTPClient를 생성하고 health check를 시작함.
+// 사용이 끝나면 Stop을 호출해야 함.
+func NewMultiHTTPClient(remotes []string, options ...MultiOption) (*MultiHTTPClient, error) {
+	if len(remotes) == 0 {
+		return nil, errors.New("at least one endpoint is required")
+	}
+
+	client := &MultiHTTPClient{
+		remotes:             remotes,
+		policy:              &RoundRobinPolicy{},
+		healthCheckInterval: DefaultHealthCheckInterval,
+		healthCheckTimeout:  DefaultHealthCheckTimeout,
+		healthy:             make([]bool, len(remotes)),
+		quit:                make(chan struct{}),
+	}
+	for _, option := range options {
+		option(client)
+	}
+	if client.healthCheckInterval <= 0 {
+		return nil, errors.Errorf("health check interval must be positive, got %v", client.healthCheckInterval)
+	}
+	// 이전 상태 확인이 끝나기 전에 다음 주기가 오지 않도록 함
+	if client.healthCheckTimeout <= 0 || client.healthCheckTimeout > client.healthCheckInterval {
+		client.healthCheckTimeout = client.healthCheckInterval
+	}
+	for _, remote := range remotes {
+		client.endpoints = append(client.endpoints, NewHTTPClient(remote, client.clientOptions...))
+	}
+
+	client.checkHealth()
+	client.wg.Add(1)
+	go client.healthCheckRoutine()
+
+	return client, nil
+}
+
+// Stop은 health check를 멈춤. 여러 번 호출해도 됨.
+func (client *MultiHTTPClient) Stop() {
+	client.stopOnce.Do(func() {
+		close(client.quit)
+	})
+	client.wg.Wait()
+}
+
+// Healthy는 healthy 상태인 endpoint 주소를 return.
+func (client *MultiHTTPClient) Healthy() []string {
+	var remotes []string
+	for _, i := range client.healthyEndpoints() {
+		remotes = append(remotes, client.remotes[i])
+	}
+	return remotes
+}
+
+func (client *MultiHTTPClient) Put(dataObjs []InputDataObj) (*ctypes.ResultBroadcastTxCommit, error) {
+	return client.PutContext(context.Background(), dataObjs)
+}
+
+func (client *MultiHTTPClient) PutContext(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTxCommit, error) {
+	// 다른 endpoint로 failover해도 같은 rowKey로 write되도록 tx는 한 번만 만듦
+	tx, err := makeTx(dataObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.failover(ctx, client.healthyEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.broadcastTxCommit(ctx, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ctypes.ResultBroadcastTxCommit), nil
+}
+
+func (client *MultiHTTPClient) PutSync(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTx, error) {
+	tx, err := makeTx(dataObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.failover(ctx, client.healthyEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.broadcastTx(ctx, tx, endpoint.caller.BroadcastTxSync)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ctypes.ResultBroadcastTx), nil
+}
+
+func (client *MultiHTTPClient) PutAsync(ctx context.Context, dataObjs []InputDataObj) (*ctypes.ResultBroadcastTx, error) {
+	tx, err := makeTx(dataObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.failover(ctx, client.healthyEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.broadcastTx(ctx, tx, endpoint.caller.BroadcastTxAsync)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ctypes.ResultBroadcastTx), nil
+}
+
+func (client *MultiHTTPClient) TxStatus(ctx context.Context, hash []byte) (*TxStatus, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.TxStatus(ctx, hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*TxStatus), nil
+}
+
+func (client *MultiHTTPClient) WaitForCommit(ctx context.Context, hash []byte) (*TxStatus, error) {
+	return waitForCommit(ctx, hash, client.TxStatus)
+}
+
+func (client *MultiHTTPClient) FindTxs(ctx context.Context, id []byte) ([]*ctypes.ResultTx, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.FindTxs(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.([]*ctypes.ResultTx), nil
+}
+
+func (client *MultiHTTPClient) Query(queryObj InputQueryObj) (*ctypes.ResultABCIQuery, error) {
+	return client.QueryContext(context.Background(), queryObj)
+}
+
+func (client *MultiHTTPClient) QueryContext(ctx context.Context, queryObj InputQueryObj) (*ctypes.ResultABCIQuery, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.QueryContext(ctx, queryObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ctypes.ResultABCIQuery), nil
+}
+
+func (client *MultiHTTPClient) Fetch(fetchObj InputFetchObj) (*ctypes.ResultABCIQuery, error) {
+	return client.FetchContext(context.Background(), fetchObj)
+}
+
+func (client *MultiHTTPClient) FetchContext(ctx context.Context, fetchObj InputFetchObj) (*ctypes.ResultABCIQuery, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.FetchContext(ctx, fetchObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ctypes.ResultABCIQuery), nil
+}
+
+func (client *MultiHTTPClient) QueryDecoded(ctx context.Context, queryObj InputQueryObj) (*ResultQuery, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.QueryDecoded(ctx, queryObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultQuery), nil
+}
+
+func (client *MultiHTTPClient) FetchDecoded(ctx context.Context, fetchObj InputFetchObj) (*ResultFetch, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.FetchDecoded(ctx, fetchObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultFetch), nil
+}
+
+func (client *MultiHTTPClient) FetchHistory(ctx context.Context, fetchObj InputFetchObj) (*ResultHistory, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.FetchHistory(ctx, fetchObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultHistory), nil
+}
+
+func (client *MultiHTTPClient) Series(ctx context.Context, seriesObj InputSeriesObj) (*ResultSeries, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.Series(ctx, seriesObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultSeries), nil
+}
+
+func (client *MultiHTTPClient) Count(ctx context.Context, queryObj InputQueryObj) (*ResultCount, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.Count(ctx, queryObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultCount), nil
+}
+
+func (client *MultiHTTPClient) Latest(ctx context.Context, latestObj InputLatestObj) (*ResultLatest, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.Latest(ctx, latestObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultLatest), nil
+}
+
+func (client *MultiHTTPClient) QL(ctx context.Context, qlObj InputQLObj) (*ResultQL, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.QL(ctx, qlObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultQL), nil
+}
+
+func (client *MultiHTTPClient) Range(ctx context.Context, rangeObj InputRangeObj) (*ResultRange, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.Range(ctx, rangeObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultRange), nil
+}
+
+func (client *MultiHTTPClient) QueryData(ctx context.Context, queryObj InputQueryObj) (*ResultQueryData, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.QueryData(ctx, queryObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*ResultQueryData), nil
+}
+
+// QueryDataStream은 chunk마다 endpoint를 골라 read함. 중간에 endpoint가 바뀌어도 cursor로 이어서 read함.
+func (client *MultiHTTPClient) QueryDataStream(ctx context.Context, queryObj InputQueryObj, chunkSize int, fn func(chunk []OutputDataObj) error) error {
+	return queryDataStream(ctx, chunkSize, fn, func(after []byte) (*ResultQueryData, []byte, error) {
+		var next []byte
+		res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+			res, cursor, err := endpoint.queryDataChunk(ctx, queryObj, chunkSize, after)
+			next = cursor
+			return res, err
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.(*ResultQueryData), next, nil
+	})
+}
+
+// Subscribe는 healthy endpoint 하나에 구독함. 구독 중 연결이 끊기면 해당 endpoint로 재연결을 시도함.
+func (client *MultiHTTPClient) Subscribe(ctx context.Context, filterObj InputSubscribeObj) (*Subscription, error) {
+	res, err := client.failover(ctx, client.balancedEndpoints(), func(endpoint *HTTPClient) (interface{}, error) {
+		return endpoint.Subscribe(ctx, filterObj)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*Subscription), nil
+}
+
+// failover는 order 순서대로 endpoint에 fn을 호출하고 일시적인 error가 나면 해당 endpoint를 unhealthy로 표시한 뒤 다음 endpoint로 넘어감.
+func (client *MultiHTTPClient) failover(ctx context.Context, order []int, fn func(endpoint *HTTPClient) (interface{}, error)) (interface{}, error) {
+	var lastErr error
+	for _, i := range order {
+		res, err := fn(client.endpoints[i])
+		if err == nil {
+			return res, nil
+		}
+		if ctx.Err() != nil || !isTransientError(err) {
+			return nil, err
+		}
+
+		client.setHealthy(i, false)
+		lastErr = errors.Wrapf(err, "endpoint %s", client.remotes[i])
+	}
+
+	return nil, errors.Wrap(lastErr, "all endpoints failed")
+}
+
+// healthyEndpoints는 healthy endpoint의 index를 endpoint 순서대로 return.
+// healthy endpoint가 없으면 상태 정보가 오래되었을 수 있으므로 모든 endpoint를 return.
+func (client *MultiHTTPClient) healthyEndpoints() []int {
+	client.mtx.RLock()
+	defer client.mtx.RUnlock()
+
+	var healthy, all []int
+	for i, ok := range client.healthy {
+		if ok {
+			healthy = append(healthy, i)
+		}
+		all = append(all, i)
+	}
+	if len(healthy) == 0 {
+		return all
+	}
+	return healthy
+}
+
+// balancedEndpoints는 Policy가 고른 endpoint를 맨 앞에 두고 나머지 healthy endpoint를 failover 순서로 붙여 return.
+func (client *MultiHTTPClient) balancedEndpoints() []int {
+	healthy := client.healthyEndpoints()
+	selected := client.policy.Select(healthy)
+
+	order := []int{selected}
+	for _, i := range healthy {
+		if i != selected {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+func (client *MultiHTTPClient) setHealthy(i int, healthy bool) {
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+
+	client.healthy[i] = healthy
+}
+
+func (client *MultiHTTPClient) healthCheckRoutine() {
+	defer client.wg.Done()
+
+	ticker := time.NewTicker(client.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			client.checkHealth()
+		case <-client.quit:
+			return
+		}
+	}
+}
+
+// checkHealth는 모든 endpoint의 status를 healthCheckTimeout 안에 동시에 조회함.
+// 제한 시간 안에 응답이 없거나 block sync 중인 endpoint는 unhealthy.
+func (client *MultiHTTPClient) checkHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), client.healthCheckTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, endpoint := range client.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint *HTTPClient) {
+			defer wg.Done()
+
+			res, err := endpoint.caller.Status(ctx)
+			client.setHealthy(i, err == nil && !res.SyncInfo.CatchingUp)
+		}(i, endpoint)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"time"
+)
+
+func newFakeMultiHTTPClient(rpcs ...*fakeRPCClient) *MultiHTTPClient {
+	client := &MultiHTTPClient{
+		policy:             &RoundRobinPolicy{},
+		healthCheckTimeout: 50 * time.Millisecond,
+		healthy:            make([]bool, len(rpcs)),
+	}
+	for i, rpc := range rpcs {
+		client.remotes = append(client.remotes, fmt.Sprintf("endpoint%d", i))
+		client.endpoints = append(client.endpoints, newFakeHTTPClient(rpc, WithRetry(0, time.Millisecond, time.Millisecond)))
+	}
+	client.checkHealth()
+
+	return client
+}
+
+func TestMultiHTTPClient_Put_failover(t *testing.T) {
+	require := require.New(t)
+	dataObjs := []InputDataObj{{Timestamp: 1547772882435375000, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("testData")}}
+
+	/*
+		첫 endpoint가 실패하면 다음 endpoint로 같은 tx를 보냄
+	*/
+	//given
+	rpc1 := &fakeRPCClient{broadcastErrs: []error{errors.Wrap(io.EOF, "post failed")}}
+	rpc2 := &fakeRPCClient{}
+	client := newFakeMultiHTTPClient(rpc1, rpc2)
+
+	//when
+	res, err := client.Put(dataObjs)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(int64(1), res.Height)
+	require.Equal(1, len(rpc1.broadcastTxs))
+	require.Equal(1, len(rpc2.broadcastTxs))
+	require.Equal(rpc1.broadcastTxs[0], rpc2.broadcastTxs[0])
+	require.Equal([]string{"endpoint1"}, client.Healthy())
+
+	/*
+		unhealthy endpoint에는 보내지 않음
+	*/
+	//given
+	rpc1 = &fakeRPCClient{statusErr: io.EOF}
+	rpc2 = &fakeRPCClient{}
+	client = newFakeMultiHTTPClient(rpc1, rpc2)
+
+	//when
+	_, err = client.Put(dataObjs)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(0, len(rpc1.broadcastTxs))
+	require.Equal(1, len(rpc2.broadcastTxs))
+
+	/*
+		일시적이지 않은 error는 failover하지 않음
+	*/
+	//given
+	rpc1 = &fakeRPCClient{broadcastErrs: []error{errors.New("Response error: invalid tx")}}
+	rpc2 = &fakeRPCClient{}
+	client = newFakeMultiHTTPClient(rpc1, rpc2)
+
+	//when
+	_, err = client.Put(dataObjs)
+
+	//then
+	require.NotNil(err)
+	require.Equal(0, len(rpc2.broadcastTxs))
+}
+
+func TestMultiHTTPClient_Query_loadBalance(t *testing.T) {
+	require := require.New(t)
+	queryObj := InputQueryObj{Start: 1, End: 2}
+
+	//given
+	rpc1 := &fakeRPCClient{}
+	rpc2 := &fakeRPCClient{statusErr: io.EOF}
+	rpc3 := &fakeRPCClient{}
+	client := newFakeMultiHTTPClient(rpc1, rpc2, rpc3)
+
+	//when
+	for i := 0; i < 4; i++ {
+		_, err := client.Query(queryObj)
+		require.Nil(err, "err: %+v", err)
+	}
+
+	//then
+	require.Equal(2, rpc1.queryCount)
+	require.Equal(0, rpc2.queryCount)
+	require.Equal(2, rpc3.queryCount)
+
+	/*
+		health check로 복구된 endpoint는 다시 사용
+	*/
+	//given
+	rpc2.statusErr = nil
+	client.checkHealth()
+
+	//when
+	for i := 0; i < 3; i++ {
+		_, err := client.Query(queryObj)
+		require.Nil(err, "err: %+v", err)
+	}
+
+	//then
+	require.Equal(1, rpc2.queryCount)
+}
+
+func TestMultiHTTPClient_checkHealth_timeout(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	// rpc2는 client timeout보다 짧지만 health check timeout보다 긴 시간 동안 응답하지 않음
+	rpc1 := &fakeRPCClient{}
+	rpc2 := &fakeRPCClient{statusDelay: time.Second}
+	client := newFakeMultiHTTPClient(rpc1, rpc2)
+	client.setHealthy(1, true)
+
+	//when
+	startTime := time.Now()
+	client.checkHealth()
+
+	//then
+	// 응답하지 않는 endpoint는 health check timeout 안에 unhealthy가 됨
+	require.True(time.Since(startTime) < 500*time.Millisecond)
+	require.Equal([]string{"endpoint0"}, client.Healthy())
+}
+
+func TestNewMultiHTTPClient(t *testing.T) {
+	require := require.New(t)
+
+	_, err := NewMultiHTTPClient(nil)
+	require.NotNil(err)
+
+	// health check 주기는 0보다 커야 함
+	_, err = NewMultiHTTPClient([]string{"http://localhost:0"}, WithHealthCheckInterval(0))
+	require.NotNil(err)
+}
+
+func TestMultiHTTPClient_Stop(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	client, err := NewMultiHTTPClient([]string{"http://localhost:0"}, WithHealthCheckTimeout(10*time.Millisecond))
+	require.Nil(err)
+
+	//when
+	client.Stop()
+
+	//then
+	// 두 번째 호출도 panic하지 않음
+	require.NotPanics(client.Stop)
+}
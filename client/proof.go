@@ -0,0 +1,192 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	rpcClient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"strings"
+	"time"
+)
+
+// heightNotCommittedErrMsg는 rpc Commit 조회 시 아직 commit되지 않은 height를 요청했을 때의 error message.
+const heightNotCommittedErrMsg = "must be less than or equal to the current blockchain height"
+
+// AppHashProvider는 query 결과 검증에 사용할 신뢰할 수 있는 app hash를 제공함.
+type AppHashProvider interface {
+	// AppHash는 height block까지 commit된 app state의 hash를 return. tendermint에서 이 값은 height+1 block header에 담김.
+	AppHash(ctx context.Context, height int64) ([]byte, error)
+}
+
+// WithProofVerification은 모든 query에 proof를 요청하고 provider의 app hash로 결과를 검증하도록 설정함.
+// 검증에 실패하면 query는 error를 return함. proof는 결과에 담긴 row가 app state에 있음을 증명하며
+// 조건에 맞는 row가 빠짐없이 담겼음을 증명하지는 않음.
+func WithProofVerification(provider AppHashProvider) Option {
+	return func(client *HTTPClient) {
+		client.appHashProvider = provider
+	}
+}
+
+// NodeAppHashProvider는 remote node의 block header에서 app hash를 읽는 AppHashProvider.
+// header의 서명은 검증하지 않으므로 query를 보내는 node와 별개인 신뢰할 수 있는 node를 사용해야 함.
+type NodeAppHashProvider struct {
+	rpcClient rpcClient.Client
+}
+
+// NewNodeAppHashProvider는 remote node를 사용하는 NodeAppHashProvider를 생성함.
+func NewNodeAppHashProvider(remote string) *NodeAppHashProvider {
+	return &NodeAppHashProvider{rpcClient: rpcClient.NewHTTP(remote, consts.WsEndpoint)}
+}
+
+// AppHash는 height+1 block header의 app hash를 return. height+1 block이 아직 commit되지 않았으면 commit될 때까지 기다림.
+func (provider *NodeAppHashProvider) AppHash(ctx context.Context, height int64) ([]byte, error) {
+	res, err := waitCommit(ctx, provider.rpcClient, height+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Header.AppHash, nil
+}
+
+// waitCommit은 height block의 signed header를 return. height block이 아직 commit되지 않았으면 commit될 때까지 기다림.
+func waitCommit(ctx context.Context, client rpcClient.Client, height int64) (*ctypes.ResultCommit, error) {
+	ticker := time.NewTicker(CommitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		res, err := client.Commit(&height)
+		if err == nil {
+			return res, nil
+		}
+		if !strings.Contains(err.Error(), heightNotCommittedErrMsg) {
+			return nil, errors.Wrap(err, "get header failed")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "header of height %v is not committed", height)
+		}
+	}
+}
+
+// rowHash는 검증할 row의 rowKey와 client가 받은 데이터로 계산한 hash. 받지 않은 쪽의 hash는 nil.
+type rowHash struct {
+	rowKey   []byte
+	metaHash []byte
+	realHash []byte
+}
+
+// verifyResponse는 path의 query 결과에 담긴 모든 row가 res.Height의 app hash에 포함되어 있는지 검증함.
+func (client *HTTPClient) verifyResponse(ctx context.Context, path string, res abciTypes.ResponseQuery) error {
+	if res.IsErr() {
+		return nil
+	}
+
+	rows, err := responseRows(path, res.Value)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	rowProofs := make(map[string]types.RowProof)
+	if res.Proof != nil {
+		for _, op := range res.Proof.Ops {
+			if op.Type != consts.RowProofOpType {
+				continue
+			}
+			var rowProof types.RowProof
+			if err := json.Unmarshal(op.Data, &rowProof); err != nil {
+				return errors.Wrap(err, "unmarshal proof failed")
+			}
+			rowProofs[string(op.Key)] = rowProof
+		}
+	}
+
+	appHash, err := client.appHashProvider.AppHash(ctx, res.Height)
+	if err != nil {
+		return errors.Wrap(err, "get app hash failed")
+	}
+
+	for _, row := range rows {
+		rowProof, ok := rowProofs[string(row.rowKey)]
+		if !ok {
+			return errors.Errorf("proof of %X is missing", row.rowKey)
+		}
+		if row.metaHash != nil && !bytes.Equal(row.metaHash, rowProof.MetaHash) {
+			return errors.Errorf("metadata of %X does not match proof", row.rowKey)
+		}
+		if row.realHash != nil && !bytes.Equal(row.realHash, rowProof.RealHash) {
+			return errors.Errorf("data of %X does not match proof", row.rowKey)
+		}
+		if !bytes.Equal(rowProof.ComputeRootHash(row.rowKey), appHash) {
+			return errors.Errorf("proof of %X does not match app hash %X at height %v", row.rowKey, appHash, res.Height)
+		}
+	}
+
+	return nil
+}
+
+// responseRows는 path에 따라 query 결과를 decode하여 검증할 row의 hash를 return.
+func responseRows(path string, value []byte) ([]rowHash, error) {
+	var rows []rowHash
+	switch path {
+	case consts.QueryPath:
+		var metaDataObjs []types.MetaDataObj
+		if err := json.Unmarshal(value, &metaDataObjs); err != nil {
+			return nil, errors.Wrap(err, "unmarshal failed")
+		}
+		for _, metaDataObj := range metaDataObjs {
+			rows = append(rows, rowHash{rowKey: metaDataObj.RowKey, metaHash: types.MetaDataHash(metaDataObj.OwnerId, metaDataObj.Qualifier)})
+		}
+	case consts.FetchPath:
+		var realDataObjs []types.RealDataObj
+		if err := json.Unmarshal(value, &realDataObjs); err != nil {
+			return nil, errors.Wrap(err, "unmarshal failed")
+		}
+		for _, realDataObj := range realDataObjs {
+			rows = append(rows, rowHash{rowKey: realDataObj.RowKey, realHash: types.RealDataHash(realDataObj.Data)})
+		}
+	case consts.QueryDataPath:
+		var queryDataResObj types.QueryDataResObj
+		if err := json.Unmarshal(value, &queryDataResObj); err != nil {
+			return nil, errors.Wrap(err, "unmarshal failed")
+		}
+		for _, baseDataObj := range queryDataResObj.Data {
+			metaDataObj := baseDataObj.MetaData
+			rows = append(rows, rowHash{rowKey: metaDataObj.RowKey, metaHash: types.MetaDataHash(metaDataObj.OwnerId, metaDataObj.Qualifier), realHash: types.RealDataHash(baseDataObj.RealData.Data)})
+		}
+	case consts.LatestPath:
+		var baseDataObjs []types.BaseDataObj
+		if err := json.Unmarshal(value, &baseDataObjs); err != nil {
+			return nil, errors.Wrap(err, "unmarshal failed")
+		}
+		for _, baseDataObj := range baseDataObjs {
+			metaDataObj := baseDataObj.MetaData
+			rows = append(rows, rowHash{rowKey: metaDataObj.RowKey, metaHash: types.MetaDataHash(metaDataObj.OwnerId, metaDataObj.Qualifier), realHash: types.RealDataHash(baseDataObj.RealData.Data)})
+		}
+	case consts.QLPath:
+		// SELECT * 결과의 데이터만 검증하며 집계 결과는 merkle tree의 row가 아님
+		var qlResObj types.QLResObj
+		if err := json.Unmarshal(value, &qlResObj); err != nil {
+			return nil, errors.Wrap(err, "unmarshal failed")
+		}
+		for _, baseDataObj := range qlResObj.Data {
+			metaDataObj := baseDataObj.MetaData
+			rows = append(rows, rowHash{rowKey: metaDataObj.RowKey, metaHash: types.MetaDataHash(metaDataObj.OwnerId, metaDataObj.Qualifier), realHash: types.RealDataHash(baseDataObj.RealData.Data)})
+		}
+	case consts.HistoryPath, consts.SeriesPath, consts.RangePath, consts.CountPath:
+		// history, series, chunk와 count 결과는 merkle tree의 row가 아니므로 검증할 row가 없음
+	default:
+		return nil, errors.Errorf("unknown query path %s", path)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/stretchr/testify/require"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	rpcClient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"testing"
+)
+
+// fakeProofRPCClient는 주어진 결과와 proof를 return하는 rpc client.
+type fakeProofRPCClient struct {
+	rpcCaller
+
+	response abciTypes.ResponseQuery
+	opts     rpcClient.ABCIQueryOptions
+}
+
+func (c *fakeProofRPCClient) ABCIQueryWithOptions(ctx context.Context, path string, data cmn.HexBytes, opts rpcClient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	c.opts = opts
+	return &ctypes.ResultABCIQuery{Response: c.response}, nil
+}
+
+type staticAppHashProvider []byte
+
+func (provider staticAppHashProvider) AppHash(ctx context.Context, height int64) ([]byte, error) {
+	return provider, nil
+}
+
+func TestHTTPClient_Fetch_proofVerification(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	var realDataObjs []types.RealDataObj
+	var rowProofs []types.RowProof
+	var leaves [][]byte
+	for i := 0; i < 3; i++ {
+		rowKey := types.GetRowKey(uint64(1547772882435375000+i), 0)
+		realDataObj := types.RealDataObj{RowKey: rowKey, Data: []byte{byte(i)}}
+		rowProof := types.RowProof{MetaHash: types.MetaDataHash(TestOwnerId, []byte(TestQualifier)), RealHash: types.RealDataHash(realDataObj.Data)}
+		realDataObjs = append(realDataObjs, realDataObj)
+		rowProofs = append(rowProofs, rowProof)
+		leaves = append(leaves, types.RowLeaf(rowKey, rowProof.MetaHash, rowProof.RealHash))
+	}
+	appHash, simpleProofs := merkle.SimpleProofsFromByteSlices(leaves)
+
+	proof := &merkle.Proof{}
+	for i, simpleProof := range simpleProofs {
+		rowProofs[i].Index, rowProofs[i].Total, rowProofs[i].Aunts = simpleProof.Index, simpleProof.Total, simpleProof.Aunts
+		data, err := json.Marshal(rowProofs[i])
+		require.Nil(err)
+		proof.Ops = append(proof.Ops, merkle.ProofOp{Type: consts.RowProofOpType, Key: realDataObjs[i].RowKey, Data: data})
+	}
+	value, err := json.Marshal(realDataObjs)
+	require.Nil(err)
+
+	rpc := &fakeProofRPCClient{response: abciTypes.ResponseQuery{Value: value, Proof: proof, Height: 1}}
+	client := &HTTPClient{caller: rpc, appHashProvider: staticAppHashProvider(appHash)}
+	fetchObj := InputFetchObj{Ids: [][]byte{realDataObjs[0].RowKey, realDataObjs[1].RowKey, realDataObjs[2].RowKey}}
+
+	//when
+	res, err := client.Fetch(fetchObj)
+
+	//then
+	require.Nil(err)
+	var outputFetchObjs []OutputFetchObj
+	require.Nil(json.Unmarshal(res.Response.Value, &outputFetchObjs))
+	require.Equal(toOutputFetchObjs(realDataObjs), outputFetchObjs)
+
+	// 데이터가 바뀌면 검증에 실패함
+	realDataObjs[1].Data = []byte("tampered")
+	rpc.response.Value, err = json.Marshal(realDataObjs)
+	require.Nil(err)
+	_, err = client.Fetch(fetchObj)
+	require.NotNil(err)
+
+	// proof가 빠지면 검증에 실패함
+	realDataObjs[1].Data = []byte{1}
+	rpc.response.Value, err = json.Marshal(realDataObjs)
+	require.Nil(err)
+	rpc.response.Proof = &merkle.Proof{Ops: proof.Ops[:2]}
+	_, err = client.Fetch(fetchObj)
+	require.NotNil(err)
+
+	// 다른 app hash로는 검증에 실패함
+	rpc.response.Proof = proof
+	client.appHashProvider = staticAppHashProvider(merkle.SimpleHashFromByteSlices(leaves[:2]))
+	_, err = client.Fetch(fetchObj)
+	require.NotNil(err)
+}
+
+func TestHTTPClient_Query_height(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	rpc := &fakeProofRPCClient{response: abciTypes.ResponseQuery{Value: []byte("[]"), Height: 3}}
+	client := &HTTPClient{caller: rpc}
+
+	//when
+	res, err := client.QueryDecoded(context.Background(), InputQueryObj{Start: 1, End: 2, Height: 3})
+
+	//then
+	require.Nil(err)
+	require.Equal(int64(3), res.Height)
+	require.Equal(rpcClient.ABCIQueryOptions{Height: 3}, rpc.opts)
+}
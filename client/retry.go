@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// HTTPClient option 기본값
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 2 * time.Second
+)
+
+// tendermint rpc가 return하는 error message 중 재시도로 해결될 수 있는 것들
+const (
+	txInCacheErrMsg       = "Tx already exists in cache"
+	txCommitTimeoutErrMsg = "Timed out waiting for tx to be included in a block"
+	mempoolFullErrMsg     = "Mempool is full"
+	connRefusedErrMsg     = "connection refused"
+)
+
+// txNotFoundErrMsg는 rpc Tx 조회 시 tx가 아직 commit되지 않았을 때의 error message.
+const txNotFoundErrMsg = "not found"
+
+// errTxPending은 이전 시도에서 broadcast된 tx가 mempool에 있지만 아직 commit되지 않았을 때의 error.
+var errTxPending = errors.New("tx is in the mempool but not committed yet")
+
+// Option은 HTTPClient의 timeout, retry 설정을 바꾸는 functional option.
+type Option func(*HTTPClient)
+
+// WithTimeout은 rpc 호출 한 번의 제한 시간을 설정함. 0이면 제한 없음.
+// 재시도를 포함한 전체 제한 시간은 ctx로 설정함.
+func WithTimeout(timeout time.Duration) Option {
+	return func(client *HTTPClient) {
+		client.timeout = timeout
+	}
+}
+
+// WithRetry는 일시적인 rpc error의 최대 재시도 횟수와 backoff를 설정함. maxRetries가 0이면 재시도하지 않음.
+// 재시도 간격은 minBackoff부터 두 배씩 늘어나며 maxBackoff를 넘지 않음.
+func WithRetry(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(client *HTTPClient) {
+		client.maxRetries = maxRetries
+		client.minBackoff = minBackoff
+		client.maxBackoff = maxBackoff
+	}
+}
+
+// retry는 fn을 호출하고 일시적인 error이면 backoff 후 다시 호출함.
+// fn은 매 시도마다 같은 tx, query를 사용해야 함.
+func (client *HTTPClient) retry(ctx context.Context, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	backoff := client.minBackoff
+	for attempt := 0; ; attempt++ {
+		res, err := client.call(ctx, fn)
+		if err == nil {
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			return nil, errors.Wrap(ctx.Err(), err.Error())
+		}
+		if attempt >= client.maxRetries || !isTransientError(err) {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), err.Error())
+		}
+		if backoff *= 2; backoff > client.maxBackoff {
+			backoff = client.maxBackoff
+		}
+	}
+}
+
+// call은 client.timeout을 적용한 ctx로 fn을 한 번 호출함. 제한 시간이 지나면 fn의 rpc 호출이 ctx와 함께 중단되며
+// ctx.Err()를 cause로 하는 error를 return.
+func (client *HTTPClient) call(ctx context.Context, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	if client.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.timeout)
+		defer cancel()
+	}
+
+	res, err := fn(ctx)
+	if err != nil && ctx.Err() != nil && errors.Cause(err) != ctx.Err() {
+		return nil, errors.Wrap(ctx.Err(), err.Error())
+	}
+	return res, err
+}
+
+// isTransientError는 err가 재시도로 해결될 수 있는 network, timeout, mempool error인지 return.
+func isTransientError(err error) bool {
+	cause := errors.Cause(err)
+	switch cause {
+	case context.DeadlineExceeded, io.EOF, io.ErrUnexpectedEOF, errTxPending:
+		return true
+	}
+	if _, ok := cause.(net.Error); ok {
+		return true
+	}
+
+	msg := err.Error()
+	for _, transientMsg := range []string{txInCacheErrMsg, txCommitTimeoutErrMsg, mempoolFullErrMsg, connRefusedErrMsg} {
+		if strings.Contains(msg, transientMsg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	rpcClient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRPCClient는 호출마다 미리 정해둔 결과를 return하는 rpc client.
+type fakeRPCClient struct {
+	broadcastErrs []error
+	broadcastTxs  []tmtypes.Tx
+	txErrs        []error
+	statusErr     error
+	statusDelay   time.Duration
+	queryDelay    time.Duration
+	searchTxs     []*ctypes.ResultTx
+
+	queryMtx   sync.Mutex
+	queryCount int
+}
+
+func (c *fakeRPCClient) BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	c.broadcastTxs = append(c.broadcastTxs, tx)
+	if len(c.broadcastErrs) > 0 {
+		err := c.broadcastErrs[0]
+		c.broadcastErrs = c.broadcastErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &ctypes.ResultBroadcastTxCommit{DeliverTx: abciTypes.ResponseDeliverTx{Code: code.CodeTypeOK}, Height: 1}, nil
+}
+
+func (c *fakeRPCClient) BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return c.broadcastTx(tx)
+}
+
+func (c *fakeRPCClient) BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return c.broadcastTx(tx)
+}
+
+func (c *fakeRPCClient) broadcastTx(tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	c.broadcastTxs = append(c.broadcastTxs, tx)
+	if len(c.broadcastErrs) > 0 {
+		err := c.broadcastErrs[0]
+		c.broadcastErrs = c.broadcastErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &ctypes.ResultBroadcastTx{Code: code.CodeTypeOK, Hash: tx.Hash()}, nil
+}
+
+func (c *fakeRPCClient) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	if len(c.txErrs) > 0 {
+		err := c.txErrs[0]
+		c.txErrs = c.txErrs[1:]
+		return nil, err
+	}
+	return &ctypes.ResultTx{Hash: hash, Height: 2, TxResult: abciTypes.ResponseDeliverTx{Code: code.CodeTypeOK}}, nil
+}
+
+func (c *fakeRPCClient) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	select {
+	case <-time.After(c.statusDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if c.statusErr != nil {
+		return nil, c.statusErr
+	}
+	return &ctypes.ResultStatus{}, nil
+}
+
+func (c *fakeRPCClient) ABCIQueryWithOptions(ctx context.Context, path string, data cmn.HexBytes, opts rpcClient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	c.queryMtx.Lock()
+	c.queryCount++
+	c.queryMtx.Unlock()
+	select {
+	case <-time.After(c.queryDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &ctypes.ResultABCIQuery{Response: abciTypes.ResponseQuery{Value: []byte("[]")}}, nil
+}
+
+func (c *fakeRPCClient) TxSearch(ctx context.Context, query string, prove bool, page, perPage int) (*ctypes.ResultTxSearch, error) {
+	start := (page - 1) * perPage
+	end := start + perPage
+	if end > len(c.searchTxs) {
+		end = len(c.searchTxs)
+	}
+	return &ctypes.ResultTxSearch{Txs: c.searchTxs[start:end], TotalCount: len(c.searchTxs)}, nil
+}
+
+func newFakeHTTPClient(rpc *fakeRPCClient, options ...Option) *HTTPClient {
+	client := &HTTPClient{caller: rpc, timeout: DefaultTimeout, maxRetries: DefaultMaxRetries, minBackoff: time.Millisecond, maxBackoff: 4 * time.Millisecond}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+func TestHTTPClient_PutContext_retry(t *testing.T) {
+	require := require.New(t)
+	dataObjs := []InputDataObj{{Timestamp: 1547772882435375000, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("testData")}}
+
+	/*
+		일시적인 error는 같은 tx로 재시도
+	*/
+	//given
+	rpc := &fakeRPCClient{broadcastErrs: []error{errors.Wrap(io.EOF, "post failed"), errors.New("Response error: " + mempoolFullErrMsg)}}
+	client := newFakeHTTPClient(rpc)
+
+	//when
+	res, err := client.PutContext(context.Background(), dataObjs)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(int64(1), res.Height)
+	require.Equal(3, len(rpc.broadcastTxs))
+	require.Equal(rpc.broadcastTxs[0], rpc.broadcastTxs[1])
+	require.Equal(rpc.broadcastTxs[0], rpc.broadcastTxs[2])
+
+	/*
+		이전 시도의 tx가 이미 mempool cache에 있으면 commit 결과를 조회
+	*/
+	//given
+	rpc = &fakeRPCClient{broadcastErrs: []error{errors.New("Response error: " + txCommitTimeoutErrMsg), errors.New("Response error: " + txInCacheErrMsg)}}
+	client = newFakeHTTPClient(rpc)
+
+	//when
+	res, err = client.PutContext(context.Background(), dataObjs)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(int64(2), res.Height)
+	require.Equal(2, len(rpc.broadcastTxs))
+
+	/*
+		일시적이지 않은 error는 재시도하지 않음
+	*/
+	//given
+	rpc = &fakeRPCClient{broadcastErrs: []error{errors.New("Response error: invalid tx")}}
+	client = newFakeHTTPClient(rpc)
+
+	//when
+	_, err = client.PutContext(context.Background(), dataObjs)
+
+	//then
+	require.NotNil(err)
+	require.Equal(1, len(rpc.broadcastTxs))
+
+	/*
+		재시도 횟수 초과
+	*/
+	//given
+	rpc = &fakeRPCClient{broadcastErrs: []error{io.EOF, io.EOF, io.EOF}}
+	client = newFakeHTTPClient(rpc, WithRetry(2, time.Millisecond, time.Millisecond))
+
+	//when
+	_, err = client.PutContext(context.Background(), dataObjs)
+
+	//then
+	require.NotNil(err)
+	require.Equal(3, len(rpc.broadcastTxs))
+}
+
+func TestHTTPClient_QueryContext_timeout(t *testing.T) {
+	require := require.New(t)
+	queryObj := InputQueryObj{Start: 1, End: 2}
+
+	/*
+		호출당 timeout 초과 시 재시도
+	*/
+	//given
+	rpc := &fakeRPCClient{queryDelay: 50 * time.Millisecond}
+	client := newFakeHTTPClient(rpc, WithTimeout(10*time.Millisecond), WithRetry(1, time.Millisecond, time.Millisecond))
+
+	//when
+	_, err := client.QueryContext(context.Background(), queryObj)
+
+	//then
+	require.NotNil(err)
+	require.Equal(context.DeadlineExceeded, errors.Cause(err))
+
+	/*
+		ctx 만료 시 재시도하지 않고 return
+	*/
+	//given
+	client = newFakeHTTPClient(rpc, WithTimeout(0))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	//when
+	startTime := time.Now()
+	_, err = client.QueryContext(ctx, queryObj)
+
+	//then
+	require.NotNil(err)
+	require.True(time.Since(startTime) < 50*time.Millisecond)
+
+	/*
+		timeout 안에 응답
+	*/
+	//given
+	rpc = &fakeRPCClient{}
+	client = newFakeHTTPClient(rpc, WithTimeout(time.Second))
+
+	//when
+	res, err := client.QueryContext(context.Background(), queryObj)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(1, rpc.queryCount)
+	require.NotNil(res)
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	amino "github.com/tendermint/go-amino"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	rpcClient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// rpcCaller는 HTTPClient가 사용하는 tendermint rpc 호출. 모든 호출은 ctx가 만료되면 응답을 기다리지 않고 중단됨.
+type rpcCaller interface {
+	BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTxCommit, error)
+	BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error)
+	BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error)
+	Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error)
+	TxSearch(ctx context.Context, query string, prove bool, page, perPage int) (*ctypes.ResultTxSearch, error)
+	ABCIQueryWithOptions(ctx context.Context, path string, data cmn.HexBytes, opts rpcClient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error)
+	Status(ctx context.Context) (*ctypes.ResultStatus, error)
+}
+
+// jsonRPCCaller는 tendermint jsonrpc endpoint를 http request의 context와 함께 호출하는 rpcCaller.
+// tendermint rpc client는 context를 받지 않으므로 같은 request를 직접 만들어 보냄.
+type jsonRPCCaller struct {
+	address string
+	client  *http.Client
+	cdc     *amino.Codec
+}
+
+// newJSONRPCCaller는 remote에 대한 jsonRPCCaller를 return. remote 형식은 tendermint rpc client와 같음.
+func newJSONRPCCaller(remote string) *jsonRPCCaller {
+	clientProtocol, protocol, address := "http", "tcp", remote
+	if parts := strings.SplitN(remote, "://", 2); len(parts) == 2 {
+		protocol, address = parts[0], parts[1]
+	}
+	switch protocol {
+	case "http", "https":
+		clientProtocol, protocol = protocol, "tcp"
+	}
+
+	cdc := amino.NewCodec()
+	ctypes.RegisterAmino(cdc)
+	dialer := &net.Dialer{}
+	return &jsonRPCCaller{
+		// unix socket 경로의 /는 host로 쓸 수 있도록 .으로 바꿈
+		address: clientProtocol + "://" + strings.Replace(address, "/", ".", -1),
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, protocol, address)
+				},
+			},
+		},
+		cdc: cdc,
+	}
+}
+
+// call은 method를 params로 호출하고 응답을 result에 decode함.
+func (caller *jsonRPCCaller) call(ctx context.Context, method string, params map[string]interface{}, result interface{}) error {
+	request, err := rpctypes.MapToRequest(caller.cdc, rpctypes.JSONRPCStringID("jsonrpc-client"), method, params)
+	if err != nil {
+		return err
+	}
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequest(http.MethodPost, caller.address, bytes.NewReader(requestBytes))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "text/json")
+
+	httpResponse, err := caller.client.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	responseBytes, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+
+	var response rpctypes.RPCResponse
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return errors.Wrap(err, "error unmarshalling rpc response")
+	}
+	if response.Error != nil {
+		return errors.Errorf("Response error: %v", response.Error)
+	}
+	if err := caller.cdc.UnmarshalJSON(response.Result, result); err != nil {
+		return errors.Wrap(err, "error unmarshalling rpc response result")
+	}
+	return nil
+}
+
+func (caller *jsonRPCCaller) BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	result := new(ctypes.ResultBroadcastTxCommit)
+	if err := caller.call(ctx, "broadcast_tx_commit", map[string]interface{}{"tx": tx}, result); err != nil {
+		return nil, errors.Wrap(err, "broadcast_tx_commit")
+	}
+	return result, nil
+}
+
+func (caller *jsonRPCCaller) BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return caller.broadcastTx(ctx, "broadcast_tx_sync", tx)
+}
+
+func (caller *jsonRPCCaller) BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return caller.broadcastTx(ctx, "broadcast_tx_async", tx)
+}
+
+func (caller *jsonRPCCaller) broadcastTx(ctx context.Context, method string, tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	result := new(ctypes.ResultBroadcastTx)
+	if err := caller.call(ctx, method, map[string]interface{}{"tx": tx}, result); err != nil {
+		return nil, errors.Wrap(err, method)
+	}
+	return result, nil
+}
+
+func (caller *jsonRPCCaller) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	result := new(ctypes.ResultTx)
+	if err := caller.call(ctx, "tx", map[string]interface{}{"hash": hash, "prove": prove}, result); err != nil {
+		return nil, errors.Wrap(err, "tx")
+	}
+	return result, nil
+}
+
+func (caller *jsonRPCCaller) TxSearch(ctx context.Context, query string, prove bool, page, perPage int) (*ctypes.ResultTxSearch, error) {
+	result := new(ctypes.ResultTxSearch)
+	params := map[string]interface{}{"query": query, "prove": prove, "page": page, "per_page": perPage}
+	if err := caller.call(ctx, "tx_search", params, result); err != nil {
+		return nil, errors.Wrap(err, "tx_search")
+	}
+	return result, nil
+}
+
+func (caller *jsonRPCCaller) ABCIQueryWithOptions(ctx context.Context, path string, data cmn.HexBytes, opts rpcClient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	result := new(ctypes.ResultABCIQuery)
+	params := map[string]interface{}{"path": path, "data": data, "height": opts.Height, "prove": opts.Prove}
+	if err := caller.call(ctx, "abci_query", params, result); err != nil {
+		return nil, errors.Wrap(err, "abci_query")
+	}
+	return result, nil
+}
+
+func (caller *jsonRPCCaller) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	result := new(ctypes.ResultStatus)
+	if err := caller.call(ctx, "status", map[string]interface{}{}, result); err != nil {
+		return nil, errors.Wrap(err, "status")
+	}
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient_call_cancel(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	// 응답하지 않는 rpc server. request가 취소되면 canceled로 알림
+	canceled := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		<-r.Context().Done()
+		canceled <- struct{}{}
+	}))
+	defer srv.Close()
+	client := &HTTPClient{caller: newJSONRPCCaller(srv.URL), timeout: 10 * time.Millisecond}
+
+	//when
+	_, err := client.QueryContext(context.Background(), InputQueryObj{Start: 1, End: 2})
+
+	//then
+	// 제한 시간이 지나면 진행 중인 http request를 취소함
+	require.NotNil(err)
+	require.Equal(context.DeadlineExceeded, errors.Cause(err))
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		require.Fail("rpc request is not canceled")
+	}
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GetMasterStatus는 master HTTP server(remote)의 /readyz를 호출하여 MasterStatus를 return.
+// master가 ready가 아닌 경우에도 응답을 받았다면 error 없이 MasterStatus.Ready가 false로 return됨.
+func GetMasterStatus(remote string) (*MasterStatus, error) {
+	if !strings.Contains(remote, "://") {
+		remote = "http://" + remote
+	}
+
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	res, err := httpClient.Get(remote + "/readyz")
+	if err != nil {
+		return nil, errors.Wrap(err, "get readyz failed")
+	}
+	defer res.Body.Close()
+
+	var status MasterStatus
+	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+		return nil, errors.Wrap(err, "unmarshal failed")
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"strings"
+)
+
+// DefaultSubscriptionBufferSize는 Subscribe가 return하는 channel의 buffer 크기.
+const DefaultSubscriptionBufferSize = 100
+
+// ErrSubscriptionDropped는 Subscription의 buffer가 가득 차 client가 구독을 해제했을 때 Err가 return하는 error.
+var ErrSubscriptionDropped = errors.New("subscription dropped: buffer is full")
+
+// subscriberName은 tendermint event subscription의 subscriber 이름. tendermint가 remote address로 덮어쓰므로 구분용으로만 사용함.
+const subscriberName = "paust-db-client"
+
+// eventSubscription은 같은 tendermint event query를 사용하는 subscription 묶음.
+// tendermint는 연결마다 같은 query를 한 번만 구독할 수 있으므로 query가 같은 Subscribe는 event 구독을 공유함.
+type eventSubscription struct {
+	query *tmquery.Query
+	subs  map[*Subscription]struct{}
+}
+
+// Subscription은 Subscribe 한 번에 해당하는 filter와 channel.
+type Subscription struct {
+	ctx       context.Context
+	filterObj InputSubscribeObj
+	eventSub  *eventSubscription
+	events    chan []types.BaseDataObj
+	out       chan OutputDataObj
+	done      chan struct{}
+
+	// dropped는 buffer가 가득 차 구독을 해제할 때 HTTPClient.subMtx를 잡고 설정함.
+	// err는 구독이 끝난 이유이며 closed가 닫힌 뒤에만 read함.
+	dropped bool
+	err     error
+	closed  chan struct{}
+}
+
+// Out은 filter와 일치하는 데이터를 전달하는 channel을 return. 구독이 끝나면 닫힘.
+func (sub *Subscription) Out() <-chan OutputDataObj {
+	return sub.out
+}
+
+// Err는 Out이 닫힌 뒤 구독이 끝난 이유를 return. buffer가 가득 차 client가 구독을 해제했으면 ErrSubscriptionDropped를,
+// Subscribe의 ctx가 만료되었으면 ctx.Err()를 return하며 구독 중에는 nil을 return.
+func (sub *Subscription) Err() error {
+	select {
+	case <-sub.closed:
+		return sub.err
+	default:
+		return nil
+	}
+}
+
+func (client *HTTPClient) Subscribe(ctx context.Context, filterObj InputSubscribeObj) (*Subscription, error) {
+	if len(filterObj.OwnerId) > consts.OwnerIdLenLimit {
+		return nil, errors.Errorf("%s: wrong ownerId length. Expect %v or below, got %v", filterObj.OwnerId, consts.OwnerIdLenLimit, len(filterObj.OwnerId))
+	}
+
+	client.eventMtx.Lock()
+	defer client.eventMtx.Unlock()
+
+	query := putEventQuery(filterObj)
+	client.subMtx.Lock()
+	eventSub, ok := client.eventSubs[query.String()]
+	client.subMtx.Unlock()
+	if !ok {
+		var err error
+		if eventSub, err = client.subscribeEvents(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &Subscription{
+		ctx:       ctx,
+		filterObj: filterObj,
+		eventSub:  eventSub,
+		events:    make(chan []types.BaseDataObj, DefaultSubscriptionBufferSize),
+		out:       make(chan OutputDataObj, DefaultSubscriptionBufferSize),
+		done:      make(chan struct{}),
+		closed:    make(chan struct{}),
+	}
+	client.subMtx.Lock()
+	eventSub.subs[sub] = struct{}{}
+	client.subMtx.Unlock()
+
+	go sub.run()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-sub.done:
+		}
+		client.unsubscribe(sub)
+	}()
+
+	return sub, nil
+}
+
+// putEventQuery는 filterObj와 일치하는 데이터를 담은 put tx가 commit될 때 발생하는 tendermint Tx event의 query를 return.
+// tendermint event는 tag key마다 마지막 값만 담으므로 tx의 모든 ownerId, qualifier type을 담은 list tag로 찾음.
+// list tag는 다른 값의 일부와도 일치할 수 있고 하나의 tx에 여러 ownerId가 담길 수 있으므로 filter는 client에서 다시 적용함.
+func putEventQuery(filterObj InputSubscribeObj) *tmquery.Query {
+	conditions := []string{
+		fmt.Sprintf("%s='%s'", tmtypes.EventTypeKey, tmtypes.EventTx),
+		fmt.Sprintf("%s='%s'", consts.ActionTagKey, consts.PutAction),
+	}
+	// query 문법상 따옴표를 담은 값은 조건에 넣을 수 없으므로 client에서만 filter함
+	if ownerId := filterObj.OwnerId; ownerId != "" && !strings.ContainsAny(ownerId, `'"`) {
+		conditions = append(conditions, fmt.Sprintf("%s CONTAINS '%s'", consts.OwnerIdsTagKey, consts.TagListSeparator+ownerId+consts.TagListSeparator))
+	}
+	var qualifier struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(filterObj.Qualifier), &qualifier); err == nil && qualifier.Type != "" && !strings.ContainsAny(qualifier.Type, `'"`) {
+		conditions = append(conditions, fmt.Sprintf("%s CONTAINS '%s'", consts.QualifierTypesTagKey, consts.TagListSeparator+qualifier.Type+consts.TagListSeparator))
+	}
+
+	return tmquery.MustParse(strings.Join(conditions, " AND "))
+}
+
+// subscribeEvents는 websocket 연결을 시작하고 query의 put tx event를 구독함.
+func (client *HTTPClient) subscribeEvents(ctx context.Context, query *tmquery.Query) (*eventSubscription, error) {
+	if err := client.rpcClient.Start(); err != nil && err != cmn.ErrAlreadyStarted {
+		return nil, errors.Wrap(err, "websocket connection failed")
+	}
+
+	events := make(chan interface{}, DefaultSubscriptionBufferSize)
+	if err := client.rpcClient.Subscribe(ctx, subscriberName, query, events); err != nil {
+		return nil, errors.Wrap(err, "subscribe failed")
+	}
+
+	eventSub := &eventSubscription{query: query, subs: make(map[*Subscription]struct{})}
+	client.subMtx.Lock()
+	if client.eventSubs == nil {
+		client.eventSubs = make(map[string]*eventSubscription)
+	}
+	client.eventSubs[query.String()] = eventSub
+	client.subMtx.Unlock()
+
+	go client.dispatchEvents(eventSub, events)
+
+	return eventSub, nil
+}
+
+// unsubscribe는 sub를 제거하고 같은 query의 subscription이 남아 있지 않으면 event 구독을 해제함. 이미 제거된 sub는 무시함.
+func (client *HTTPClient) unsubscribe(sub *Subscription) {
+	client.eventMtx.Lock()
+	defer client.eventMtx.Unlock()
+
+	eventSub := sub.eventSub
+	client.subMtx.Lock()
+	if _, ok := eventSub.subs[sub]; !ok {
+		client.subMtx.Unlock()
+		return
+	}
+	delete(eventSub.subs, sub)
+	last := len(eventSub.subs) == 0
+	client.subMtx.Unlock()
+	close(sub.done)
+
+	// 구독 해제에 실패하면 기존 event subscription을 같은 query의 다음 Subscribe에서 그대로 사용함
+	if last {
+		if err := client.rpcClient.Unsubscribe(context.Background(), subscriberName, eventSub.query); err == nil {
+			client.subMtx.Lock()
+			delete(client.eventSubs, eventSub.query.String())
+			client.subMtx.Unlock()
+		}
+	}
+}
+
+// dispatchEvents는 tendermint에서 받은 put tx event를 decode하여 write된 데이터를 eventSub의 모든 subscription에 전달함.
+// keep-first나 재전송으로 write되지 않은 데이터는 DeliverTx response data의 index로 제외함.
+// 다른 subscription과 websocket 연결이 멈추지 않도록 buffer가 가득 찬 subscription은 기다리지 않고 구독을 해제함.
+// events는 구독 해제 시 tendermint rpc client가 닫음.
+func (client *HTTPClient) dispatchEvents(eventSub *eventSubscription, events <-chan interface{}) {
+	for event := range events {
+		txEvent, ok := event.(tmtypes.EventDataTx)
+		if !ok || txEvent.Result.IsErr() {
+			continue
+		}
+
+		baseDataObjs, err := writtenDataObjs(txEvent)
+		if err != nil || len(baseDataObjs) == 0 {
+			continue
+		}
+
+		client.subMtx.Lock()
+		for sub := range eventSub.subs {
+			select {
+			case sub.events <- baseDataObjs:
+			default:
+				sub.dropped = true
+				go client.unsubscribe(sub)
+			}
+		}
+		client.subMtx.Unlock()
+	}
+}
+
+// writtenDataObjs는 txEvent의 put tx에 담긴 데이터 중 write된 데이터를 tx 순서로 return.
+func writtenDataObjs(txEvent tmtypes.EventDataTx) ([]types.BaseDataObj, error) {
+	var baseDataObjs []types.BaseDataObj
+	if err := json.Unmarshal(txEvent.Tx, &baseDataObjs); err != nil {
+		return nil, err
+	}
+	if len(txEvent.Result.Data) == 0 {
+		return baseDataObjs, nil
+	}
+
+	var putResObj types.PutResObj
+	if err := json.Unmarshal(txEvent.Result.Data, &putResObj); err != nil {
+		return nil, err
+	}
+	skipped := make(map[int]bool)
+	for _, i := range putResObj.Skipped {
+		skipped[i] = true
+	}
+	var written []types.BaseDataObj
+	for i, baseDataObj := range baseDataObjs {
+		if !skipped[i] {
+			written = append(written, baseDataObj)
+		}
+	}
+	return written, nil
+}
+
+// run은 filter와 일치하는 데이터를 out으로 보내며 구독이 해제되면 구독이 끝난 이유를 기록하고 out을 닫음.
+func (sub *Subscription) run() {
+	defer func() {
+		// dispatchEvents는 subMtx를 잡고 dropped를 설정하며 unsubscribe는 subMtx를 잡은 뒤 done을 닫으므로 여기서 dropped를 read할 수 있음
+		if sub.dropped {
+			sub.err = ErrSubscriptionDropped
+		} else {
+			sub.err = sub.ctx.Err()
+		}
+		close(sub.closed)
+		close(sub.out)
+	}()
+
+	for {
+		select {
+		case baseDataObjs := <-sub.events:
+			for _, baseDataObj := range baseDataObjs {
+				if !sub.match(baseDataObj.MetaData) {
+					continue
+				}
+				select {
+				case sub.out <- toOutputDataObj(baseDataObj):
+				case <-sub.done:
+					return
+				}
+			}
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+// match는 metaDataObj가 filter의 OwnerId, Qualifier와 일치하는지 return. Query와 같이 Qualifier는 정확히 일치해야 함.
+func (sub *Subscription) match(metaDataObj types.MetaDataObj) bool {
+	if sub.filterObj.OwnerId != "" && metaDataObj.OwnerId != sub.filterObj.OwnerId {
+		return false
+	}
+	if sub.filterObj.Qualifier != "" && string(metaDataObj.Qualifier) != sub.filterObj.Qualifier {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
+	rpcClient "github.com/tendermint/tendermint/rpc/client"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEventsRPCClient는 Subscribe로 받은 channel 중 query가 tag와 일치하는 것에 event를 직접 보낼 수 있는 rpc client.
+type fakeEventsRPCClient struct {
+	rpcClient.Client
+
+	mtx              sync.Mutex
+	outs             map[string]chan<- interface{}
+	queries          map[string]tmpubsub.Query
+	subscribeCount   int
+	unsubscribeCount int
+}
+
+func (c *fakeEventsRPCClient) Start() error {
+	return nil
+}
+
+func (c *fakeEventsRPCClient) Subscribe(ctx context.Context, subscriber string, query tmpubsub.Query, out chan<- interface{}) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.outs == nil {
+		c.outs = make(map[string]chan<- interface{})
+		c.queries = make(map[string]tmpubsub.Query)
+	}
+	if _, ok := c.outs[query.String()]; ok {
+		return errors.New("already subscribed")
+	}
+	c.outs[query.String()] = out
+	c.queries[query.String()] = query
+	c.subscribeCount++
+	return nil
+}
+
+func (c *fakeEventsRPCClient) Unsubscribe(ctx context.Context, subscriber string, query tmpubsub.Query) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	close(c.outs[query.String()])
+	delete(c.outs, query.String())
+	c.unsubscribeCount++
+	return nil
+}
+
+// publish는 master가 DeliverTx에 담는 것과 같은 tag로 baseDataObjs의 put tx event를 보냄.
+func (c *fakeEventsRPCClient) publish(baseDataObjs []types.BaseDataObj) error {
+	return c.publishResult(baseDataObjs, nil)
+}
+
+// publishResult는 resData를 DeliverTx response data로 담아 baseDataObjs의 put tx event를 보냄.
+func (c *fakeEventsRPCClient) publishResult(baseDataObjs []types.BaseDataObj, resData []byte) error {
+	tx, err := json.Marshal(baseDataObjs)
+	if err != nil {
+		return err
+	}
+	tags := map[string]string{tmtypes.EventTypeKey: tmtypes.EventTx, consts.ActionTagKey: consts.PutAction}
+	ownerIds := consts.TagListSeparator
+	for _, baseDataObj := range baseDataObjs {
+		ownerIds += baseDataObj.MetaData.OwnerId + consts.TagListSeparator
+	}
+	tags[consts.OwnerIdsTagKey] = ownerIds
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for q, out := range c.outs {
+		if c.queries[q].Matches(tmpubsub.NewTagMap(tags)) {
+			out <- tmtypes.EventDataTx{TxResult: tmtypes.TxResult{Height: 1, Tx: tx, Result: abciTypes.ResponseDeliverTx{Code: code.CodeTypeOK, Data: resData}}}
+		}
+	}
+	return nil
+}
+
+func (c *fakeEventsRPCClient) counts() (int, int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.subscribeCount, c.unsubscribeCount
+}
+
+func receive(ch <-chan OutputDataObj, n int) ([]OutputDataObj, bool) {
+	var objs []OutputDataObj
+	for len(objs) < n {
+		select {
+		case obj, ok := <-ch:
+			if !ok {
+				return objs, false
+			}
+			objs = append(objs, obj)
+		case <-time.After(time.Second):
+			return objs, true
+		}
+	}
+	return objs, true
+}
+
+func TestHTTPClient_Subscribe(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	rpc := &fakeEventsRPCClient{}
+	client := &HTTPClient{rpcClient: rpc}
+	ownerCtx, cancelOwner := context.WithCancel(context.Background())
+	allCtx, cancelAll := context.WithCancel(context.Background())
+	defer cancelAll()
+
+	ownerSub, err := client.Subscribe(ownerCtx, InputSubscribeObj{OwnerId: TestOwnerId})
+	require.Nil(err)
+	allSub, err := client.Subscribe(allCtx, InputSubscribeObj{})
+	require.Nil(err)
+	ownerCh, allCh := ownerSub.Out(), allSub.Out()
+
+	rowKey1 := types.GetRowKey(1547772882435375000, 0)
+	rowKey2 := types.GetRowKey(1547772882435375001, 0)
+	givenBaseDataObjs := []types.BaseDataObj{
+		{MetaData: types.MetaDataObj{RowKey: rowKey1, OwnerId: TestOwnerId, Qualifier: []byte(TestQualifier)}, RealData: types.RealDataObj{RowKey: rowKey1, Data: []byte("data1")}},
+		{MetaData: types.MetaDataObj{RowKey: rowKey2, OwnerId: "otherOwner", Qualifier: []byte(TestQualifier)}, RealData: types.RealDataObj{RowKey: rowKey2, Data: []byte("data2")}},
+	}
+
+	//when
+	require.Nil(rpc.publish(givenBaseDataObjs))
+
+	//then
+	ownerObjs, _ := receive(ownerCh, 1)
+	require.Equal([]OutputDataObj{{Id: rowKey1, Timestamp: 1547772882435375000, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("data1")}}, ownerObjs)
+	allObjs, _ := receive(allCh, 2)
+	require.Equal(2, len(allObjs))
+
+	// filter의 ownerId는 event query 조건으로 사용하므로 다른 owner만 담은 tx는 전달받지 않음
+	require.Nil(rpc.publish(givenBaseDataObjs[1:]))
+	allObjs, _ = receive(allCh, 1)
+	require.Equal(1, len(allObjs))
+	ownerObjs, _ = receive(ownerCh, 1)
+	require.Equal(0, len(ownerObjs))
+
+	// 같은 filter의 Subscribe는 하나의 event subscription을 공유하며 마지막 구독이 해제될 때 event 구독을 해제함
+	otherOwnerCtx, cancelOtherOwner := context.WithCancel(context.Background())
+	defer cancelOtherOwner()
+	_, err = client.Subscribe(otherOwnerCtx, InputSubscribeObj{OwnerId: TestOwnerId})
+	require.Nil(err)
+	cancelOwner()
+	_, open := receive(ownerCh, 1)
+	require.False(open)
+	require.Equal(context.Canceled, ownerSub.Err())
+	subscribeCount, unsubscribeCount := rpc.counts()
+	require.Equal(2, subscribeCount)
+	require.Equal(0, unsubscribeCount)
+
+	cancelOtherOwner()
+	cancelAll()
+	_, open = receive(allCh, 1)
+	require.False(open)
+	// channel은 event 구독 해제 전에 닫히므로 잠시 기다림
+	time.Sleep(50 * time.Millisecond)
+	_, unsubscribeCount = rpc.counts()
+	require.Equal(2, unsubscribeCount)
+}
+
+func TestHTTPClient_Subscribe_slowSubscriber(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	rpc := &fakeEventsRPCClient{}
+	client := &HTTPClient{rpcClient: rpc}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slowSub, err := client.Subscribe(ctx, InputSubscribeObj{})
+	require.Nil(err)
+	require.Nil(slowSub.Err())
+
+	//when
+	// slowCh를 읽지 않는 동안 buffer보다 많은 tx가 commit됨
+	count := 3 * DefaultSubscriptionBufferSize
+	for i := 0; i < count; i++ {
+		rowKey := types.GetRowKey(uint64(1547772882435375000+i), 0)
+		require.Nil(rpc.publish([]types.BaseDataObj{{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId}, RealData: types.RealDataObj{RowKey: rowKey, Data: []byte("data")}}}))
+	}
+
+	//then
+	// event 전달은 block되지 않으며 buffer가 가득 찬 subscription은 해제되어 channel이 닫힘
+	objs, open := receive(slowSub.Out(), count)
+	require.False(open)
+	require.True(len(objs) < count)
+	// ctx 만료로 인한 해제와 구분할 수 있음
+	require.Equal(ErrSubscriptionDropped, slowSub.Err())
+}
+
+func TestHTTPClient_Subscribe_skipped(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	rpc := &fakeEventsRPCClient{}
+	client := &HTTPClient{rpcClient: rpc}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := client.Subscribe(ctx, InputSubscribeObj{})
+	require.Nil(err)
+
+	var givenBaseDataObjs []types.BaseDataObj
+	for i := 0; i < 3; i++ {
+		rowKey := types.GetRowKey(uint64(1547772882435375000+i), 0)
+		givenBaseDataObjs = append(givenBaseDataObjs, types.BaseDataObj{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId}, RealData: types.RealDataObj{RowKey: rowKey, Data: []byte("data")}})
+	}
+	resData, err := json.Marshal(types.PutResObj{Skipped: []int{0, 2}})
+	require.Nil(err)
+
+	//when
+	// 첫 번째와 세 번째 데이터는 keep-first나 재전송으로 write되지 않음
+	require.Nil(rpc.publishResult(givenBaseDataObjs, resData))
+
+	//then
+	// write된 데이터만 전달됨
+	objs, open := receive(sub.Out(), 2)
+	require.True(open)
+	require.Equal(1, len(objs))
+	require.Equal([]byte(givenBaseDataObjs[1].MetaData.RowKey), objs[0].Id)
+}
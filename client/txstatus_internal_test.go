@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient_PutSync_PutAsync(t *testing.T) {
+	require := require.New(t)
+	dataObjs := []InputDataObj{{Timestamp: 1547772882435375000, OwnerId: TestOwnerId, Qualifier: TestQualifier, Data: []byte("testData")}}
+
+	/*
+		PutSync
+	*/
+	//given
+	rpc := &fakeRPCClient{broadcastErrs: []error{io.EOF}}
+	client := newFakeHTTPClient(rpc)
+
+	//when
+	res, err := client.PutSync(context.Background(), dataObjs)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(2, len(rpc.broadcastTxs))
+	require.Equal(rpc.broadcastTxs[0].Hash(), []byte(res.Hash))
+
+	/*
+		이전 시도의 tx가 이미 mempool cache에 있으면 성공
+	*/
+	//given
+	rpc = &fakeRPCClient{broadcastErrs: []error{io.EOF, errors.New("Response error: " + txInCacheErrMsg)}}
+	client = newFakeHTTPClient(rpc)
+
+	//when
+	res, err = client.PutAsync(context.Background(), dataObjs)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(2, len(rpc.broadcastTxs))
+	require.Equal(rpc.broadcastTxs[0].Hash(), []byte(res.Hash))
+}
+
+func TestHTTPClient_TxStatus(t *testing.T) {
+	require := require.New(t)
+	hash := []byte("testHash")
+
+	/*
+		commit 전
+	*/
+	//given
+	rpc := &fakeRPCClient{txErrs: []error{errors.Errorf("Response error: Tx (%X) not found", hash)}}
+	client := newFakeHTTPClient(rpc)
+
+	//when
+	status, err := client.TxStatus(context.Background(), hash)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.False(status.Committed)
+
+	/*
+		commit 후
+	*/
+	//when
+	status, err = client.TxStatus(context.Background(), hash)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.True(status.Committed)
+	require.Equal(int64(2), status.Height)
+}
+
+func TestHTTPClient_WaitForCommit(t *testing.T) {
+	require := require.New(t)
+	hash := []byte("testHash")
+	notFoundErr := errors.Errorf("Response error: Tx (%X) not found", hash)
+
+	/*
+		commit될 때까지 대기
+	*/
+	//given
+	rpc := &fakeRPCClient{txErrs: []error{notFoundErr, notFoundErr}}
+	client := newFakeHTTPClient(rpc)
+
+	//when
+	status, err := client.WaitForCommit(context.Background(), hash)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.True(status.Committed)
+	require.Equal(0, len(rpc.txErrs))
+
+	/*
+		ctx 만료
+	*/
+	//given
+	rpc = &fakeRPCClient{txErrs: []error{notFoundErr, notFoundErr, notFoundErr, notFoundErr}}
+	client = newFakeHTTPClient(rpc)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	//when
+	status, err = client.WaitForCommit(ctx, hash)
+
+	//then
+	require.NotNil(err)
+	require.Equal(context.DeadlineExceeded, errors.Cause(err))
+	require.False(status.Committed)
+}
+
+func TestHTTPClient_FindTxs(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	rowKey := types.GetRowKey(1547772882435375000, 1)
+	otherRowKey := types.GetRowKey(1547772882435375000, 2)
+	givenTx := func(rowKey []byte) tmtypes.Tx {
+		tx, err := json.Marshal([]types.BaseDataObj{{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId}, RealData: types.RealDataObj{RowKey: rowKey}}})
+		require.Nil(err)
+		return tx
+	}
+	// 두 page에 걸쳐 있는 tx 중 첫 번째와 마지막 tx만 rowKey를 담고 있음
+	rpc := &fakeRPCClient{}
+	for i := 0; i <= txSearchPerPage; i++ {
+		tx := givenTx(otherRowKey)
+		if i == 0 || i == txSearchPerPage {
+			tx = givenTx(rowKey)
+		}
+		rpc.searchTxs = append(rpc.searchTxs, &ctypes.ResultTx{Hash: tx.Hash(), Height: int64(i + 1), Tx: tx})
+	}
+	client := newFakeHTTPClient(rpc)
+
+	//when
+	txs, err := client.FindTxs(context.Background(), rowKey)
+
+	//then
+	require.Nil(err, "err: %+v", err)
+	require.Equal(2, len(txs))
+	require.Equal(int64(1), txs[0].Height)
+	require.Equal(int64(txSearchPerPage+1), txs[1].Height)
+
+	// rowKey 길이가 다르면 error
+	_, err = client.FindTxs(context.Background(), []byte("wrong"))
+	require.NotNil(err)
+}
@@ -1,31 +1,99 @@
 package client
 
+import (
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	"time"
+)
+
 // InputDataObj는 Put function의 write model.
 // Timestamp는 unix timestamp이며 단위는 nano second임.
 // OwnerId는 data owner id이며 64자리 미만 string
 // Qualifier는 json object이며 string.
+// Id는 overwrite할 데이터의 id. 비어 있으면 내용으로 새 id를 만들며 Timestamp가 0이면 Id의 timestamp를 사용함.
+// Conflict는 같은 id의 데이터가 이미 있을 때의 처리 방법(consts.ConflictReject | consts.ConflictOverwrite | consts.ConflictKeepFirst).
+// 비어 있으면 consts.ConflictReject로 처리됨.
 type InputDataObj struct {
 	Timestamp uint64 `json:"timestamp"`
 	OwnerId   string `json:"ownerId"`
 	Qualifier string `json:"qualifier"`
 	Data      []byte `json:"data"`
+	Id        []byte `json:"id,omitempty"`
+	Conflict  string `json:"conflict,omitempty"`
 }
 
 // InputQueryObj는 Query function의 read model.
 // Start, End는 unix timestamp이며 단위는 nano second임.
 // OwnerId는 data owner id이며 64자리 미만 string. OwnerId를 제한하고 싶지 않다면 empty string을 넣음.
 // Qualifier는 json object이며 string. Qualifier를 제한하고 싶지 않다면 empty string을 넣음.
+// OwnerIds, Qualifiers는 여러 값 중 하나와 일치하는 데이터를 read할 때 사용하며 OwnerId, Qualifier와 합쳐서 검사함.
+// OwnerIdPrefix가 주어지면 ownerId가 그 prefix로 시작하는 데이터만 read함.
+// Height는 read할 상태의 block height. 0이면 마지막으로 commit된 height의 상태를 read함.
 type InputQueryObj struct {
+	Start         uint64   `json:"start"`
+	End           uint64   `json:"end"`
+	OwnerId       string   `json:"ownerId"`
+	Qualifier     string   `json:"qualifier"`
+	OwnerIds      []string `json:"ownerIds,omitempty"`
+	Qualifiers    []string `json:"qualifiers,omitempty"`
+	OwnerIdPrefix string   `json:"ownerIdPrefix,omitempty"`
+	Height        int64    `json:"height,omitempty"`
+}
+
+// InputFetchObj는 Fetch function의 read model.
+// Id는 data의 고유한 id.
+// Height는 read할 상태의 block height. 0이면 마지막으로 commit된 height의 상태를 read함.
+type InputFetchObj struct {
+	Ids    [][]byte `json:"ids"`
+	Height int64    `json:"height,omitempty"`
+}
+
+// InputSubscribeObj는 Subscribe function의 filter.
+// OwnerId, Qualifier를 제한하고 싶지 않다면 empty string을 넣음.
+type InputSubscribeObj struct {
+	OwnerId   string `json:"ownerId"`
+	Qualifier string `json:"qualifier"`
+}
+
+// InputSeriesObj는 Series function의 read model.
+// OwnerId, Qualifier를 제한하고 싶지 않다면 empty string을 넣음.
+// Height는 read할 상태의 block height. 0이면 마지막으로 commit된 height의 상태를 read함.
+type InputSeriesObj struct {
+	OwnerId   string `json:"ownerId"`
+	Qualifier string `json:"qualifier"`
+	Height    int64  `json:"height,omitempty"`
+}
+
+// InputLatestObj는 Latest function의 read model.
+// OwnerIds, Qualifiers 중 하나와 일치하는 series마다 가장 최신 데이터를 read하며 비어 있으면 모든 값과 일치함.
+// Height는 read할 상태의 block height. 0이면 마지막으로 commit된 height의 상태를 read함.
+type InputLatestObj struct {
+	OwnerIds   []string `json:"ownerIds"`
+	Qualifiers []string `json:"qualifiers"`
+	Height     int64    `json:"height,omitempty"`
+}
+
+// InputRangeObj는 Range function의 read model.
+// Start, End는 unix timestamp이며 단위는 nano second임. Start 이상 End 미만의 데이터를 read함.
+// OwnerId, Qualifier를 제한하고 싶지 않다면 empty string을 넣음.
+// Step이 0보다 크면 Start부터 Step nano second 간격의 bucket으로 집계하며 0이면 point를 그대로 read함.
+// Height는 read할 상태의 block height. 0이면 마지막으로 commit된 height의 상태를 read함.
+type InputRangeObj struct {
 	Start     uint64 `json:"start"`
 	End       uint64 `json:"end"`
 	OwnerId   string `json:"ownerId"`
 	Qualifier string `json:"qualifier"`
+	Step      uint64 `json:"step,omitempty"`
+	Height    int64  `json:"height,omitempty"`
 }
 
-// InputFetchObj는 Fetch function의 read model.
-// Id는 data의 고유한 id.
-type InputFetchObj struct {
-	Ids [][]byte `json:"ids"`
+// InputQLObj는 QL function의 read model.
+// Query는 SELECT <집계 함수 혹은 *> FROM <조건> WHERE <조건> [GROUP BY time(<간격>), owner] [LIMIT <n>] 형태의 query이며
+// WHERE에 time range를 반드시 주어야 함.
+// Height는 read할 상태의 block height. 0이면 마지막으로 commit된 height의 상태를 read함.
+type InputQLObj struct {
+	Query  string `json:"query"`
+	Height int64  `json:"height,omitempty"`
 }
 
 // OutputQueryObj는 Query function의 result data type.
@@ -48,3 +116,176 @@ type OutputFetchObj struct {
 	Timestamp uint64 `json:"timestamp"`
 	Data      []byte `json:"data"`
 }
+
+// OutputDataObj는 QueryData function의 result data type.
+// Id는 data의 고유한 id.
+// Timestamp는 unix timestamp이며 단위는 nano second임.
+// OwnerId는 data owner id이며 64자리 미만 string
+// Qualifier는 json object이며 string.
+type OutputDataObj struct {
+	Id        []byte `json:"id"`
+	Timestamp uint64 `json:"timestamp"`
+	OwnerId   string `json:"ownerId"`
+	Qualifier string `json:"qualifier"`
+	Data      []byte `json:"data"`
+}
+
+// OutputHistoryObj는 FetchHistory function의 result data type.
+// Id, Timestamp, OwnerId, Qualifier, Data는 overwrite로 대체되기 전의 데이터이며 Height는 데이터가 대체된 block height임.
+type OutputHistoryObj struct {
+	Id        []byte `json:"id"`
+	Timestamp uint64 `json:"timestamp"`
+	OwnerId   string `json:"ownerId"`
+	Qualifier string `json:"qualifier"`
+	Data      []byte `json:"data"`
+	Height    int64  `json:"height"`
+}
+
+// OutputSeriesObj는 Series function의 result data type.
+// Id는 series가 처음 write될 때 부여된 고유한 id이며 OwnerId, Qualifier는 series의 데이터가 공유하는 값임.
+type OutputSeriesObj struct {
+	Id        uint64 `json:"id"`
+	OwnerId   string `json:"ownerId"`
+	Qualifier string `json:"qualifier"`
+}
+
+// OutputPointObj는 Range function이 read한 숫자 데이터 하나.
+type OutputPointObj struct {
+	Timestamp uint64  `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// OutputBucketObj는 Range function이 Start부터 Start+Step 미만의 숫자 데이터를 집계한 결과.
+type OutputBucketObj struct {
+	Start uint64  `json:"start"`
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+}
+
+// OutputRangeObj는 Range function의 series 하나의 result data type.
+// Step이 0이면 Points가, 0보다 크면 Buckets가 채워짐.
+// Resolution은 server가 Buckets 집계에 사용한 rollup의 resolution(nano second)이며 0이면 원본 데이터로 집계함.
+type OutputRangeObj struct {
+	Series     OutputSeriesObj   `json:"series"`
+	Resolution uint64            `json:"resolution,omitempty"`
+	Points     []OutputPointObj  `json:"points,omitempty"`
+	Buckets    []OutputBucketObj `json:"buckets,omitempty"`
+}
+
+// OutputQLGroupObj는 QL function의 집계 group 하나.
+// Time은 group의 시작 시각이며 OwnerId는 GROUP BY owner일 때만 채워짐. Values는 ResultQL의 Columns 순서의 집계 value임.
+type OutputQLGroupObj struct {
+	Time    uint64    `json:"time"`
+	OwnerId string    `json:"ownerId,omitempty"`
+	Values  []float64 `json:"values"`
+}
+
+// ResultQuery는 QueryDecoded function의 result data type.
+// Height는 query가 수행된 block height이며 Proof는 server가 proof를 제공하는 경우에만 채워짐.
+// Data는 read한 데이터의 metadata임.
+type ResultQuery struct {
+	Height int64            `json:"height"`
+	Proof  *merkle.Proof    `json:"proof,omitempty"`
+	Data   []OutputQueryObj `json:"data"`
+}
+
+// ResultFetch는 FetchDecoded function의 result data type.
+// Height는 fetch가 수행된 block height이며 Proof는 server가 proof를 제공하는 경우에만 채워짐.
+// Data는 read한 실제 데이터임.
+type ResultFetch struct {
+	Height int64            `json:"height"`
+	Proof  *merkle.Proof    `json:"proof,omitempty"`
+	Data   []OutputFetchObj `json:"data"`
+}
+
+// ResultQueryData는 QueryData function의 result data type.
+// Height는 query가 수행된 block height이며 Proof는 server가 proof를 제공하는 경우에만 채워짐.
+// Data는 read한 데이터의 metadata와 실제 데이터임.
+type ResultQueryData struct {
+	Height int64           `json:"height"`
+	Proof  *merkle.Proof   `json:"proof,omitempty"`
+	Data   []OutputDataObj `json:"data"`
+}
+
+// ResultHistory는 FetchHistory function의 result data type.
+// Height는 read가 수행된 block height이며 Data는 id마다 대체된 height 순서로 정렬된 이전 데이터임.
+// history는 app hash에 포함되지 않으므로 proof를 제공하지 않음.
+type ResultHistory struct {
+	Height int64              `json:"height"`
+	Data   []OutputHistoryObj `json:"data"`
+}
+
+// ResultSeries는 Series function의 result data type.
+// Height는 read가 수행된 block height이며 Data는 id 순서로 정렬된 series임.
+type ResultSeries struct {
+	Height int64             `json:"height"`
+	Data   []OutputSeriesObj `json:"data"`
+}
+
+// ResultCount는 Count function의 result data type.
+// Height는 read가 수행된 block height이며 Count는 조건에 맞는 데이터 수,
+// Series, Owners, Qualifiers는 그 데이터의 서로 다른 series, ownerId, qualifier 수.
+type ResultCount struct {
+	Height     int64  `json:"height"`
+	Count      uint64 `json:"count"`
+	Series     uint64 `json:"series"`
+	Owners     uint64 `json:"owners"`
+	Qualifiers uint64 `json:"qualifiers"`
+}
+
+// ResultLatest는 Latest function의 result data type.
+// Height는 read가 수행된 block height이며 Proof는 server가 proof를 제공하는 경우에만 채워짐.
+// Data는 series id 순서로 정렬된 series마다 가장 최신 데이터의 metadata와 실제 데이터임.
+type ResultLatest struct {
+	Height int64           `json:"height"`
+	Proof  *merkle.Proof   `json:"proof,omitempty"`
+	Data   []OutputDataObj `json:"data"`
+}
+
+// ResultQL은 QL function의 result data type.
+// Height는 read가 수행된 block height이며 Proof는 server가 proof를 제공하는 경우에만 채워짐.
+// SELECT * query는 Data를, 집계 query는 Columns와 Groups를 채움. 집계 결과는 app hash에 포함되지 않으므로 proof를 제공하지 않음.
+type ResultQL struct {
+	Height  int64              `json:"height"`
+	Proof   *merkle.Proof      `json:"proof,omitempty"`
+	Columns []string           `json:"columns,omitempty"`
+	Groups  []OutputQLGroupObj `json:"groups,omitempty"`
+	Data    []OutputDataObj    `json:"data,omitempty"`
+}
+
+// ResultRange는 Range function의 result data type.
+// Height는 read가 수행된 block height이며 Data는 id 순서로 정렬된 series마다 read한 숫자 데이터임.
+// chunk는 app hash에 포함되지 않으므로 proof를 제공하지 않음.
+type ResultRange struct {
+	Height int64            `json:"height"`
+	Data   []OutputRangeObj `json:"data"`
+}
+
+// MasterStatus는 master의 /readyz response model.
+// DBOpen, DBWritable은 rocksdb의 open, write 가능 여부.
+// LastHeight, LastBlockTime은 마지막으로 commit된 block의 height와 commit 시각이며 SinceLastBlock은 그 이후 경과 시간.
+// Handshaked는 master가 시작된 뒤 tendermint와 ABCI handshake를 했는지 여부이며 Ready는 위 항목들을 종합한 readiness.
+type MasterStatus struct {
+	DBOpen         bool      `json:"dbOpen"`
+	DBWritable     bool      `json:"dbWritable"`
+	LastHeight     int64     `json:"lastHeight"`
+	LastBlockTime  time.Time `json:"lastBlockTime"`
+	SinceLastBlock string    `json:"sinceLastBlock"`
+	Handshaked     bool      `json:"handshaked"`
+	Ready          bool      `json:"ready"`
+}
+
+// TxStatus는 TxStatus, WaitForCommit function의 result data type.
+// Hash는 PutSync, PutAsync가 return한 tx hash.
+// Committed가 false이면 tx가 아직 block에 포함되지 않았거나 mempool에서 제외된 것임.
+// Height, DeliverTx는 tx가 포함된 block의 height와 DeliverTx 결과이며 Committed가 true일 때만 유효함.
+type TxStatus struct {
+	Hash      []byte                      `json:"hash"`
+	Committed bool                        `json:"committed"`
+	Height    int64                       `json:"height"`
+	DeliverTx abciTypes.ResponseDeliverTx `json:"deliverTx"`
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"github.com/paust-team/paust-db/master"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+	"net/http"
+)
+
+// newHTTPHandler는 master HTTP server의 handler를 생성함.
+// /healthz, /readyz는 항상 제공하고 Prometheus가 켜져 있으면 /metrics를 제공함.
+func newHTTPHandler(app *master.MasterApplication) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", app.HealthzHandler())
+	mux.Handle("/readyz", app.ReadyzHandler())
+	if conf.Instrumentation.Prometheus {
+		mux.Handle("/metrics", promhttp.Handler())
+	}
+
+	return mux
+}
+
+// startHTTPServer는 addr에서 handler를 제공하는 HTTP server를 background로 시작함.
+func startHTTPServer(addr string, handler http.Handler, logger tmlog.Logger) *http.Server {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			logger.Error("HTTP server ListenAndServe", "err", err)
+		}
+	}()
+
+	return srv
+}
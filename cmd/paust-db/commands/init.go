@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/paust-team/paust-db/config"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	tmcfg "github.com/tendermint/tendermint/config"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+	"os"
+)
+
+func initFiles() error {
+	configFile := conf.ConfigFile()
+	if _, err := os.Stat(configFile); err == nil {
+		fmt.Printf("Found config file: %s\n", configFile)
+	} else {
+		if err := config.WriteConfigFile(configFile, conf); err != nil {
+			return err
+		}
+		fmt.Printf("Generated config file: %s\n", configFile)
+	}
+
+	return initTendermintFiles()
+}
+
+// initTendermintFiles는 node command에서 사용할 tendermint config, private validator, node key, genesis file을 생성함.
+func initTendermintFiles() error {
+	// EnsureRoot는 tendermint config file이 없을 경우 기본값으로 생성함
+	tmcfg.EnsureRoot(conf.RootDir)
+	tmConfig, err := ParseTendermintConfig(conf.RootDir)
+	if err != nil {
+		return err
+	}
+
+	privValKeyFile := tmConfig.PrivValidatorKeyFile()
+	privValStateFile := tmConfig.PrivValidatorStateFile()
+	var pv *privval.FilePV
+	if cmn.FileExists(privValKeyFile) {
+		pv = privval.LoadFilePV(privValKeyFile, privValStateFile)
+		fmt.Printf("Found private validator: %s\n", privValKeyFile)
+	} else {
+		pv = privval.GenFilePV(privValKeyFile, privValStateFile)
+		pv.Save()
+		fmt.Printf("Generated private validator: %s\n", privValKeyFile)
+	}
+
+	nodeKeyFile := tmConfig.NodeKeyFile()
+	if cmn.FileExists(nodeKeyFile) {
+		fmt.Printf("Found node key: %s\n", nodeKeyFile)
+	} else {
+		if _, err := p2p.LoadOrGenNodeKey(nodeKeyFile); err != nil {
+			return errors.Wrap(err, "generate node key err")
+		}
+		fmt.Printf("Generated node key: %s\n", nodeKeyFile)
+	}
+
+	genFile := tmConfig.GenesisFile()
+	if cmn.FileExists(genFile) {
+		fmt.Printf("Found genesis file: %s\n", genFile)
+	} else {
+		genDoc := types.GenesisDoc{
+			ChainID:         fmt.Sprintf("paust-db-chain-%v", cmn.RandStr(6)),
+			GenesisTime:     tmtime.Now(),
+			ConsensusParams: types.DefaultConsensusParams(),
+		}
+		key := pv.GetPubKey()
+		genDoc.Validators = []types.GenesisValidator{{
+			Address: key.Address(),
+			PubKey:  key,
+			Power:   10,
+		}}
+
+		if err := genDoc.SaveAs(genFile); err != nil {
+			return errors.Wrap(err, "save genesis file err")
+		}
+		fmt.Printf("Generated genesis file: %s\n", genFile)
+	}
+
+	return nil
+}
+
+var InitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write default config files of Paust DB and the embedded tendermint node",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := initFiles(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
@@ -1,8 +1,8 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"github.com/paust-team/paust-db/consts"
 	"github.com/paust-team/paust-db/libs/log"
 	"github.com/paust-team/paust-db/master"
 	"github.com/pkg/errors"
@@ -13,33 +13,42 @@ import (
 	"os"
 )
 
-var dir, level string
-
 func Serve() error {
-	option, err := log.AllowLevel(level)
+	option, err := log.AllowLevel(conf.LogLevel)
 	if err != nil {
 		return errors.Wrap(err, "level parsing err")
 	}
 
-	app, err := master.NewMasterApplication(true, dir, option)
+	app, err := master.NewMasterApplicationWithConfig(conf, option)
 	if err != nil {
 		return errors.Wrap(err, "NewMasterApplication err")
 	}
 	logger := tmlog.NewTMLogger(tmlog.NewSyncWriter(os.Stdout))
 
-	srv, err := server.NewServer(consts.ProtoAddr, consts.Transport, app)
+	srv, err := server.NewServer(conf.ProtoAddr, conf.Transport, app)
 
 	if err != nil {
 		return err
 	}
 
+	httpSrv := startHTTPServer(conf.Instrumentation.ListenAddr, newHTTPHandler(app), logger.With("module", "http-server"))
+
 	srv.SetLogger(logger.With("module", "abci-server"))
 	if err := srv.Start(); err != nil {
 		return err
 	}
 
+	// ABCI server를 먼저 멈춰 새 block이 시작되지 않도록 한 뒤 application을 shutdown(처리 중인 요청 대기, DB close)함.
+	// commit되지 않은 block은 재시작 시 tendermint handshake에서 다시 replay됨.
 	common.TrapSignal(func() {
+		httpSrv.Close()
 		srv.Stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+		defer cancel()
+		if err := app.Shutdown(ctx); err != nil {
+			logger.Error("Error shutting down master application", "err", err)
+		}
 	})
 
 	return nil
@@ -58,6 +67,8 @@ var MasterCmd = &cobra.Command{
 }
 
 func init() {
-	MasterCmd.Flags().StringVarP(&dir, "dir", "d", os.ExpandEnv("$HOME/.paust-db"), "directory for data store")
-	MasterCmd.Flags().StringVarP(&level, "level", "l", "info", "set log level [debug|info|error|none]")
+	MasterCmd.Flags().String("proto_addr", "", "address for the ABCI server to listen on (default from config)")
+	MasterCmd.Flags().String("transport", "", "ABCI transport [socket|grpc] (default from config)")
+	MasterCmd.Flags().StringP("dir", "d", "", "directory for data store (default from config)")
+	MasterCmd.Flags().StringP("level", "l", "", "set log level [debug|info|error|none] (default from config)")
 }
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/log"
+	"github.com/paust-team/paust-db/master"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	tmcfg "github.com/tendermint/tendermint/config"
+	tmflags "github.com/tendermint/tendermint/libs/cli/flags"
+	"github.com/tendermint/tendermint/libs/common"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+	nm "github.com/tendermint/tendermint/node"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/proxy"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseTendermintConfig는 home directory의 tendermint config file(config/config.toml)을 read하여 tendermint Config를 return.
+// paust-db config와 key가 겹치지 않도록 별도의 viper instance를 사용함.
+func ParseTendermintConfig(home string) (*tmcfg.Config, error) {
+	tmConfig := tmcfg.DefaultConfig().SetRoot(home)
+	configFile := filepath.Join(home, "config", "config.toml")
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if _, err := os.Stat(configFile); err == nil {
+		if err := v.ReadInConfig(); err != nil {
+			return nil, errors.Wrap(err, "read tendermint config file err")
+		}
+		if err := v.Unmarshal(tmConfig); err != nil {
+			return nil, errors.Wrap(err, "unmarshal tendermint config err")
+		}
+	}
+	tmConfig.SetRoot(home)
+	// index할 tag가 지정되지 않았으면 tx_search로 put tx를 찾을 수 있도록 paust-db tag를 index함
+	if tmConfig.TxIndex.IndexTags == "" && !tmConfig.TxIndex.IndexAllTags {
+		tmConfig.TxIndex.IndexTags = strings.Join([]string{consts.ActionTagKey, consts.OwnerIdTagKey, consts.QualifierTypeTagKey, consts.MinTimestampTagKey, consts.MaxTimestampTagKey}, ",")
+	}
+	if err := tmConfig.ValidateBasic(); err != nil {
+		return nil, errors.Wrap(err, "error in tendermint config file")
+	}
+
+	return tmConfig, nil
+}
+
+// RunNode는 tendermint node를 같은 process 안에서 실행하고 local ABCI client로 MasterApplication과 연결함.
+func RunNode() error {
+	option, err := log.AllowLevel(conf.LogLevel)
+	if err != nil {
+		return errors.Wrap(err, "level parsing err")
+	}
+
+	tmConfig, err := ParseTendermintConfig(conf.RootDir)
+	if err != nil {
+		return err
+	}
+	logger, err := tmflags.ParseLogLevel(tmConfig.LogLevel, tmlog.NewTMLogger(tmlog.NewSyncWriter(os.Stdout)), tmcfg.DefaultLogLevel())
+	if err != nil {
+		return errors.Wrap(err, "tendermint log level parsing err")
+	}
+
+	app, err := master.NewMasterApplicationWithConfig(conf, option)
+	if err != nil {
+		return errors.Wrap(err, "NewMasterApplication err")
+	}
+
+	nodeKey, err := p2p.LoadOrGenNodeKey(tmConfig.NodeKeyFile())
+	if err != nil {
+		return errors.Wrap(err, "load node key err")
+	}
+
+	n, err := nm.NewNode(tmConfig,
+		privval.LoadOrGenFilePV(tmConfig.PrivValidatorKeyFile(), tmConfig.PrivValidatorStateFile()),
+		nodeKey,
+		proxy.NewLocalClientCreator(app),
+		nm.DefaultGenesisDocProviderFunc(tmConfig),
+		nm.DefaultDBProvider,
+		nm.DefaultMetricsProvider(tmConfig.Instrumentation),
+		logger.With("module", "node"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "NewNode err")
+	}
+
+	if err := n.Start(); err != nil {
+		return errors.Wrap(err, "node start err")
+	}
+	logger.Info("Started node", "nodeInfo", n.Switch().NodeInfo())
+
+	httpSrv := startHTTPServer(conf.Instrumentation.ListenAddr, newHTTPHandler(app), logger.With("module", "http-server"))
+
+	// node를 먼저 멈춰 새 block이 시작되지 않도록 한 뒤 DB를 close함. commit되지 않은 block은 재시작 시 replay됨.
+	common.TrapSignal(func() {
+		httpSrv.Close()
+		if n.IsRunning() {
+			n.Stop()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+		defer cancel()
+		if err := app.Shutdown(ctx); err != nil {
+			logger.Error("Error shutting down master application", "err", err)
+		}
+	})
+
+	return nil
+}
+
+var NodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Run Paust DB Master Application with an embedded tendermint node",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := RunNode()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	NodeCmd.Flags().StringP("dir", "d", "", "directory for data store (default from config)")
+	NodeCmd.Flags().StringP("level", "l", "", "set log level [debug|info|error|none] (default from config)")
+}
@@ -1,21 +1,94 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/paust-team/paust-db/config"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"os"
+	"strings"
 )
 
+// 실행 중인 command의 설정. PersistentPreRunE에서 채워짐.
+var conf = config.DefaultConfig()
+
+// config key와 이름이 다른 flag들. key는 flag 이름, value는 config key.
+var flagAliases = map[string]string{
+	"dir":   "db_dir",
+	"level": "log_level",
+}
+
+// ParseConfig는 기본 설정 위에 config file, 환경 변수(PAUSTDB_*), command flag 순서로 값을 덮어쓴 Config를 return.
+// 호출마다 새 viper instance를 사용하므로 이전 호출의 flag binding, 설정값이 남지 않음.
+func ParseConfig(cmd *cobra.Command) (*config.Config, error) {
+	v := viper.New()
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		return nil, errors.Wrap(err, "bind flags err")
+	}
+	for name, key := range flagAliases {
+		if flag := cmd.Flags().Lookup(name); flag != nil {
+			if err := v.BindPFlag(key, flag); err != nil {
+				return nil, errors.Wrap(err, "bind flag err")
+			}
+		}
+	}
+	v.SetEnvPrefix(config.EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// 모든 key를 viper에 등록해야 환경 변수로 덮어쓸 수 있으므로 기본 설정을 먼저 읽는다
+	defaultConfig, err := config.RenderConfig(config.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	v.SetConfigType("toml")
+	if err := v.ReadConfig(bytes.NewReader(defaultConfig)); err != nil {
+		return nil, errors.Wrap(err, "read default config err")
+	}
+
+	home := v.GetString("home")
+	configFile := config.DefaultConfig().SetRoot(home).ConfigFile()
+	if _, err := os.Stat(configFile); err == nil {
+		v.SetConfigFile(configFile)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, errors.Wrapf(err, "read config file %s err", configFile)
+		}
+	}
+
+	parsed := config.DefaultConfig()
+	if err := v.Unmarshal(parsed); err != nil {
+		return nil, errors.Wrap(err, "unmarshal config err")
+	}
+	parsed.SetRoot(home)
+	if err := parsed.ValidateBasic(); err != nil {
+		return nil, errors.Wrap(err, "error in config")
+	}
+
+	return parsed, nil
+}
+
 var PaustDBCmd = &cobra.Command{
 	Use:   "paust-db",
 	Short: "Paust DB",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		conf, err = ParseConfig(cmd)
+		return err
+	},
 }
 
 func Execute() {
+	PaustDBCmd.AddCommand(InitCmd)
 	PaustDBCmd.AddCommand(MasterCmd)
+	PaustDBCmd.AddCommand(NodeCmd)
 
 	if err := PaustDBCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+func init() {
+	PaustDBCmd.PersistentFlags().String("home", config.DefaultConfig().RootDir, "directory for config and data")
+}
@@ -0,0 +1,64 @@
+package commands_test
+
+import (
+	"github.com/paust-team/paust-db/cmd/paust-db/commands"
+	"github.com/paust-team/paust-db/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestParseConfig_precedence(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	// config file, 환경 변수, flag에 같은 key를 서로 다른 값으로 설정함
+	home, err := ioutil.TempDir("", "paust-db-config")
+	require.Nil(err)
+	defer os.RemoveAll(home)
+	fileConfig := config.DefaultConfig().SetRoot(home)
+	fileConfig.ProtoAddr = "tcp://file:26658"
+	fileConfig.Transport = "grpc"
+	fileConfig.LogLevel = "debug"
+	require.Nil(config.WriteConfigFile(fileConfig.ConfigFile(), fileConfig))
+
+	require.Nil(os.Setenv("PAUSTDB_PROTO_ADDR", "tcp://env:26658"))
+	require.Nil(os.Setenv("PAUSTDB_LOG_LEVEL", "error"))
+	defer os.Unsetenv("PAUSTDB_PROTO_ADDR")
+	defer os.Unsetenv("PAUSTDB_LOG_LEVEL")
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().String("home", config.DefaultConfig().RootDir, "")
+		cmd.Flags().String("proto_addr", "", "")
+		cmd.Flags().StringP("level", "l", "", "")
+		require.Nil(cmd.Flags().Parse(args))
+		return cmd
+	}
+
+	//when
+	actual, err := commands.ParseConfig(newCmd("--home", home, "--level", "none"))
+
+	//then
+	// flag > 환경 변수 > config file > 기본값 순서로 적용됨
+	require.Nil(err)
+	require.Equal("none", actual.LogLevel)
+	require.Equal("tcp://env:26658", actual.ProtoAddr)
+	require.Equal("grpc", actual.Transport)
+	require.Equal(config.DefaultConfig().ShutdownTimeout, actual.ShutdownTimeout)
+
+	// 이전 호출의 flag, config file 값이 다음 호출에 남지 않음
+	os.Unsetenv("PAUSTDB_PROTO_ADDR")
+	os.Unsetenv("PAUSTDB_LOG_LEVEL")
+	otherHome, err := ioutil.TempDir("", "paust-db-config")
+	require.Nil(err)
+	defer os.RemoveAll(otherHome)
+	actual, err = commands.ParseConfig(newCmd("--home", otherHome))
+	require.Nil(err)
+	expected := config.DefaultConfig().SetRoot(otherHome)
+	require.Equal(expected.LogLevel, actual.LogLevel)
+	require.Equal(expected.ProtoAddr, actual.ProtoAddr)
+	require.Equal(expected.Transport, actual.Transport)
+}
@@ -0,0 +1,324 @@
+// Package config는 paust-db master의 설정 파일(TOML)을 다룸.
+package config
+
+import (
+	"github.com/paust-team/paust-db/consts"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config file 관련 상수
+const (
+	DefaultConfigDir      = "config"
+	DefaultConfigFileName = "paust-db.toml"
+	EnvPrefix             = "PAUSTDB"
+)
+
+var (
+	defaultHome           = os.ExpandEnv("$HOME/.paust-db")
+	defaultConfigFilePath = filepath.Join(DefaultConfigDir, DefaultConfigFileName)
+)
+
+// Config는 paust-db master의 최상위 설정임.
+type Config struct {
+	BaseConfig `mapstructure:",squash"`
+
+	DB              *DBConfig              `mapstructure:"db"`
+	Features        *FeaturesConfig        `mapstructure:"features"`
+	Rollup          *RollupConfig          `mapstructure:"rollup"`
+	Instrumentation *InstrumentationConfig `mapstructure:"instrumentation"`
+}
+
+// DefaultConfig는 기본값으로 채워진 Config를 return.
+func DefaultConfig() *Config {
+	return &Config{
+		BaseConfig:      DefaultBaseConfig(),
+		DB:              DefaultDBConfig(),
+		Features:        DefaultFeaturesConfig(),
+		Rollup:          DefaultRollupConfig(),
+		Instrumentation: DefaultInstrumentationConfig(),
+	}
+}
+
+// SetRoot는 home directory를 설정함. 상대 경로로 주어진 path들은 root를 기준으로 해석됨.
+func (cfg *Config) SetRoot(root string) *Config {
+	cfg.BaseConfig.RootDir = root
+	return cfg
+}
+
+// ValidateBasic은 설정값의 범위를 검사함.
+func (cfg *Config) ValidateBasic() error {
+	if err := cfg.BaseConfig.ValidateBasic(); err != nil {
+		return err
+	}
+	if err := cfg.DB.ValidateBasic(); err != nil {
+		return errors.Wrap(err, "error in [db] section")
+	}
+	if err := cfg.Rollup.ValidateBasic(); err != nil {
+		return errors.Wrap(err, "error in [rollup] section")
+	}
+
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// BaseConfig
+
+// BaseConfig는 ABCI server와 data directory에 관한 설정임.
+type BaseConfig struct {
+	// RootDir은 config, data directory의 기준 directory. config file에는 기록되지 않고 --home flag로만 설정함.
+	RootDir string `mapstructure:"home"`
+
+	// ProtoAddr는 ABCI server가 listen할 TCP 혹은 UNIX socket 주소.
+	ProtoAddr string `mapstructure:"proto_addr"`
+
+	// Transport는 ABCI transport 종류(socket | grpc).
+	Transport string `mapstructure:"transport"`
+
+	// DBPath는 rocksdb가 저장될 directory. 상대 경로일 경우 RootDir 기준이며 비어 있으면 RootDir을 사용.
+	DBPath string `mapstructure:"db_dir"`
+
+	// LogLevel은 master application의 log level(debug | info | error | none).
+	LogLevel string `mapstructure:"log_level"`
+
+	// ShutdownTimeout은 종료 signal을 받은 뒤 처리 중인 ABCI 요청을 기다리는 최대 시간.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// DefaultBaseConfig는 기본 BaseConfig를 return.
+func DefaultBaseConfig() BaseConfig {
+	return BaseConfig{
+		RootDir:         defaultHome,
+		ProtoAddr:       consts.ProtoAddr,
+		Transport:       consts.Transport,
+		DBPath:          "",
+		LogLevel:        "info",
+		ShutdownTimeout: 10 * time.Second,
+	}
+}
+
+// DBDir은 rocksdb directory의 절대 경로를 return.
+func (cfg BaseConfig) DBDir() string {
+	return rootify(cfg.DBPath, cfg.RootDir)
+}
+
+// ConfigFile은 config file의 절대 경로를 return.
+func (cfg BaseConfig) ConfigFile() string {
+	return rootify(defaultConfigFilePath, cfg.RootDir)
+}
+
+// ValidateBasic은 transport, log level, shutdown timeout 값을 검사함.
+func (cfg BaseConfig) ValidateBasic() error {
+	switch cfg.Transport {
+	case "socket", "grpc":
+	default:
+		return errors.Errorf("unknown transport %q. Expect socket or grpc", cfg.Transport)
+	}
+
+	switch cfg.LogLevel {
+	case "debug", "info", "error", "none":
+	default:
+		return errors.Errorf("unknown log_level %q. Expect debug, info, error or none", cfg.LogLevel)
+	}
+
+	if cfg.ProtoAddr == "" {
+		return errors.New("proto_addr must not be empty")
+	}
+
+	if cfg.ShutdownTimeout <= 0 {
+		return errors.New("shutdown_timeout must be positive")
+	}
+
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// DBConfig
+
+// DBConfig는 rocksdb option 설정임.
+type DBConfig struct {
+	// BlockCacheSize는 LRU block cache의 크기(byte).
+	BlockCacheSize uint64 `mapstructure:"block_cache_size"`
+
+	// WriteBufferSize는 column family별 memtable 크기(byte).
+	WriteBufferSize int `mapstructure:"write_buffer_size"`
+
+	// MaxOpenFiles는 rocksdb가 열어둘 수 있는 최대 file 수. -1이면 제한 없음.
+	MaxOpenFiles int `mapstructure:"max_open_files"`
+
+	// Compression은 SST file 압축 방식(none | snappy | zlib | lz4 | zstd).
+	Compression string `mapstructure:"compression"`
+
+	// HistoryHeights는 과거 height 기준 query를 위해 snapshot을 유지할 최근 height 수. 0이면 마지막 height만 query 가능.
+	HistoryHeights int `mapstructure:"history_heights"`
+
+	// ChunkInterval은 features.chunk_storage에서 한 chunk가 담는 시간 범위. 첫 block을 commit한 뒤에는 바꿀 수 없음.
+	ChunkInterval time.Duration `mapstructure:"chunk_interval"`
+}
+
+// DefaultDBConfig는 기본 DBConfig를 return.
+func DefaultDBConfig() *DBConfig {
+	return &DBConfig{
+		BlockCacheSize:  1 << 30,
+		WriteBufferSize: 64 << 20,
+		MaxOpenFiles:    -1,
+		Compression:     "snappy",
+		HistoryHeights:  100,
+		ChunkInterval:   2 * time.Hour,
+	}
+}
+
+// ValidateBasic은 DB option 값을 검사함.
+func (cfg *DBConfig) ValidateBasic() error {
+	if cfg.WriteBufferSize <= 0 {
+		return errors.New("write_buffer_size must be positive")
+	}
+
+	if cfg.HistoryHeights < 0 {
+		return errors.New("history_heights must not be negative")
+	}
+
+	if cfg.ChunkInterval <= 0 {
+		return errors.New("chunk_interval must be positive")
+	}
+
+	switch cfg.Compression {
+	case "none", "snappy", "zlib", "lz4", "zstd":
+	default:
+		return errors.Errorf("unknown compression %q", cfg.Compression)
+	}
+
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// FeaturesConfig
+
+// FeaturesConfig는 master application의 부가 기능 on/off 설정임.
+type FeaturesConfig struct {
+	// Serial은 tx를 순차적으로 처리할지 여부.
+	Serial bool `mapstructure:"serial"`
+
+	// ChunkStorage는 숫자 데이터를 series별 시간 범위 chunk로 압축하여 chunk column family에 함께 저장할지 여부.
+	ChunkStorage bool `mapstructure:"chunk_storage"`
+}
+
+// DefaultFeaturesConfig는 기본 FeaturesConfig를 return.
+func DefaultFeaturesConfig() *FeaturesConfig {
+	return &FeaturesConfig{
+		Serial: true,
+	}
+}
+
+//-----------------------------------------------------------------------------
+// RollupConfig
+
+// RollupConfig는 features.chunk_storage로 저장된 숫자 데이터를 resolution 단위 bucket으로 미리 집계하는 rollup 설정임.
+type RollupConfig struct {
+	// Policies는 series별 rollup resolution이며 각 항목은 "selector=resolution ..." 형식임.
+	// selector는 모든 series를 나타내는 "*", ownerId 혹은 "ownerId/qualifier"이며 여러 항목이 일치하면 더 구체적인 selector가 우선함.
+	// 모든 node가 같은 값을 사용해야 하며 첫 block을 commit한 뒤에는 바꿀 수 없음.
+	Policies []string `mapstructure:"policies"`
+}
+
+// RollupPolicy는 Policies의 항목 하나를 parse한 결과.
+// OwnerId가 "*"이면 모든 series와, Qualifier가 비어 있으면 OwnerId의 모든 series와 일치함.
+type RollupPolicy struct {
+	OwnerId     string
+	Qualifier   string
+	Resolutions []time.Duration
+}
+
+// DefaultRollupConfig는 기본 RollupConfig를 return.
+func DefaultRollupConfig() *RollupConfig {
+	return &RollupConfig{
+		Policies: []string{"*=1m 1h 24h"},
+	}
+}
+
+// ParsePolicies는 Policies를 parse하여 return. resolution은 작은 것부터 정렬됨.
+func (cfg *RollupConfig) ParsePolicies() ([]RollupPolicy, error) {
+	var policies []RollupPolicy
+	seen := make(map[string]bool)
+	for _, policy := range cfg.Policies {
+		i := strings.LastIndex(policy, "=")
+		if i < 0 {
+			return nil, errors.Errorf("wrong policy %q. Expect selector=resolution ...", policy)
+		}
+		selector := strings.TrimSpace(policy[:i])
+		if selector == "" {
+			return nil, errors.Errorf("wrong policy %q. Expect non-empty selector", policy)
+		}
+		if seen[selector] {
+			return nil, errors.Errorf("duplicate policy selector %q", selector)
+		}
+		seen[selector] = true
+
+		rollupPolicy := RollupPolicy{OwnerId: selector}
+		if j := strings.Index(selector, "/"); j >= 0 {
+			rollupPolicy.OwnerId, rollupPolicy.Qualifier = selector[:j], selector[j+1:]
+		}
+		if rollupPolicy.OwnerId == "*" && rollupPolicy.Qualifier != "" {
+			return nil, errors.Errorf("wrong policy %q. Qualifier selector needs an ownerId", policy)
+		}
+
+		for _, field := range strings.Fields(policy[i+1:]) {
+			resolution, err := time.ParseDuration(field)
+			if err != nil {
+				return nil, errors.Wrapf(err, "wrong resolution in policy %q", policy)
+			}
+			if resolution <= 0 {
+				return nil, errors.Errorf("wrong resolution in policy %q. Resolution must be positive", policy)
+			}
+			rollupPolicy.Resolutions = append(rollupPolicy.Resolutions, resolution)
+		}
+		sort.Slice(rollupPolicy.Resolutions, func(i, j int) bool { return rollupPolicy.Resolutions[i] < rollupPolicy.Resolutions[j] })
+		policies = append(policies, rollupPolicy)
+	}
+
+	return policies, nil
+}
+
+// ValidateBasic은 Policies가 parse되는지 검사함.
+func (cfg *RollupConfig) ValidateBasic() error {
+	_, err := cfg.ParsePolicies()
+	return err
+}
+
+//-----------------------------------------------------------------------------
+// InstrumentationConfig
+
+// InstrumentationConfig는 master의 HTTP server(/healthz, /readyz, /metrics) 설정임.
+type InstrumentationConfig struct {
+	// Prometheus가 true이면 ListenAddr의 /metrics에서 Prometheus metrics를 제공함.
+	Prometheus bool `mapstructure:"prometheus"`
+
+	// ListenAddr는 master HTTP server가 listen할 주소.
+	ListenAddr string `mapstructure:"listen_addr"`
+
+	// Namespace는 모든 metric 이름 앞에 붙는 prefix.
+	Namespace string `mapstructure:"namespace"`
+}
+
+// DefaultInstrumentationConfig는 기본 InstrumentationConfig를 return.
+func DefaultInstrumentationConfig() *InstrumentationConfig {
+	return &InstrumentationConfig{
+		Prometheus: false,
+		ListenAddr: ":26661",
+		Namespace:  "paustdb",
+	}
+}
+
+//-----------------------------------------------------------------------------
+// Utils
+
+func rootify(path, root string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
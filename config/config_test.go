@@ -0,0 +1,121 @@
+package config_test
+
+import (
+	"bytes"
+	"github.com/paust-team/paust-db/config"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	require := require.New(t)
+
+	cfg := config.DefaultConfig()
+	require.Nil(cfg.ValidateBasic())
+
+	cfg.SetRoot("/tmp/pdbhome")
+	require.Equal("/tmp/pdbhome", cfg.DBDir())
+	require.Equal("/tmp/pdbhome/config/paust-db.toml", cfg.ConfigFile())
+
+	cfg.DBPath = "data"
+	require.Equal("/tmp/pdbhome/data", cfg.DBDir())
+
+	cfg.DBPath = "/var/lib/paustdb"
+	require.Equal("/var/lib/paustdb", cfg.DBDir())
+}
+
+func TestConfig_ValidateBasic(t *testing.T) {
+	require := require.New(t)
+
+	cfg := config.DefaultConfig()
+	cfg.Transport = "http"
+	require.NotNil(cfg.ValidateBasic())
+
+	cfg = config.DefaultConfig()
+	cfg.LogLevel = "warn"
+	require.NotNil(cfg.ValidateBasic())
+
+	cfg = config.DefaultConfig()
+	cfg.ShutdownTimeout = 0
+	require.NotNil(cfg.ValidateBasic())
+
+	cfg = config.DefaultConfig()
+	cfg.DB.Compression = "brotli"
+	require.NotNil(cfg.ValidateBasic())
+
+	cfg = config.DefaultConfig()
+	cfg.DB.HistoryHeights = -1
+	require.NotNil(cfg.ValidateBasic())
+
+	cfg = config.DefaultConfig()
+	cfg.DB.ChunkInterval = 0
+	require.NotNil(cfg.ValidateBasic())
+
+	cfg = config.DefaultConfig()
+	cfg.Rollup.Policies = []string{"*=1m", "*=1h"}
+	require.NotNil(cfg.ValidateBasic())
+
+	cfg = config.DefaultConfig()
+	cfg.Rollup.Policies = []string{"owner1=1m 0s"}
+	require.NotNil(cfg.ValidateBasic())
+}
+
+func TestRollupConfig_ParsePolicies(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	cfg := &config.RollupConfig{Policies: []string{"*=1h 1m", "owner1=1s", `owner1/{"type":"a=b"}=1m`, "owner2="}}
+
+	//when
+	policies, err := cfg.ParsePolicies()
+
+	//then
+	require.Nil(err)
+	require.Equal([]config.RollupPolicy{
+		{OwnerId: "*", Resolutions: []time.Duration{time.Minute, time.Hour}},
+		{OwnerId: "owner1", Resolutions: []time.Duration{time.Second}},
+		{OwnerId: "owner1", Qualifier: `{"type":"a=b"}`, Resolutions: []time.Duration{time.Minute}},
+		{OwnerId: "owner2"},
+	}, policies)
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pdbconfig")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	//given
+	givenConfig := config.DefaultConfig()
+	givenConfig.ProtoAddr = "tcp://127.0.0.1:36658"
+	givenConfig.Transport = "grpc"
+	givenConfig.DBPath = "data"
+	givenConfig.LogLevel = "debug"
+	givenConfig.ShutdownTimeout = 3 * time.Second
+	givenConfig.DB.MaxOpenFiles = 512
+	givenConfig.Features.Serial = false
+	givenConfig.Rollup.Policies = []string{"*=1m 1h", `owner1/{"type":"temperature"}=10s`}
+
+	//when
+	configFile := filepath.Join(dir, "config", "paust-db.toml")
+	require.Nil(config.WriteConfigFile(configFile, givenConfig))
+
+	//then
+	configBytes, err := ioutil.ReadFile(configFile)
+	require.Nil(err)
+
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.Nil(v.ReadConfig(bytes.NewReader(configBytes)))
+
+	actualConfig := config.DefaultConfig()
+	require.Nil(v.Unmarshal(actualConfig))
+	actualConfig.SetRoot(givenConfig.RootDir)
+	require.Equal(givenConfig, actualConfig)
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// DefaultDirPerm은 config directory 생성 시 사용하는 기본 permission.
+const DefaultDirPerm = 0700
+
+var configTemplate *template.Template
+
+func init() {
+	var err error
+	if configTemplate, err = template.New("configFileTemplate").Parse(defaultConfigTemplate); err != nil {
+		panic(err)
+	}
+}
+
+// RenderConfig는 cfg를 TOML config file 형식으로 변환해 return.
+func RenderConfig(cfg *Config) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := configTemplate.Execute(&buffer, cfg); err != nil {
+		return nil, errors.Wrap(err, "config template execute err")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// WriteConfigFile은 cfg를 TOML 형식으로 configFilePath에 write함.
+func WriteConfigFile(configFilePath string, cfg *Config) error {
+	configBytes, err := RenderConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configFilePath), DefaultDirPerm); err != nil {
+		return errors.Wrap(err, "make config directory failed")
+	}
+	if err := ioutil.WriteFile(configFilePath, configBytes, 0644); err != nil {
+		return errors.Wrap(err, "write config file failed")
+	}
+
+	return nil
+}
+
+// Note: config.go의 struct field나 mapstructure tag를 바꾸면 아래 template도 함께 수정해야 함.
+const defaultConfigTemplate = `# This is a TOML config file for paust-db master.
+# For more information, see https://github.com/toml-lang/toml
+
+##### main base config options #####
+
+# TCP or UNIX socket address for the ABCI application server to listen on
+proto_addr = "{{ .BaseConfig.ProtoAddr }}"
+
+# Transport protocol used by the ABCI server (socket | grpc)
+transport = "{{ .BaseConfig.Transport }}"
+
+# Directory of the rocksdb database. Relative paths are resolved against
+# the home directory, and an empty value means the home directory itself
+db_dir = "{{ .BaseConfig.DBPath }}"
+
+# Output level for logging (debug | info | error | none)
+log_level = "{{ .BaseConfig.LogLevel }}"
+
+# Maximum time to wait for in-flight ABCI requests on shutdown
+shutdown_timeout = "{{ .BaseConfig.ShutdownTimeout }}"
+
+##### rocksdb options #####
+[db]
+
+# Size of the LRU block cache in bytes
+block_cache_size = {{ .DB.BlockCacheSize }}
+
+# Size of a single memtable in bytes
+write_buffer_size = {{ .DB.WriteBufferSize }}
+
+# Maximum number of open files. -1 means unlimited
+max_open_files = {{ .DB.MaxOpenFiles }}
+
+# SST file compression (none | snappy | zlib | lz4 | zstd)
+compression = "{{ .DB.Compression }}"
+
+# Number of recent heights kept for historical queries. Queries at older
+# heights fail. 0 keeps only the latest height
+history_heights = {{ .DB.HistoryHeights }}
+
+# Time range of a chunk when features.chunk_storage is enabled. Must be the
+# same on every node and cannot be changed after the first block
+chunk_interval = "{{ .DB.ChunkInterval }}"
+
+##### feature toggles #####
+[features]
+
+# Process transactions serially
+serial = {{ .Features.Serial }}
+
+# Also store numeric data in compressed per series chunks for /range queries.
+# Must be the same on every node and cannot be changed after the first block
+chunk_storage = {{ .Features.ChunkStorage }}
+
+##### rollup options #####
+[rollup]
+
+# Rollup resolutions of numeric series when features.chunk_storage is enabled.
+# Each entry is "selector=resolution ...". The selector is "*" for all series,
+# an ownerId or "ownerId/qualifier", and the most specific one wins. Must be
+# the same on every node and cannot be changed after the first block
+policies = [{{ range $i, $policy := .Rollup.Policies }}{{ if $i }}, {{ end }}{{ printf "%q" $policy }}{{ end }}]
+
+##### instrumentation configuration options #####
+[instrumentation]
+
+# When true, Prometheus metrics are served under /metrics on listen_addr
+prometheus = {{ .Instrumentation.Prometheus }}
+
+# Address for the master HTTP server to listen on
+listen_addr = "{{ .Instrumentation.ListenAddr }}"
+
+# Instrumentation namespace
+namespace = "{{ .Instrumentation.Namespace }}"
+`
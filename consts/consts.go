@@ -3,6 +3,24 @@ package consts
 //Data Length관련 상수
 const (
 	OwnerIdLenLimit = 64
+	RowKeyLen       = 16
+	LegacyRowKeyLen = 10
+	TimestampLen    = 8
+)
+
+//Response code 상수. tendermint example code와 겹치지 않도록 100부터 사용
+const (
+	CodeTypeDuplicateRowKey uint32 = 100
+	CodeTypeInvalidRowKey   uint32 = 101
+	CodeTypeInvalidConflict uint32 = 102
+	CodeTypeInvalidOwnerId  uint32 = 103
+)
+
+//Conflict policy 상수. 이미 write된 rowKey에 다시 write할 때의 처리 방법
+const (
+	ConflictReject    = "reject"
+	ConflictOverwrite = "overwrite"
+	ConflictKeepFirst = "keep-first"
 )
 
 //ColumnFamily위치 관련 상수
@@ -10,13 +28,44 @@ const (
 	DefaultCFNum = iota
 	MetaCFNum
 	RealCFNum
+	ProofCFNum
+	HistoryCFNum
+	SeriesCFNum
+	ChunkCFNum
+	RollupCFNum
+	LatestCFNum
 	TotalCFNum
 )
 
 //Server, Client config 공통 상수
 const (
-	QueryPath = "/query"
-	FetchPath = "/fetch"
+	QueryPath     = "/query"
+	FetchPath     = "/fetch"
+	QueryDataPath = "/querydata"
+	HistoryPath   = "/history"
+	SeriesPath    = "/series"
+	RangePath     = "/range"
+	LatestPath    = "/latest"
+	CountPath     = "/count"
+	QLPath        = "/ql"
+)
+
+//Query proof 상수. ResponseQuery.Proof의 ProofOp type
+const (
+	RowProofOpType = "paustdb:row"
+)
+
+//DeliverTx tag 상수. tendermint event subscription과 tx_search에서 사용
+const (
+	ActionTagKey         = "paustdb.action"
+	OwnerIdTagKey        = "paustdb.owner"
+	QualifierTypeTagKey  = "paustdb.type"
+	OwnerIdsTagKey       = "paustdb.owners"
+	QualifierTypesTagKey = "paustdb.types"
+	MinTimestampTagKey   = "paustdb.mintime"
+	MaxTimestampTagKey   = "paustdb.maxtime"
+	PutAction            = "put"
+	TagListSeparator     = "|"
 )
 
 //Client config 상수
@@ -24,7 +73,7 @@ const (
 	WsEndpoint = "/websocket"
 )
 
-//Server config 상수
+//Server config 기본값 상수. config file, flag, 환경 변수로 변경 가능
 const (
 	ProtoAddr = "0.0.0.0:26658"
 	Transport = "socket"
@@ -10,6 +10,11 @@ PAUSTDB=/usr/bin/paust-db
 
 export TMHOME="/tendermint/node${ID}"
 
-$PAUSTDB master -d $TMHOME &
+# node는 tendermint를 내장한 paust-db 단일 process로 실행하고,
+# init, testnet 등 나머지 command는 tendermint로 전달함
+if [ "$1" = "node" ]; then
+  shift
+  exec $PAUSTDB node --home $TMHOME "$@"
+fi
 
-$TENDERMINT $@
+exec $TENDERMINT "$@"
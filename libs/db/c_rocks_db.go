@@ -9,6 +9,9 @@ import (
 
 var _ DB = (*CRocksDB)(nil)
 
+// ColumnFamilyNames는 consts의 ColumnFamily 위치 순서대로 나열된 column family 이름임.
+var ColumnFamilyNames = []string{"default", "metadata", "realdata", "proof", "history", "series", "chunk", "rollup", "latest"}
+
 type CRocksDB struct {
 	db                  *gorocksdb.DB
 	ro                  *gorocksdb.ReadOptions
@@ -16,19 +19,52 @@ type CRocksDB struct {
 	columnFamilyHandles gorocksdb.ColumnFamilyHandles
 }
 
+// Options는 rocksdb open 시 사용하는 option임.
+type Options struct {
+	BlockCacheSize  uint64
+	WriteBufferSize int
+	MaxOpenFiles    int
+	Compression     string
+}
+
+// DefaultOptions는 기본 Options를 return.
+func DefaultOptions() Options {
+	return Options{
+		BlockCacheSize:  1 << 30,
+		WriteBufferSize: 64 << 20,
+		MaxOpenFiles:    -1,
+		Compression:     "snappy",
+	}
+}
+
 func NewCRocksDB(name, dir string) (*CRocksDB, error) {
+	return NewCRocksDBWithOptions(name, dir, DefaultOptions())
+}
+
+func NewCRocksDBWithOptions(name, dir string, options Options) (*CRocksDB, error) {
 	dbPath := filepath.Join(dir, name+".db")
-	columnFamilyNames := []string{"default", "metadata", "realdata"}
+
+	compression, err := compressionType(options.Compression)
+	if err != nil {
+		return nil, err
+	}
 
 	bbto := gorocksdb.NewDefaultBlockBasedTableOptions()
-	bbto.SetBlockCache(gorocksdb.NewLRUCache(1 << 30))
+	bbto.SetBlockCache(gorocksdb.NewLRUCache(options.BlockCacheSize))
 	defaultOpts := gorocksdb.NewDefaultOptions()
 	defaultOpts.SetBlockBasedTableFactory(bbto)
 	defaultOpts.SetCreateIfMissing(true)
 	defaultOpts.SetCreateIfMissingColumnFamilies(true)
+	defaultOpts.SetMaxOpenFiles(options.MaxOpenFiles)
 
 	opts := gorocksdb.NewDefaultOptions()
-	db, columnFamilyHandles, err := gorocksdb.OpenDbColumnFamilies(defaultOpts, dbPath, columnFamilyNames, []*gorocksdb.Options{opts, opts, opts})
+	opts.SetWriteBufferSize(options.WriteBufferSize)
+	opts.SetCompression(compression)
+	columnFamilyOpts := make([]*gorocksdb.Options, len(ColumnFamilyNames))
+	for i := range columnFamilyOpts {
+		columnFamilyOpts[i] = opts
+	}
+	db, columnFamilyHandles, err := gorocksdb.OpenDbColumnFamilies(defaultOpts, dbPath, ColumnFamilyNames, columnFamilyOpts)
 
 	if err != nil {
 		fmt.Println("DB open error", err)
@@ -47,11 +83,33 @@ func NewCRocksDB(name, dir string) (*CRocksDB, error) {
 	return database, nil
 }
 
+func compressionType(name string) (gorocksdb.CompressionType, error) {
+	switch name {
+	case "none":
+		return gorocksdb.NoCompression, nil
+	case "", "snappy":
+		return gorocksdb.SnappyCompression, nil
+	case "zlib":
+		return gorocksdb.ZLibCompression, nil
+	case "lz4":
+		return gorocksdb.LZ4Compression, nil
+	case "zstd":
+		return gorocksdb.ZSTDCompression, nil
+	default:
+		return gorocksdb.NoCompression, fmt.Errorf("unknown compression type: %s", name)
+	}
+}
+
 // Implements DB.
 func (db CRocksDB) GetDataFromColumnFamily(index int, key []byte) (*gorocksdb.Slice, error) {
 	return db.db.GetCF(db.ro, db.ColumnFamilyHandles()[index], key)
 }
 
+// Implements DB.
+func (db CRocksDB) GetPropertyFromColumnFamily(index int, propName string) string {
+	return db.db.GetPropertyCF(propName, db.ColumnFamilyHandles()[index])
+}
+
 // Implements DB.
 func (db *CRocksDB) SetDataInColumnFamily(index int, key, value []byte) error {
 	return db.db.PutCF(db.wo, db.ColumnFamilyHandles()[index], key, value)
@@ -91,6 +149,48 @@ func (db CRocksDB) ColumnFamilyHandles() gorocksdb.ColumnFamilyHandles {
 	return db.columnFamilyHandles
 }
 
+// NewSnapshot은 현재 DB 상태를 read하는 Snapshot을 생성함. 사용이 끝나면 Release해야 함.
+func (db *CRocksDB) NewSnapshot() *Snapshot {
+	snapshot := db.db.NewSnapshot()
+	ro := gorocksdb.NewDefaultReadOptions()
+	ro.SetSnapshot(snapshot)
+
+	return &Snapshot{db: db, snapshot: snapshot, ro: ro}
+}
+
+//----------------------------------------
+// Snapshot
+var _ Reader = (*Snapshot)(nil)
+
+// Snapshot은 생성 시점의 DB 상태를 read함. DB를 close하기 전에 Release해야 함.
+type Snapshot struct {
+	db       *CRocksDB
+	snapshot *gorocksdb.Snapshot
+	ro       *gorocksdb.ReadOptions
+}
+
+// Implements Reader.
+func (snapshot *Snapshot) GetDataFromColumnFamily(index int, key []byte) (*gorocksdb.Slice, error) {
+	return snapshot.db.db.GetCF(snapshot.ro, snapshot.db.ColumnFamilyHandles()[index], key)
+}
+
+// Implements Reader.
+func (snapshot *Snapshot) IteratorColumnFamily(start, end []byte, cf *gorocksdb.ColumnFamilyHandle) Iterator {
+	itr := snapshot.db.db.NewIteratorCF(snapshot.ro, cf)
+	return newCRocksDBIterator(itr, start, end)
+}
+
+// Implements Reader.
+func (snapshot *Snapshot) ColumnFamilyHandles() gorocksdb.ColumnFamilyHandles {
+	return snapshot.db.ColumnFamilyHandles()
+}
+
+// Release는 snapshot이 잡고 있는 resource를 해제함.
+func (snapshot *Snapshot) Release() {
+	snapshot.db.db.ReleaseSnapshot(snapshot.snapshot)
+	snapshot.ro.Destroy()
+}
+
 //----------------------------------------
 // Batch
 var _ Batch = (*cRocksDBBatch)(nil)
@@ -2,6 +2,7 @@ package db_test
 
 import (
 	"github.com/paust-team/paust-db/consts"
+	"strconv"
 )
 
 func (suite *DBSuite) TestDBCreateRetrieveInColumnFamily() {
@@ -24,5 +25,18 @@ func (suite *DBSuite) TestDBCreateRetrieveInColumnFamily() {
 }
 
 func (suite *DBSuite) TestColumnFamilyLength() {
-	suite.Equal(consts.TotalCFNum, len(suite.DB.ColumnFamilyHandles()), "The number of ColumnFamilies should be 3")
+	suite.Equal(consts.TotalCFNum, len(suite.DB.ColumnFamilyHandles()), "The number of ColumnFamilies should be 9")
+}
+
+func (suite *DBSuite) TestGetPropertyFromColumnFamily() {
+	require := suite.Require()
+
+	err := suite.DB.SetDataInColumnFamily(consts.MetaCFNum, []byte("hello"), []byte("world"))
+	require.Nil(err, "MetaColumnFamily Set error : %v", err)
+
+	for i := range suite.DB.ColumnFamilyHandles() {
+		value := suite.DB.GetPropertyFromColumnFamily(i, "rocksdb.estimate-num-keys")
+		_, err := strconv.ParseUint(value, 10, 64)
+		suite.Nil(err, "property should be a number, got %q", value)
+	}
 }
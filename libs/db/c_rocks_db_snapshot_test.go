@@ -0,0 +1,31 @@
+package db_test
+
+import (
+	"github.com/paust-team/paust-db/consts"
+)
+
+func (suite *DBSuite) TestSnapshotReadsOldState() {
+	require := suite.Require()
+
+	givenKey := []byte("Key")
+	givenValue := []byte("Value")
+	require.Nil(suite.DB.SetDataInColumnFamily(consts.MetaCFNum, givenKey, givenValue))
+
+	snapshot := suite.DB.NewSnapshot()
+	defer snapshot.Release()
+	require.Nil(suite.DB.SetDataInColumnFamily(consts.MetaCFNum, givenKey, []byte("NewValue")))
+	require.Nil(suite.DB.SetDataInColumnFamily(consts.MetaCFNum, []byte("Key2"), []byte("Value2")))
+
+	actualValue, err := snapshot.GetDataFromColumnFamily(consts.MetaCFNum, givenKey)
+	require.Nil(err, "Snapshot Get Error : %v", err)
+	defer actualValue.Free()
+	require.Equal(givenValue, actualValue.Data())
+
+	itr := snapshot.IteratorColumnFamily(nil, nil, snapshot.ColumnFamilyHandles()[consts.MetaCFNum])
+	defer itr.Close()
+	var keys []string
+	for ; itr.Valid(); itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+	require.Equal([]string{"Key"}, keys)
+}
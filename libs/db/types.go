@@ -7,6 +7,9 @@ type DB interface {
 	// Get value from specific ColumnFamily
 	GetDataFromColumnFamily(index int, key []byte) (*gorocksdb.Slice, error)
 
+	// Get rocksdb property(e.g. "rocksdb.cur-size-all-mem-tables") of specific ColumnFamily
+	GetPropertyFromColumnFamily(index int, propName string) string
+
 	// Set value In specific ColumnFamily
 	SetDataInColumnFamily(index int, key, value []byte) error
 
@@ -31,6 +34,21 @@ type DB interface {
 	Close()
 }
 
+//----------------------------------------
+// Reader
+
+// Reader는 DB 혹은 Snapshot에서 데이터를 read하는 interface임.
+type Reader interface {
+	// Get value from specific ColumnFamily
+	GetDataFromColumnFamily(index int, key []byte) (*gorocksdb.Slice, error)
+
+	// Specific Column Family Iterator
+	IteratorColumnFamily(start, end []byte, cf *gorocksdb.ColumnFamilyHandle) Iterator
+
+	// Get all ColumnFamily handles which return slice of *columnFamilyHandle
+	ColumnFamilyHandles() gorocksdb.ColumnFamilyHandles
+}
+
 //----------------------------------------
 // Batch
 
@@ -0,0 +1,109 @@
+package ql
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+	"unicode"
+)
+
+// tokenKind는 lexer가 만드는 token의 종류.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenIdent는 keyword, field 이름 혹은 "qualifier.type" 같이 '.'으로 이어진 이름.
+	tokenIdent
+	// tokenString은 작은따옴표로 감싼 문자열이며 안의 ''는 '로 읽음.
+	tokenString
+	// tokenNumber는 10진수 정수.
+	tokenNumber
+	// tokenDuration은 "1m", "1h30m" 같이 숫자로 시작하고 단위가 붙은 시간 간격.
+	tokenDuration
+	// tokenSymbol은 =, !=, <>, <, <=, >, >=, (, ), ',', *.
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// is는 token이 symbol 혹은 대소문자 구분 없이 keyword value인지 return.
+func (tok token) is(value string) bool {
+	return (tok.kind == tokenSymbol || tok.kind == tokenIdent) && strings.EqualFold(tok.value, value)
+}
+
+// lex는 query를 token으로 나눔. 마지막 token은 항상 tokenEOF.
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+
+		case r == '\'':
+			var value strings.Builder
+			start := pos
+			pos++
+			for {
+				if pos >= len(runes) {
+					return nil, errors.Errorf("unterminated string at %v", start)
+				}
+				if runes[pos] == '\'' {
+					if pos+1 < len(runes) && runes[pos+1] == '\'' {
+						value.WriteRune('\'')
+						pos += 2
+						continue
+					}
+					pos++
+					break
+				}
+				value.WriteRune(runes[pos])
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value.String(), pos: start})
+
+		case unicode.IsDigit(r):
+			start := pos
+			for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+				pos++
+			}
+			kind := tokenNumber
+			// "1h30m" 같이 숫자와 단위가 번갈아 오면 하나의 duration으로 읽음
+			for pos < len(runes) && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos]) || runes[pos] == '.') {
+				kind = tokenDuration
+				pos++
+			}
+			tokens = append(tokens, token{kind: kind, value: string(runes[start:pos]), pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := pos
+			for pos < len(runes) && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos]) || runes[pos] == '_' || runes[pos] == '.') {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:pos]), pos: start})
+
+		default:
+			start := pos
+			symbol := string(r)
+			if pos+1 < len(runes) {
+				switch two := string(runes[pos : pos+2]); two {
+				case "!=", "<>", "<=", ">=":
+					symbol = two
+				}
+			}
+			switch symbol {
+			case "=", "!=", "<>", "<", "<=", ">", ">=", "(", ")", ",", "*":
+			default:
+				return nil, errors.Errorf("unexpected character %q at %v", r, start)
+			}
+			pos += len([]rune(symbol))
+			tokens = append(tokens, token{kind: tokenSymbol, value: symbol, pos: start})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
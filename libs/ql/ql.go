@@ -0,0 +1,459 @@
+// Package ql은 paust-db의 데이터를 read하는 SQL 형태의 query language임.
+//
+//	SELECT avg(value), max(value) FROM qualifier.type='temperature'
+//	WHERE owner='x' AND time >= '2019-01-01T00:00:00Z' AND time < '2019-01-02T00:00:00Z'
+//	GROUP BY time(1m), owner LIMIT 100
+//
+// FROM과 WHERE의 조건은 모두 AND로 합쳐짐. time range의 시작이 없으면 Unix epoch부터, 끝이 없으면 마지막 데이터까지 read하며
+// GROUP BY time의 group은 시작 시각부터 나뉨.
+// SELECT *는 조건에 맞는 데이터를, 집계 함수는 숫자 데이터를 group마다 집계한 value를 read함.
+package ql
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Funcs는 SELECT에 사용할 수 있는 집계 함수.
+var Funcs = []string{"count", "sum", "avg", "min", "max", "first", "last"}
+
+// Field는 SELECT의 집계 column 하나.
+type Field struct {
+	Func string
+}
+
+// String은 field의 column 이름을 return.
+func (field Field) String() string {
+	return field.Func + "(value)"
+}
+
+// Condition은 ownerId 혹은 qualifier에 대한 조건 하나.
+// Name은 "owner", "qualifier" 혹은 qualifier JSON object의 key를 가리키는 "qualifier.<key>"이며
+// Op는 "=", "!=", "in", "like" 중 하나. "like"의 Values는 '%'를 뗀 prefix 하나임.
+type Condition struct {
+	Name   string
+	Op     string
+	Values []string
+}
+
+// Query는 parse된 query plan. Fields가 비어 있으면 SELECT *이며 Start 이상 End 미만의 time range를 read함.
+// GroupByTime이 0보다 크면 Start부터 GroupByTime 간격의 group으로, GroupByOwner이면 ownerId마다 집계함.
+// Limit이 0보다 크면 최대 Limit개의 데이터 혹은 group을 return.
+type Query struct {
+	Fields       []Field
+	Start        uint64
+	End          uint64
+	Conditions   []Condition
+	GroupByTime  uint64
+	GroupByOwner bool
+	Limit        int
+}
+
+// Columns는 집계 query의 column 이름을 SELECT 순서로 return.
+func (query *Query) Columns() []string {
+	var columns []string
+	for _, field := range query.Fields {
+		columns = append(columns, field.String())
+	}
+	return columns
+}
+
+// Match는 ownerId, qualifier가 모든 조건을 만족하는지 return.
+func (query *Query) Match(ownerId string, qualifier []byte) bool {
+	var object map[string]interface{}
+	parsed := false
+	for _, condition := range query.Conditions {
+		var value string
+		switch {
+		case condition.Name == "owner":
+			value = ownerId
+		case condition.Name == "qualifier":
+			value = string(qualifier)
+		default:
+			if !parsed {
+				parsed = true
+				if err := json.Unmarshal(qualifier, &object); err != nil {
+					object = nil
+				}
+			}
+			var ok bool
+			// qualifier가 JSON object가 아니거나 key가 없으면 어떤 조건도 만족하지 않음
+			if value, ok = lookup(object, strings.TrimPrefix(condition.Name, "qualifier.")); !ok {
+				return false
+			}
+		}
+		if !condition.match(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (condition Condition) match(value string) bool {
+	switch condition.Op {
+	case "like":
+		return strings.HasPrefix(value, condition.Values[0])
+	case "!=":
+		return value != condition.Values[0]
+	default:
+		for _, v := range condition.Values {
+			if value == v {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// lookup은 '.'으로 이어진 path를 따라 object의 value를 찾아 문자열로 return.
+// 문자열이 아닌 value는 JSON으로 encoding된 문자열과 비교함.
+func lookup(object map[string]interface{}, path string) (string, bool) {
+	keys := strings.Split(path, ".")
+	var value interface{} = object
+	for _, key := range keys {
+		current, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = current[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case map[string]interface{}, []interface{}:
+		return "", false
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(encoded), true
+	}
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse는 query를 parse하여 query plan을 return.
+func Parse(query string) (*Query, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	q := &Query{End: math.MaxUint64}
+	if err := p.parse(q); err != nil {
+		return nil, err
+	}
+	if q.Start >= q.End {
+		return nil, errors.New("time range is empty")
+	}
+
+	return q, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept는 다음 token이 value이면 token을 넘기고 true를 return.
+func (p *parser) accept(value string) bool {
+	if p.peek().is(value) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(value string) error {
+	if !p.accept(value) {
+		return p.unexpected(value)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	tok := p.peek()
+	if tok.kind == tokenEOF {
+		return errors.Errorf("expected %v at %v, got end of query", expected, tok.pos)
+	}
+	return errors.Errorf("expected %v at %v, got %q", expected, tok.pos, tok.value)
+}
+
+func (p *parser) parse(q *Query) error {
+	if err := p.expect("SELECT"); err != nil {
+		return err
+	}
+	if !p.accept("*") {
+		for {
+			field, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			q.Fields = append(q.Fields, field)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return err
+	}
+	if !p.accept("*") {
+		if err := p.parseConditions(q); err != nil {
+			return err
+		}
+	}
+
+	if p.accept("WHERE") {
+		if err := p.parseConditions(q); err != nil {
+			return err
+		}
+	}
+
+	if p.accept("GROUP") {
+		if err := p.expect("BY"); err != nil {
+			return err
+		}
+		if len(q.Fields) == 0 {
+			return errors.New("GROUP BY requires aggregate functions")
+		}
+		for {
+			if err := p.parseGroup(q); err != nil {
+				return err
+			}
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.accept("LIMIT") {
+		tok := p.next()
+		limit, err := strconv.Atoi(tok.value)
+		if tok.kind != tokenNumber || err != nil || limit <= 0 {
+			return errors.Errorf("LIMIT must be a positive number at %v", tok.pos)
+		}
+		q.Limit = limit
+	}
+
+	if p.peek().kind != tokenEOF {
+		return p.unexpected("end of query")
+	}
+
+	return nil
+}
+
+func (p *parser) parseField() (Field, error) {
+	tok := p.next()
+	name := strings.ToLower(tok.value)
+	supported := false
+	for _, f := range Funcs {
+		supported = supported || (tok.kind == tokenIdent && name == f)
+	}
+	if !supported {
+		return Field{}, errors.Errorf("unknown function %q at %v: must be one of %v", tok.value, tok.pos, strings.Join(Funcs, ", "))
+	}
+	if err := p.expect("("); err != nil {
+		return Field{}, err
+	}
+	// count(*)는 count(value)와 같음
+	if !p.accept("value") && !(name == "count" && p.accept("*")) {
+		return Field{}, p.unexpected("value")
+	}
+	if err := p.expect(")"); err != nil {
+		return Field{}, err
+	}
+
+	return Field{Func: name}, nil
+}
+
+func (p *parser) parseConditions(q *Query) error {
+	for {
+		if err := p.parseCondition(q); err != nil {
+			return err
+		}
+		if !p.accept("AND") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseCondition(q *Query) error {
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return errors.Errorf("expected condition at %v, got %q", tok.pos, tok.value)
+	}
+	name := strings.ToLower(tok.value)
+	switch {
+	case name == "time":
+		return p.parseTime(q)
+	case name == "owner" || name == "ownerid":
+		name = "owner"
+	case name == "qualifier":
+	case strings.HasPrefix(name, "qualifier."):
+		// qualifier JSON의 key는 대소문자를 구분함
+		name = "qualifier." + tok.value[len("qualifier."):]
+		if strings.HasSuffix(name, ".") || strings.Contains(name, "..") {
+			return errors.Errorf("invalid qualifier key %q at %v", tok.value, tok.pos)
+		}
+	default:
+		return errors.Errorf("unknown field %q at %v: must be one of time, owner, qualifier, qualifier.<key>", tok.value, tok.pos)
+	}
+
+	condition := Condition{Name: name}
+	switch {
+	case p.accept("="):
+		condition.Op = "="
+	case p.accept("!="), p.accept("<>"):
+		condition.Op = "!="
+	case p.accept("IN"):
+		condition.Op = "in"
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		for {
+			value, err := p.parseString()
+			if err != nil {
+				return err
+			}
+			condition.Values = append(condition.Values, value)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		q.Conditions = append(q.Conditions, condition)
+		return nil
+	case p.accept("LIKE"):
+		condition.Op = "like"
+		pos := p.peek().pos
+		pattern, err := p.parseString()
+		if err != nil {
+			return err
+		}
+		prefix := strings.TrimSuffix(pattern, "%")
+		if prefix == pattern || strings.ContainsAny(prefix, "%_") {
+			return errors.Errorf("only prefix pattern 'prefix%%' is supported in LIKE at %v", pos)
+		}
+		condition.Values = []string{prefix}
+		q.Conditions = append(q.Conditions, condition)
+		return nil
+	default:
+		return p.unexpected("=, !=, IN or LIKE")
+	}
+
+	value, err := p.parseString()
+	if err != nil {
+		return err
+	}
+	condition.Values = []string{value}
+	q.Conditions = append(q.Conditions, condition)
+	return nil
+}
+
+func (p *parser) parseString() (string, error) {
+	if p.peek().kind != tokenString {
+		return "", p.unexpected("string")
+	}
+	return p.next().value, nil
+}
+
+// parseTime은 time 조건을 q의 time range에 반영함. 여러 조건이 주어지면 모든 조건을 만족하는 range로 좁힘.
+func (p *parser) parseTime(q *Query) error {
+	op := p.next()
+	if op.kind != tokenSymbol || !strings.Contains(" = < <= > >= ", " "+op.value+" ") {
+		return errors.Errorf("expected =, <, <=, > or >= at %v, got %q", op.pos, op.value)
+	}
+
+	tok := p.next()
+	var timestamp uint64
+	switch tok.kind {
+	case tokenNumber:
+		var err error
+		if timestamp, err = strconv.ParseUint(tok.value, 10, 64); err != nil {
+			return errors.Errorf("invalid timestamp %q at %v", tok.value, tok.pos)
+		}
+	case tokenString:
+		t, err := time.Parse(time.RFC3339Nano, tok.value)
+		if err != nil || t.UnixNano() < 0 {
+			return errors.Errorf("invalid time %q at %v: must be RFC3339", tok.value, tok.pos)
+		}
+		timestamp = uint64(t.UnixNano())
+	default:
+		return errors.Errorf("expected timestamp at %v, got %q", tok.pos, tok.value)
+	}
+
+	start, end := uint64(0), uint64(math.MaxUint64)
+	// math.MaxUint64를 넘는 경계는 math.MaxUint64로 자름
+	next := timestamp
+	if next < math.MaxUint64 {
+		next++
+	}
+	switch op.value {
+	case "=":
+		start, end = timestamp, next
+	case "<":
+		end = timestamp
+	case "<=":
+		end = next
+	case ">":
+		start = next
+	case ">=":
+		start = timestamp
+	}
+	if start > q.Start {
+		q.Start = start
+	}
+	if end < q.End {
+		q.End = end
+	}
+
+	return nil
+}
+
+func (p *parser) parseGroup(q *Query) error {
+	switch {
+	case p.accept("owner"), p.accept("ownerId"):
+		q.GroupByOwner = true
+	case p.accept("time"):
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		tok := p.next()
+		interval, err := time.ParseDuration(tok.value)
+		if tok.kind != tokenDuration || err != nil || interval <= 0 {
+			return errors.Errorf("invalid interval %q at %v", tok.value, tok.pos)
+		}
+		q.GroupByTime = uint64(interval)
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+	default:
+		return p.unexpected("time(<interval>) or owner")
+	}
+
+	return nil
+}
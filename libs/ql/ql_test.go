@@ -0,0 +1,125 @@
+package ql_test
+
+import (
+	"github.com/paust-team/paust-db/libs/ql"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	query := `select AVG(value), count(*) FROM qualifier.type='temperature' AND qualifier IN ('humidity', 'it''s')
+		WHERE owner LIKE 'fleet-%' AND owner != 'fleet-x' AND time >= '2019-01-01T00:00:00Z' AND time < 1546300860000000000 AND time > 1546300800000000000
+		GROUP BY time(1m), owner LIMIT 10`
+
+	//when
+	actual, err := ql.Parse(query)
+
+	//then
+	require.Nil(err)
+	require.Equal(&ql.Query{
+		Fields: []ql.Field{{Func: "avg"}, {Func: "count"}},
+		Start:  1546300800000000001,
+		End:    1546300860000000000,
+		Conditions: []ql.Condition{
+			{Name: "qualifier.type", Op: "=", Values: []string{"temperature"}},
+			{Name: "qualifier", Op: "in", Values: []string{"humidity", "it's"}},
+			{Name: "owner", Op: "like", Values: []string{"fleet-"}},
+			{Name: "owner", Op: "!=", Values: []string{"fleet-x"}},
+		},
+		GroupByTime:  uint64(time.Minute),
+		GroupByOwner: true,
+		Limit:        10,
+	}, actual)
+	require.Equal([]string{"avg(value)", "count(value)"}, actual.Columns())
+}
+
+func TestParse_defaultTimeRange(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	// time 조건이 없거나 시작, 끝 중 하나만 주어진 query
+	queries := map[string][2]uint64{
+		"SELECT avg(value) FROM qualifier.type='temperature' WHERE owner='x' GROUP BY time(1m)": {0, math.MaxUint64},
+		"SELECT * FROM * WHERE time >= 10": {10, math.MaxUint64},
+		"SELECT * FROM * WHERE time < 10":  {0, 10},
+	}
+
+	for query, expected := range queries {
+		//when
+		actual, err := ql.Parse(query)
+
+		//then
+		// 없는 시작은 Unix epoch, 없는 끝은 마지막 데이터까지로 read함
+		require.Nil(err, query)
+		require.Equal(expected[0], actual.Start, query)
+		require.Equal(expected[1], actual.End, query)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	queries := map[string]string{
+		"SELECT * FROM * WHERE time < 0":                                                "time range is empty",
+		"SELECT * FROM * WHERE time >= 10 AND time < 10":                                "time range is empty",
+		"SELECT median(value) FROM * WHERE time = 1":                                    "unknown function",
+		"SELECT sum(*) FROM * WHERE time = 1":                                           "expected value",
+		"SELECT * FROM * WHERE time = 1 GROUP BY time(1m)":                              "GROUP BY requires aggregate functions",
+		"SELECT avg(value) FROM * WHERE time = 1 GROUP BY time(0s)":                     "invalid interval",
+		"SELECT avg(value) FROM * WHERE time = 1 GROUP BY qualifier":                    "expected time(<interval>) or owner",
+		"SELECT * FROM * WHERE owner LIKE '%a%' AND time = 1":                           "only prefix pattern",
+		"SELECT * FROM * WHERE data = 'a' AND time = 1":                                 "unknown field",
+		"SELECT * FROM * WHERE time = 'yesterday'":                                      "invalid time",
+		"SELECT * FROM * WHERE owner = 'a' OR owner = 'b' AND time = 1":                 "expected end of query",
+		"SELECT * FROM * WHERE time = 1 LIMIT 0":                                        "LIMIT must be a positive number",
+		"SELECT * FROM * WHERE owner = 'a":                                              "unterminated string",
+		"SELECT * FROM * WHERE owner = \"a\"":                                           "unexpected character",
+		"SELECT * FROM qualifier.='a' WHERE time = 1":                                   "invalid qualifier key",
+		"SELECT * FROM * WHERE time = 1 AND":                                            "expected condition",
+		"SELECT * FROM * WHERE owner IN () AND time = 1":                                "expected string",
+		"SELECT count(value) FROM * WHERE time = 1 GROUP BY time(1m) LIMIT 1 LIMIT 1":   "expected end of query",
+		"SELECT count(value) FROM * WHERE time = 1 GROUP BY time(1m), time(1h) LIMIT 1": "",
+	}
+
+	for query, expected := range queries {
+		//when
+		_, err := ql.Parse(query)
+
+		//then
+		if expected == "" {
+			require.Nil(err, query)
+			continue
+		}
+		require.NotNil(err, query)
+		require.Contains(err.Error(), expected, query)
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	query, err := ql.Parse(`SELECT * FROM qualifier.type IN ('temperature', 'humidity') AND qualifier.sensor.floor = '3'
+		WHERE owner LIKE 'fleet-%' AND time = 1`)
+	require.Nil(err)
+
+	//when
+	actual := query.Match("fleet-a", []byte(`{"type":"temperature","sensor":{"floor":3}}`))
+
+	//then
+	// 문자열이 아닌 qualifier value는 JSON encoding과 비교함
+	require.True(actual)
+	require.True(query.Match("fleet-b", []byte(`{"type":"humidity","sensor":{"floor":3,"room":"a"}}`)))
+	require.False(query.Match("owner1", []byte(`{"type":"temperature","sensor":{"floor":3}}`)))
+	require.False(query.Match("fleet-a", []byte(`{"type":"speed","sensor":{"floor":3}}`)))
+	require.False(query.Match("fleet-a", []byte(`{"type":"temperature","sensor":{"floor":"4"}}`)))
+	// qualifier가 JSON object가 아니거나 key가 없으면 일치하지 않음
+	require.False(query.Match("fleet-a", []byte(`{"type":"temperature"}`)))
+	require.False(query.Match("fleet-a", []byte("temperature")))
+}
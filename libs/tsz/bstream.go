@@ -0,0 +1,68 @@
+package tsz
+
+import (
+	"github.com/pkg/errors"
+)
+
+// errEOF는 bit stream을 끝까지 read한 뒤 더 read하려 할 때 return됨.
+var errEOF = errors.New("unexpected end of chunk")
+
+// bstream은 bit 단위로 write하는 stream. 마지막 byte에서 아직 쓰지 않은 bit 수를 count로 유지함.
+type bstream struct {
+	stream []byte
+	count  uint8
+}
+
+func (b *bstream) writeBit(bit bool) {
+	if b.count == 0 {
+		b.stream = append(b.stream, 0)
+		b.count = 8
+	}
+
+	b.count--
+	if bit {
+		b.stream[len(b.stream)-1] |= 1 << b.count
+	}
+}
+
+// writeBits는 u의 하위 nbits개 bit를 상위 bit부터 write함.
+func (b *bstream) writeBits(u uint64, nbits int) {
+	for nbits > 0 {
+		nbits--
+		b.writeBit((u>>uint(nbits))&1 == 1)
+	}
+}
+
+// bstreamReader는 bstream이 write한 byte를 bit 단위로 read함.
+type bstreamReader struct {
+	stream []byte
+	// pos는 다음에 read할 bit의 위치.
+	pos int
+}
+
+func (b *bstreamReader) readBit() (bool, error) {
+	if b.pos >= len(b.stream)*8 {
+		return false, errEOF
+	}
+
+	bit := b.stream[b.pos/8]&(1<<uint(7-b.pos%8)) != 0
+	b.pos++
+	return bit, nil
+}
+
+// readBits는 nbits개 bit를 read하여 하위 bit에 담아 return.
+func (b *bstreamReader) readBits(nbits int) (uint64, error) {
+	var u uint64
+	for i := 0; i < nbits; i++ {
+		bit, err := b.readBit()
+		if err != nil {
+			return 0, err
+		}
+		u <<= 1
+		if bit {
+			u |= 1
+		}
+	}
+
+	return u, nil
+}
@@ -0,0 +1,277 @@
+// Package tsz는 Facebook Gorilla 논문의 방식으로 timestamp, float64 value point를 압축하는 encoding임.
+// timestamp는 delta-of-delta로, value는 이전 value와의 XOR로 encoding되므로 일정한 간격으로 수집된 비슷한 값의 point일수록 작아짐.
+package tsz
+
+import (
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"math"
+	"math/bits"
+)
+
+// Point는 nano second 단위 unix timestamp T와 value V.
+type Point struct {
+	T uint64
+	V float64
+}
+
+// dodBuckets는 delta-of-delta를 담는 bit 수. prefix의 1 개수가 bucket 위치이며 마지막 bucket은 64 bit 전체를 담음.
+var dodBuckets = []int{7, 9, 12, 32}
+
+// Encoder는 timestamp 순서로 push된 point를 압축함.
+type Encoder struct {
+	b     bstream
+	count uint64
+
+	t      uint64
+	tDelta uint64
+
+	v        uint64
+	leading  uint8
+	trailing uint8
+}
+
+// NewEncoder는 빈 Encoder를 return.
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+// Push는 point를 추가함. t는 이전 point의 timestamp보다 작을 수 없음.
+func (e *Encoder) Push(t uint64, v float64) error {
+	if e.count > 0 && t < e.t {
+		return errors.Errorf("timestamp %v is before previous timestamp %v", t, e.t)
+	}
+
+	vBits := math.Float64bits(v)
+	if e.count == 0 {
+		e.b.writeBits(t, 64)
+		e.b.writeBits(vBits, 64)
+		// 첫 XOR은 항상 새 window를 쓰도록 window를 비워 둠
+		e.leading, e.trailing = math.MaxUint8, 0
+	} else {
+		tDelta := t - e.t
+		e.writeDod(int64(tDelta - e.tDelta))
+		e.tDelta = tDelta
+		e.writeXOR(vBits ^ e.v)
+	}
+
+	e.t, e.v = t, vBits
+	e.count++
+	return nil
+}
+
+func (e *Encoder) writeDod(dod int64) {
+	if dod == 0 {
+		e.b.writeBit(false)
+		return
+	}
+
+	for _, nbits := range dodBuckets {
+		e.b.writeBit(true)
+		if bitRange(dod, nbits) {
+			e.b.writeBit(false)
+			e.b.writeBits(uint64(dod), nbits)
+			return
+		}
+	}
+	e.b.writeBit(true)
+	e.b.writeBits(uint64(dod), 64)
+}
+
+func (e *Encoder) writeXOR(xor uint64) {
+	if xor == 0 {
+		e.b.writeBit(false)
+		return
+	}
+	e.b.writeBit(true)
+
+	leading := uint8(bits.LeadingZeros64(xor))
+	trailing := uint8(bits.TrailingZeros64(xor))
+	// leading zero 수는 5 bit로 담으므로 31을 넘지 않게 함
+	if leading > 31 {
+		leading = 31
+	}
+
+	// 이전 window 안에 meaningful bit가 모두 들어가면 window를 다시 쓰지 않음
+	if e.leading != math.MaxUint8 && leading >= e.leading && trailing >= e.trailing {
+		e.b.writeBit(false)
+		e.b.writeBits(xor>>e.trailing, 64-int(e.leading)-int(e.trailing))
+		return
+	}
+
+	e.leading, e.trailing = leading, trailing
+	sigbits := 64 - leading - trailing
+	e.b.writeBit(true)
+	e.b.writeBits(uint64(leading), 5)
+	// sigbits는 1~64이며 64는 0으로 담음
+	e.b.writeBits(uint64(sigbits), 6)
+	e.b.writeBits(xor>>trailing, int(sigbits))
+}
+
+// Bytes는 point 수와 압축된 bit stream을 담은 byte를 return.
+func (e *Encoder) Bytes() []byte {
+	header := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(header, e.count)
+	return append(header[:n], e.b.stream...)
+}
+
+// Encode는 timestamp 순서로 정렬된 points를 압축함.
+func Encode(points []Point) ([]byte, error) {
+	encoder := NewEncoder()
+	for _, point := range points {
+		if err := encoder.Push(point.T, point.V); err != nil {
+			return nil, err
+		}
+	}
+
+	return encoder.Bytes(), nil
+}
+
+// Iterator는 Encoder가 압축한 point를 순서대로 read함.
+type Iterator struct {
+	b     bstreamReader
+	count uint64
+	read  uint64
+
+	t      uint64
+	tDelta uint64
+
+	v        uint64
+	leading  uint8
+	trailing uint8
+
+	err error
+}
+
+// NewIterator는 Encoder.Bytes()가 return한 byte를 read하는 Iterator를 return.
+func NewIterator(data []byte) (*Iterator, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errors.New("wrong chunk header")
+	}
+
+	return &Iterator{b: bstreamReader{stream: data[n:]}, count: count}, nil
+}
+
+// Next는 다음 point로 이동함. 더 이상 point가 없거나 error가 있으면 false를 return.
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.read == it.count {
+		return false
+	}
+
+	if it.read == 0 {
+		it.t, it.err = it.b.readBits(64)
+		if it.err == nil {
+			it.v, it.err = it.b.readBits(64)
+		}
+	} else {
+		var dod int64
+		dod, it.err = it.readDod()
+		if it.err == nil {
+			it.tDelta += uint64(dod)
+			it.t += it.tDelta
+			it.err = it.readXOR()
+		}
+	}
+	if it.err != nil {
+		it.err = errors.Wrapf(it.err, "read point %v failed", it.read)
+		return false
+	}
+
+	it.read++
+	return true
+}
+
+func (it *Iterator) readDod() (int64, error) {
+	bit, err := it.b.readBit()
+	if err != nil || !bit {
+		return 0, err
+	}
+
+	for _, nbits := range dodBuckets {
+		bit, err := it.b.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if !bit {
+			u, err := it.b.readBits(nbits)
+			if err != nil {
+				return 0, err
+			}
+			// nbits bit의 2의 보수를 sign extend함
+			return int64(u<<uint(64-nbits)) >> uint(64-nbits), nil
+		}
+	}
+
+	u, err := it.b.readBits(64)
+	return int64(u), err
+}
+
+func (it *Iterator) readXOR() error {
+	bit, err := it.b.readBit()
+	if err != nil || !bit {
+		return err
+	}
+
+	bit, err = it.b.readBit()
+	if err != nil {
+		return err
+	}
+	if bit {
+		leading, err := it.b.readBits(5)
+		if err != nil {
+			return err
+		}
+		sigbits, err := it.b.readBits(6)
+		if err != nil {
+			return err
+		}
+		if sigbits == 0 {
+			sigbits = 64
+		}
+		if leading+sigbits > 64 {
+			return errors.Errorf("wrong xor window. leading %v, significant bits %v", leading, sigbits)
+		}
+		it.leading = uint8(leading)
+		it.trailing = uint8(64 - leading - sigbits)
+	}
+
+	sigbits := 64 - int(it.leading) - int(it.trailing)
+	u, err := it.b.readBits(sigbits)
+	if err != nil {
+		return err
+	}
+	it.v ^= u << it.trailing
+	return nil
+}
+
+// At은 현재 point의 timestamp와 value를 return.
+func (it *Iterator) At() (uint64, float64) {
+	return it.t, math.Float64frombits(it.v)
+}
+
+// Err는 read 중에 발생한 error를 return.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Decode는 Encoder.Bytes()가 return한 byte의 모든 point를 return.
+func Decode(data []byte) ([]Point, error) {
+	it, err := NewIterator(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []Point
+	for it.Next() {
+		t, v := it.At()
+		points = append(points, Point{T: t, V: v})
+	}
+
+	return points, it.Err()
+}
+
+// bitRange는 v가 nbits bit의 2의 보수로 표현 가능한지 return.
+func bitRange(v int64, nbits int) bool {
+	return -(1<<uint(nbits-1)) <= v && v <= (1<<uint(nbits-1))-1
+}
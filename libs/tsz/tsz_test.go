@@ -0,0 +1,78 @@
+package tsz_test
+
+import (
+	"github.com/paust-team/paust-db/libs/tsz"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	// 일정한 간격, 불규칙한 간격, 같은 timestamp, 큰 간격의 point와 다양한 value를 섞음
+	start := uint64(1545982882435375000)
+	points := []tsz.Point{
+		{T: start, V: 21.5},
+		{T: start + 1e9, V: 21.5},
+		{T: start + 2e9, V: 21.7},
+		{T: start + 3e9, V: -3},
+		{T: start + 3e9, V: 0},
+		{T: start + 3e9 + 17, V: math.MaxFloat64},
+		{T: start + 3600e9, V: math.SmallestNonzeroFloat64},
+		{T: math.MaxUint64, V: 1e-300},
+	}
+
+	//when
+	data, err := tsz.Encode(points)
+
+	//then
+	require.Nil(err)
+	actualPoints, err := tsz.Decode(data)
+	require.Nil(err)
+	require.Equal(points, actualPoints)
+}
+
+func TestEncode_regularSeries(t *testing.T) {
+	require := require.New(t)
+
+	//given
+	// 1초 간격으로 수집된 값이 거의 변하지 않는 series
+	var points []tsz.Point
+	for i := 0; i < 1000; i++ {
+		points = append(points, tsz.Point{T: 1545982882000000000 + uint64(i)*1e9, V: float64(20 + i%2)})
+	}
+
+	//when
+	data, err := tsz.Encode(points)
+
+	//then
+	// point 하나가 16 byte가 아니라 평균 2 byte 미만으로 압축됨
+	require.Nil(err)
+	require.True(len(data) < 2*len(points), "encoded size %v", len(data))
+	actualPoints, err := tsz.Decode(data)
+	require.Nil(err)
+	require.Equal(points, actualPoints)
+}
+
+func TestEncode_errors(t *testing.T) {
+	require := require.New(t)
+
+	// 빈 point는 빈 chunk로 encoding됨
+	data, err := tsz.Encode(nil)
+	require.Nil(err)
+	points, err := tsz.Decode(data)
+	require.Nil(err)
+	require.Equal(0, len(points))
+
+	// timestamp 순서가 아니면 error
+	_, err = tsz.Encode([]tsz.Point{{T: 2, V: 1}, {T: 1, V: 1}})
+	require.NotNil(err)
+
+	// 잘린 chunk는 error
+	data, err = tsz.Encode([]tsz.Point{{T: 1, V: 1}, {T: 2, V: 3}})
+	require.Nil(err)
+	_, err = tsz.Decode(data[:len(data)-2])
+	require.NotNil(err)
+}
@@ -2,55 +2,191 @@ package master
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"github.com/paust-team/paust-db/config"
 	"github.com/paust-team/paust-db/consts"
 	"github.com/paust-team/paust-db/libs/db"
 	"github.com/paust-team/paust-db/libs/log"
+	"github.com/paust-team/paust-db/libs/tsz"
 	"github.com/paust-team/paust-db/types"
 	"github.com/pkg/errors"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"github.com/tendermint/tendermint/abci/example/code"
 	abciTypes "github.com/tendermint/tendermint/abci/types"
-	"math/rand"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"math"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type MasterApplication struct {
 	abciTypes.BaseApplication
 
-	hash   []byte
 	serial bool
 	db     *db.CRocksDB
-	wb     db.Batch
-	mwb    db.Batch
+	// wb는 현재 block에서 write할 모든 column family의 batch로 Commit에서 tree node, last height와 함께 한 번에 write됨.
+	wb db.Batch
 
-	logger log.Logger
+	// tree는 app hash를 계산하는 merkle tree이며 blockRows는 현재 block에서 write된 row로 Commit에서 tree에 추가되거나
+	// overwrite된 rowKey의 leaf를 교체함.
+	tree      *stateTree
+	blockRows []blockRow
+	// blockRowKeys는 현재 block에서 write된 데이터를 rowKey별로 담아 같은 block 안의 중복 rowKey를 찾는 데 사용함.
+	blockRowKeys map[string]types.BaseDataObj
+	// blockSeries는 현재 block에서 처음 write된 series의 id이며 nextSeriesId는 다음에 부여할 series id.
+	blockSeries  map[string]uint64
+	nextSeriesId uint64
+	// chunkStorage가 true이면 숫자 데이터를 chunkInterval(nano second) 단위 chunk에도 저장함. blockChunks는 현재 block에서 수정된 chunk.
+	chunkStorage  bool
+	chunkInterval uint64
+	blockChunks   map[string][]tsz.Point
+	// rollupPolicies는 series별 rollup resolution을 정하는 config policy. blockResolutions는 현재 block에서 read하거나 정한 series의 resolution이며
+	// blockRollups는 Commit에서 다시 집계할 rollup bucket key.
+	rollupPolicies   []config.RollupPolicy
+	blockResolutions map[uint64][]uint64
+	blockRollups     map[string]struct{}
+	// blockLatest는 현재 block에서 갱신된 series의 최신 rowKey.
+	blockLatest map[uint64][]byte
+	// pendingSettings는 아직 DB에 저장되지 않은 chunk, rollup 설정이며 첫 Commit에서 block과 함께 저장함.
+	pendingSettings []byte
+
+	logger  log.Logger
+	metrics *Metrics
+
+	// dbMtx는 DB close와 ABCI 요청의 DB 접근이 겹치지 않도록 보호함. stateMtx보다 먼저 lock해야 함.
+	dbMtx sync.RWMutex
+
+	// health check용 상태. ABCI 요청과 HTTP handler에서 동시에 접근하므로 stateMtx로 보호함.
+	stateMtx      sync.RWMutex
+	dbOpen        bool
+	handshaked    bool
+	height        int64
+	lastHeight    int64
+	lastBlockTime time.Time
+	// treeSize, appHash는 마지막으로 commit된 merkle tree의 상태. Query는 이 상태를 기준으로 proof를 만듦.
+	treeSize uint64
+	appHash  []byte
+	// snapshots는 과거 height 기준 Query를 위한 최근 historyHeights개 height의 snapshot이며 height 순서로 정렬됨.
+	historyHeights int
+	snapshots      []*heightSnapshot
+}
+
+// blockRow는 merkle tree에 추가하거나 교체할 row의 rowKey와 hash.
+type blockRow struct {
+	rowKey   []byte
+	metaHash []byte
+	realHash []byte
 }
 
 func NewMasterApplication(serial bool, dir string, option log.Option) (*MasterApplication, error) {
-	hash := make([]byte, 8)
+	cfg := config.DefaultConfig()
+	cfg.SetRoot(dir)
+	cfg.Features.Serial = serial
+
+	return NewMasterApplicationWithConfig(cfg, option)
+}
+
+// NewMasterApplicationWithConfig는 cfg의 db directory와 rocksdb option으로 MasterApplication을 생성함.
+func NewMasterApplicationWithConfig(cfg *config.Config, option log.Option) (*MasterApplication, error) {
+	dir := cfg.DBDir()
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 		return nil, errors.Wrap(err, "make directory failed")
 	}
-	database, err := db.NewCRocksDB(consts.DBName, dir)
+	dbOptions := db.Options{
+		BlockCacheSize:  cfg.DB.BlockCacheSize,
+		WriteBufferSize: cfg.DB.WriteBufferSize,
+		MaxOpenFiles:    cfg.DB.MaxOpenFiles,
+		Compression:     cfg.DB.Compression,
+	}
+	database, err := db.NewCRocksDBWithOptions(consts.DBName, dir, dbOptions)
 	if err != nil {
 		return nil, errors.Wrap(err, "NewCRocksDB err")
 	}
 
-	binary.BigEndian.PutUint64(hash, rand.Uint64())
-	return &MasterApplication{
-		serial: serial,
-		hash:   hash,
-		db:     database,
-		logger: log.NewFilter(log.NewPDBLogger(log.NewSyncWriter(os.Stdout)), option),
-	}, nil
+	metrics := NopMetrics()
+	if cfg.Instrumentation.Prometheus {
+		metrics = PrometheusMetrics(stdprometheus.DefaultRegisterer, cfg.Instrumentation.Namespace)
+	}
+
+	tree, err := loadStateTree(database)
+	if err != nil {
+		database.Close()
+		return nil, errors.Wrap(err, "load merkle tree err")
+	}
+	lastHeight, err := getUint64(database, consts.DefaultCFNum, lastHeightKey)
+	if err != nil {
+		database.Close()
+		return nil, errors.Wrap(err, "load last height err")
+	}
+	// series id는 1부터 부여하며 0은 등록되지 않은 series를 나타냄
+	nextSeriesId, err := getUint64(database, consts.DefaultCFNum, nextSeriesIdKey)
+	if err != nil {
+		database.Close()
+		return nil, errors.Wrap(err, "load next series id err")
+	}
+	if nextSeriesId == 0 {
+		nextSeriesId = 1
+	}
+	rollupPolicies, err := cfg.Rollup.ParsePolicies()
+	if err != nil {
+		database.Close()
+		return nil, errors.Wrap(err, "parse rollup policies err")
+	}
+	settings := newStorageSettings(cfg, rollupPolicies)
+	settingsStored, err := checkStorageSettings(database, settings)
+	if err != nil {
+		database.Close()
+		return nil, errors.Wrap(err, "check storage settings err")
+	}
+
+	app := &MasterApplication{
+		serial:         cfg.Features.Serial,
+		db:             database,
+		wb:             database.NewBatch(),
+		tree:           tree,
+		nextSeriesId:   nextSeriesId,
+		chunkStorage:   cfg.Features.ChunkStorage,
+		chunkInterval:  uint64(cfg.DB.ChunkInterval),
+		rollupPolicies: rollupPolicies,
+		logger:         log.NewFilter(log.NewPDBLogger(log.NewSyncWriter(os.Stdout)), option),
+		metrics:        metrics,
+		dbOpen:         true,
+		lastHeight:     int64(lastHeight),
+		treeSize:       tree.size,
+		appHash:        tree.root(),
+		historyHeights: cfg.DB.HistoryHeights,
+	}
+	if !settingsStored {
+		if app.pendingSettings, err = json.Marshal(settings); err != nil {
+			database.Close()
+			return nil, errors.Wrap(err, "encode storage settings err")
+		}
+	}
+	// 재시작 이전 height의 snapshot은 남아 있지 않으므로 마지막 height부터 유지함
+	app.addSnapshot(int64(lastHeight), tree.size)
+
+	return app, nil
 }
 
 func (app *MasterApplication) Info(req abciTypes.RequestInfo) abciTypes.ResponseInfo {
+	// tendermint는 연결 직후 handshake로 Info를 호출함
+	app.stateMtx.Lock()
+	app.handshaked = true
+	lastHeight, appHash := app.lastHeight, app.appHash
+	app.stateMtx.Unlock()
+
+	// tendermint는 LastBlockHeight 이후의 block만 replay함
 	return abciTypes.ResponseInfo{
-		Data: fmt.Sprintf("---- Info"),
+		Data:             fmt.Sprintf("---- Info"),
+		LastBlockHeight:  lastHeight,
+		LastBlockAppHash: appHash,
 	}
 }
 
@@ -58,24 +194,169 @@ func (app *MasterApplication) CheckTx(tx []byte) abciTypes.ResponseCheckTx {
 	var baseDataObjs []types.BaseDataObj
 	err := json.Unmarshal(tx, &baseDataObjs)
 	if err != nil {
+		app.metrics.CheckTxRejects.With("reason", "encoding").Add(1)
 		return abciTypes.ResponseCheckTx{Code: code.CodeTypeEncodingError, Log: err.Error()}
 	}
 
+	app.dbMtx.RLock()
+	defer app.dbMtx.RUnlock()
+	if !app.dbOpen {
+		return abciTypes.ResponseCheckTx{Code: code.CodeTypeUnknownError, Log: "db is closed"}
+	}
+
+	// mempool 단계에서는 commit된 rowKey와의 중복만 검사하며 같은 block의 중복은 DeliverTx에서 검사함
+	if _, resCode, err := app.checkRowKeys(baseDataObjs, nil); err != nil {
+		var reason string
+		switch resCode {
+		case consts.CodeTypeDuplicateRowKey:
+			reason = "duplicate"
+		case consts.CodeTypeInvalidConflict:
+			reason = "conflict"
+		case code.CodeTypeUnauthorized:
+			reason = "unauthorized"
+		default:
+			reason = "rowkey"
+		}
+		app.metrics.CheckTxRejects.With("reason", reason).Add(1)
+		return abciTypes.ResponseCheckTx{Code: resCode, Log: err.Error()}
+	}
+
 	return abciTypes.ResponseCheckTx{Code: code.CodeTypeOK}
 }
 
+// rowAction은 put tx의 데이터를 conflict policy에 따라 처리하는 방법.
+type rowAction int
+
+const (
+	// rowInsert는 새 rowKey의 데이터를 write함.
+	rowInsert rowAction = iota
+	// rowOverwrite는 이미 commit된 데이터를 history에 남기고 새 데이터로 대체함.
+	rowOverwrite
+	// rowSkip은 keep-first policy로 이미 write된 rowKey의 데이터를 write하지 않음.
+	rowSkip
+	// rowDuplicate는 같은 rowKey에 같은 데이터가 이미 write되어 있어 write하지 않음.
+	rowDuplicate
+)
+
+// checkRowKeys는 baseDataObjs의 rowKey와 ownerId가 올바른 형식인지 검사하고 tx 안, pending, 이미 commit된 rowKey와 겹치는 데이터를
+// 각 데이터의 conflict policy에 따라 처리할 방법을 return. 거부해야 하면 response code와 error를 return.
+// ownerId, qualifier, data까지 같은 데이터는 재전송으로 보고 conflict policy와 관계없이 write하지 않음.
+// overwrite는 commit된 데이터의 owner가 같을 때만 허용하며 같은 block 안에서 아직 commit되지 않은 rowKey는 overwrite할 수 없음.
+func (app *MasterApplication) checkRowKeys(baseDataObjs []types.BaseDataObj, pending map[string]types.BaseDataObj) ([]rowAction, uint32, error) {
+	actions := make([]rowAction, len(baseDataObjs))
+	seen := make(map[string]types.BaseDataObj, len(baseDataObjs))
+	for i, baseDataObj := range baseDataObjs {
+		rowKey := baseDataObj.MetaData.RowKey
+		if len(rowKey) < consts.TimestampLen {
+			return nil, consts.CodeTypeInvalidRowKey, errors.Errorf("wrong rowKey length. Expect %v or above, got %v", consts.TimestampLen, len(rowKey))
+		}
+		if !bytes.Equal(rowKey, baseDataObj.RealData.RowKey) {
+			return nil, consts.CodeTypeInvalidRowKey, errors.Errorf("rowKey of metadata %X and realdata %X are different", rowKey, baseDataObj.RealData.RowKey)
+		}
+		// ownerId 길이가 제한되어야 series key가 다른 owner의 series key와 겹치지 않음
+		if ownerId := baseDataObj.MetaData.OwnerId; len(ownerId) == 0 || len(ownerId) > consts.OwnerIdLenLimit {
+			return nil, consts.CodeTypeInvalidOwnerId, errors.Errorf("wrong ownerId length of rowKey %X. Expect %v or below, got %v", rowKey, consts.OwnerIdLenLimit, len(ownerId))
+		}
+		conflict := baseDataObj.Conflict
+		switch conflict {
+		case "", consts.ConflictReject, consts.ConflictOverwrite, consts.ConflictKeepFirst:
+		default:
+			return nil, consts.CodeTypeInvalidConflict, errors.Errorf("unknown conflict policy %q of rowKey %X", conflict, rowKey)
+		}
+
+		previous, inTx := seen[string(rowKey)]
+		inBlock := false
+		if !inTx {
+			previous, inBlock = pending[string(rowKey)]
+		}
+		if inTx || inBlock {
+			if sameData(previous.MetaData.OwnerId, previous.MetaData.Qualifier, previous.RealData.Data, baseDataObj) {
+				actions[i] = rowDuplicate
+				continue
+			}
+			if conflict == consts.ConflictKeepFirst {
+				actions[i] = rowSkip
+				continue
+			}
+			if inTx {
+				return nil, consts.CodeTypeDuplicateRowKey, errors.Errorf("duplicate rowKey %X in tx", rowKey)
+			}
+			return nil, consts.CodeTypeDuplicateRowKey, errors.Errorf("duplicate rowKey %X in block", rowKey)
+		}
+		seen[string(rowKey)] = baseDataObj
+
+		slice, err := app.db.GetDataFromColumnFamily(consts.MetaCFNum, rowKey)
+		if err != nil {
+			return nil, code.CodeTypeUnknownError, errors.Wrap(err, "GetDataFromColumnFamily err")
+		}
+		if !slice.Exists() {
+			slice.Free()
+			continue
+		}
+		ownerId, qualifier, err := newSeriesResolver(app.db).resolve(slice.Data())
+		slice.Free()
+		if err != nil {
+			return nil, code.CodeTypeUnknownError, err
+		}
+		realSlice, err := app.db.GetDataFromColumnFamily(consts.RealCFNum, rowKey)
+		if err != nil {
+			return nil, code.CodeTypeUnknownError, errors.Wrap(err, "GetDataFromColumnFamily err")
+		}
+		duplicate := sameData(ownerId, qualifier, realSlice.Data(), baseDataObj)
+		realSlice.Free()
+
+		switch {
+		case duplicate:
+			actions[i] = rowDuplicate
+		case conflict == consts.ConflictKeepFirst:
+			actions[i] = rowSkip
+		case conflict == consts.ConflictOverwrite:
+			if ownerId != baseDataObj.MetaData.OwnerId {
+				return nil, code.CodeTypeUnauthorized, errors.Errorf("rowKey %X is owned by another owner", rowKey)
+			}
+			actions[i] = rowOverwrite
+		default:
+			return nil, consts.CodeTypeDuplicateRowKey, errors.Errorf("rowKey %X already exists", rowKey)
+		}
+	}
+
+	return actions, code.CodeTypeOK, nil
+}
+
+// sameData는 baseDataObj의 ownerId, qualifier, data가 주어진 값과 모두 같은지 return.
+func sameData(ownerId string, qualifier []byte, data []byte, baseDataObj types.BaseDataObj) bool {
+	return ownerId == baseDataObj.MetaData.OwnerId && bytes.Equal(qualifier, baseDataObj.MetaData.Qualifier) && bytes.Equal(data, baseDataObj.RealData.Data)
+}
+
 func (app *MasterApplication) InitChain(req abciTypes.RequestInitChain) abciTypes.ResponseInitChain {
 	app.wb = app.db.NewBatch()
-	app.mwb = app.db.NewBatch()
+	app.blockRows = nil
+	app.blockRowKeys = nil
+	app.blockSeries = nil
+	app.blockChunks = nil
+	app.blockResolutions = nil
+	app.blockRollups = nil
+	app.blockLatest = nil
 
 	return abciTypes.ResponseInitChain{}
 }
 
 func (app *MasterApplication) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.ResponseBeginBlock {
+	app.stateMtx.Lock()
+	app.height = req.Header.Height
+	app.stateMtx.Unlock()
+
 	return abciTypes.ResponseBeginBlock{}
 }
 
 func (app *MasterApplication) DeliverTx(tx []byte) abciTypes.ResponseDeliverTx {
+	app.dbMtx.RLock()
+	defer app.dbMtx.RUnlock()
+	// close 이후의 DeliverTx 결과가 block에 기록되면 replay 결과와 달라지므로 응답하지 않고 멈춤
+	if !app.dbOpen {
+		panic("DeliverTx after db is closed")
+	}
+
 	//Unmarshal tx to baseDataObjs
 	var baseDataObjs []types.BaseDataObj
 	if err := json.Unmarshal(tx, &baseDataObjs); err != nil {
@@ -83,26 +364,214 @@ func (app *MasterApplication) DeliverTx(tx []byte) abciTypes.ResponseDeliverTx {
 		return abciTypes.ResponseDeliverTx{Code: code.CodeTypeEncodingError, Log: err.Error()}
 	}
 
+	// rowKey 형식이 잘못되었거나 conflict policy에 따라 거부해야 하는 데이터가 있으면 tx 전체를 거부함
+	actions, resCode, err := app.checkRowKeys(baseDataObjs, app.blockRowKeys)
+	if err != nil {
+		app.logger.Error("Error checking rowKeys", "state", "DeliverTx", "err", err)
+		return abciTypes.ResponseDeliverTx{Code: resCode, Log: err.Error()}
+	}
+	if app.blockRowKeys == nil {
+		app.blockRowKeys = make(map[string]types.BaseDataObj)
+	}
+	app.stateMtx.RLock()
+	height := app.height
+	app.stateMtx.RUnlock()
+
 	//meta와 real 나누어 batch에 담는다
+	var written []types.BaseDataObj
 	for i := 0; i < len(baseDataObjs); i++ {
-		var metaValue struct {
-			OwnerId   string `json:"ownerId"`
-			Qualifier []byte `json:"qualifier"`
+		if actions[i] == rowSkip || actions[i] == rowDuplicate {
+			continue
+		}
+		var previous types.HistoryObj
+		if actions[i] == rowOverwrite {
+			if previous, err = app.replaceRow(baseDataObjs[i].MetaData.RowKey, height); err != nil {
+				app.logger.Error("Error writing history", "state", "DeliverTx", "err", err)
+				return abciTypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError, Log: err.Error()}
+			}
 		}
-		metaValue.OwnerId = baseDataObjs[i].MetaData.OwnerId
-		metaValue.Qualifier = baseDataObjs[i].MetaData.Qualifier
 
-		metaData, err := json.Marshal(metaValue)
+		// metadata에는 ownerId, qualifier 대신 series id를 저장함
+		metaDataObj := baseDataObjs[i].MetaData
+		seriesId, err := app.seriesId(metaDataObj.OwnerId, metaDataObj.Qualifier)
 		if err != nil {
-			app.logger.Error("Error marshaling metaValue", "state", "DeliverTx", "err", err)
-			return abciTypes.ResponseDeliverTx{Code: code.CodeTypeEncodingError, Log: err.Error()}
+			app.logger.Error("Error getting series id", "state", "DeliverTx", "err", err)
+			return abciTypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+		app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.MetaCFNum], metaDataObj.RowKey, seriesRef(seriesId))
+		if err := app.setLatest(seriesId, metaDataObj.RowKey); err != nil {
+			app.logger.Error("Error writing latest index", "state", "DeliverTx", "err", err)
+			return abciTypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+		if app.chunkStorage {
+			if err := app.addChunkPoint(seriesId, metaDataObj, baseDataObjs[i].RealData.Data); err != nil {
+				app.logger.Error("Error writing chunk", "state", "DeliverTx", "err", err)
+				return abciTypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError, Log: err.Error()}
+			}
 		}
-		app.mwb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.MetaCFNum], baseDataObjs[i].MetaData.RowKey, metaData)
 		app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.RealCFNum], baseDataObjs[i].RealData.RowKey, baseDataObjs[i].RealData.Data)
+		app.blockRowKeys[string(baseDataObjs[i].MetaData.RowKey)] = baseDataObjs[i]
+		// 다른 series로 overwrite된 rowKey가 이전 series의 최신 rowKey이면 이전 series에 남은 최신 데이터로 고침
+		if actions[i] == rowOverwrite && (previous.OwnerId != metaDataObj.OwnerId || !bytes.Equal(previous.Qualifier, metaDataObj.Qualifier)) {
+			if err := app.repairLatest(previous.OwnerId, previous.Qualifier, metaDataObj.RowKey); err != nil {
+				app.logger.Error("Error repairing latest index", "state", "DeliverTx", "err", err)
+				return abciTypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError, Log: err.Error()}
+			}
+		}
+		app.blockRows = append(app.blockRows, blockRow{
+			rowKey:   baseDataObjs[i].MetaData.RowKey,
+			metaHash: types.MetaDataHash(metaDataObj.OwnerId, metaDataObj.Qualifier),
+			realHash: types.RealDataHash(baseDataObjs[i].RealData.Data),
+		})
+		written = append(written, baseDataObjs[i])
 	}
 
-	app.logger.Info("Put success", "state", "DeliverTx", "size", len(baseDataObjs), "tx", tx)
-	return abciTypes.ResponseDeliverTx{Code: code.CodeTypeOK}
+	var skipped, duplicates int
+	var putResObj types.PutResObj
+	for i, action := range actions {
+		switch action {
+		case rowSkip:
+			skipped++
+		case rowDuplicate:
+			duplicates++
+		default:
+			continue
+		}
+		putResObj.Skipped = append(putResObj.Skipped, i)
+	}
+	// subscription이 write된 데이터만 전달할 수 있도록 write하지 않은 데이터의 index를 담음
+	var resData []byte
+	if len(putResObj.Skipped) > 0 {
+		var err error
+		if resData, err = json.Marshal(putResObj); err != nil {
+			app.logger.Error("Error marshaling PutResObj", "state", "DeliverTx", "err", err)
+			return abciTypes.ResponseDeliverTx{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+	}
+	var infos []string
+	if skipped > 0 {
+		infos = append(infos, fmt.Sprintf("%v data skipped by keep-first", skipped))
+	}
+	if duplicates > 0 {
+		infos = append(infos, fmt.Sprintf("%v duplicate data skipped", duplicates))
+	}
+	app.metrics.TxsDelivered.Add(1)
+	app.metrics.ObjectsDelivered.Add(float64(len(written)))
+	app.logger.Info("Put success", "state", "DeliverTx", "size", len(written), "tx", tx)
+	return abciTypes.ResponseDeliverTx{Code: code.CodeTypeOK, Data: resData, Info: strings.Join(infos, ", "), Tags: deliverTxTags(written)}
+}
+
+// replaceRow는 overwrite로 대체되는 rowKey의 commit된 데이터를 history에 남기고 chunk에서 제거한 뒤 대체된 데이터를 return.
+func (app *MasterApplication) replaceRow(rowKey []byte, height int64) (types.HistoryObj, error) {
+	previous, err := app.committedRow(rowKey, height)
+	if err != nil {
+		return previous, err
+	}
+	if err := app.setHistory(previous); err != nil {
+		return previous, err
+	}
+	if app.chunkStorage {
+		return previous, app.removeChunkPoint(previous.OwnerId, previous.Qualifier, rowKey, previous.Data)
+	}
+
+	return previous, nil
+}
+
+// committedRow는 rowKey에 commit된 데이터를 height에 대체되는 HistoryObj로 return.
+func (app *MasterApplication) committedRow(rowKey []byte, height int64) (types.HistoryObj, error) {
+	metaSlice, err := app.db.GetDataFromColumnFamily(consts.MetaCFNum, rowKey)
+	if err != nil {
+		return types.HistoryObj{}, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	ownerId, qualifier, err := newSeriesResolver(app.db).resolve(metaSlice.Data())
+	metaSlice.Free()
+	if err != nil {
+		return types.HistoryObj{}, err
+	}
+	realSlice, err := app.db.GetDataFromColumnFamily(consts.RealCFNum, rowKey)
+	if err != nil {
+		return types.HistoryObj{}, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	data := make([]byte, realSlice.Size())
+	copy(data, realSlice.Data())
+	realSlice.Free()
+
+	return types.HistoryObj{RowKey: rowKey, OwnerId: ownerId, Qualifier: qualifier, Data: data, Height: height}, nil
+}
+
+// setHistory는 대체된 데이터 historyObj를 block batch에 담음.
+func (app *MasterApplication) setHistory(historyObj types.HistoryObj) error {
+	key := historyKey(historyObj.RowKey, historyObj.Height)
+	historyValue, err := json.Marshal(historyObj)
+	if err != nil {
+		return errors.Wrap(err, "marshal history failed")
+	}
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.HistoryCFNum], key, historyValue)
+
+	return nil
+}
+
+// historyKey는 history column family의 key. 같은 rowKey의 history가 height 순서로 정렬되도록 rowKey 뒤에 height를 붙임.
+func historyKey(rowKey []byte, height int64) []byte {
+	return append(append([]byte{}, rowKey...), uint64Bytes(uint64(height))...)
+}
+
+// deliverTxTags는 tendermint event subscription과 tx_search에서 put tx를 찾을 수 있도록 action, ownerId, qualifier type,
+// 최소/최대 timestamp tag를 return. ownerId, qualifier type tag는 tx에 담긴 값마다 하나씩 추가되며
+// qualifier type은 qualifier가 "type" field를 가진 json object인 경우에만 추가됨.
+// tendermint event는 tag key마다 마지막 값만 담으므로 event subscription을 위해 모든 값을 TagListSeparator로 감싸 이은
+// ownerIds, qualifier types tag를 함께 추가함. 예: |owner1|owner2|
+func deliverTxTags(baseDataObjs []types.BaseDataObj) []cmn.KVPair {
+	tags := []cmn.KVPair{{Key: []byte(consts.ActionTagKey), Value: []byte(consts.PutAction)}}
+	if len(baseDataObjs) == 0 {
+		return tags
+	}
+
+	var ownerIdTags, typeTags []cmn.KVPair
+	ownerIds, qualifierTypes := consts.TagListSeparator, consts.TagListSeparator
+	seen := make(map[string]bool)
+	minTimestamp, maxTimestamp := uint64(math.MaxUint64), uint64(0)
+	for _, baseDataObj := range baseDataObjs {
+		metaDataObj := baseDataObj.MetaData
+		if ownerId := metaDataObj.OwnerId; !seen[consts.OwnerIdTagKey+ownerId] {
+			seen[consts.OwnerIdTagKey+ownerId] = true
+			ownerIdTags = append(ownerIdTags, cmn.KVPair{Key: []byte(consts.OwnerIdTagKey), Value: []byte(ownerId)})
+			ownerIds += ownerId + consts.TagListSeparator
+		}
+
+		var qualifier struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(metaDataObj.Qualifier, &qualifier); err == nil && qualifier.Type != "" && !seen[consts.QualifierTypeTagKey+qualifier.Type] {
+			seen[consts.QualifierTypeTagKey+qualifier.Type] = true
+			typeTags = append(typeTags, cmn.KVPair{Key: []byte(consts.QualifierTypeTagKey), Value: []byte(qualifier.Type)})
+			qualifierTypes += qualifier.Type + consts.TagListSeparator
+		}
+
+		if len(metaDataObj.RowKey) >= 8 {
+			timestamp := binary.BigEndian.Uint64(metaDataObj.RowKey[0:8])
+			if timestamp < minTimestamp {
+				minTimestamp = timestamp
+			}
+			if timestamp > maxTimestamp {
+				maxTimestamp = timestamp
+			}
+		}
+	}
+
+	tags = append(tags, ownerIdTags...)
+	tags = append(tags, typeTags...)
+	tags = append(tags, cmn.KVPair{Key: []byte(consts.OwnerIdsTagKey), Value: []byte(ownerIds)})
+	if len(typeTags) > 0 {
+		tags = append(tags, cmn.KVPair{Key: []byte(consts.QualifierTypesTagKey), Value: []byte(qualifierTypes)})
+	}
+	if minTimestamp <= maxTimestamp {
+		tags = append(tags,
+			cmn.KVPair{Key: []byte(consts.MinTimestampTagKey), Value: []byte(strconv.FormatUint(minTimestamp, 10))},
+			cmn.KVPair{Key: []byte(consts.MaxTimestampTagKey), Value: []byte(strconv.FormatUint(maxTimestamp, 10))})
+	}
+
+	return tags
 }
 
 func (app *MasterApplication) EndBlock(req abciTypes.RequestEndBlock) abciTypes.ResponseEndBlock {
@@ -110,31 +579,125 @@ func (app *MasterApplication) EndBlock(req abciTypes.RequestEndBlock) abciTypes.
 }
 
 func (app *MasterApplication) Commit() (resp abciTypes.ResponseCommit) {
-	//resp.Data = app.hash
-	count, err := app.mwb.Write()
-	if err != nil {
-		app.logger.Error("Error writing batch", "state", "Commit", "err", err)
-		return
-	} else if count > 0 {
-		app.logger.Info("Flush metadata", "state", "Commit", "size", count)
+	app.dbMtx.RLock()
+	defer app.dbMtx.RUnlock()
+	// 빈 app hash가 block에 기록되지 않도록 close 이후의 Commit은 응답하지 않고 멈춤
+	if !app.dbOpen {
+		panic("Commit after db is closed")
 	}
 
-	count, err = app.wb.Write()
+	// 덜 write된 block이 남으면 replay 결과와 app hash가 달라지므로 write에 실패하면 응답하지 않고 멈춤
+	startTime := time.Now()
+	if err := app.setRollups(); err != nil {
+		app.logger.Error("Error writing rollups", "state", "Commit", "err", err)
+		panic(err)
+	}
+	if err := app.setChunks(); err != nil {
+		app.logger.Error("Error writing chunks", "state", "Commit", "err", err)
+		panic(err)
+	}
+
+	// 데이터, merkle tree node, last height를 한 batch로 write하여 block 전체가 write되거나 전혀 write되지 않도록 함
+	app.stateMtx.RLock()
+	height := app.height
+	app.stateMtx.RUnlock()
+	// overwrite된 rowKey는 이전 데이터가 proof로 검증되지 않도록 기존 leaf를 교체함
+	for _, row := range app.blockRows {
+		leaf := types.RowLeaf(row.rowKey, row.metaHash, row.realHash)
+		index, ok, err := rowIndex(app.db, row.rowKey)
+		if err == nil && ok {
+			err = app.tree.replace(app.db, app.wb, index, leaf)
+		} else if err == nil {
+			index = app.tree.append(app.db, app.wb, leaf)
+		}
+		if err != nil {
+			app.logger.Error("Error updating merkle tree", "state", "Commit", "err", err)
+			panic(err)
+		}
+		setRowEntry(app.db, app.wb, row.rowKey, index, row.metaHash, row.realHash)
+	}
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.DefaultCFNum], lastHeightKey, uint64Bytes(uint64(height)))
+	if app.pendingSettings != nil {
+		app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.DefaultCFNum], storageSettingsKey, app.pendingSettings)
+	}
+	count, err := app.wb.Write()
 	if err != nil {
 		app.logger.Error("Error writing batch", "state", "Commit", "err", err)
-		return
-	} else if count > 0 {
-		app.logger.Info("Flush realdata", "state", "Commit", "size", count)
+		panic(err)
 	}
+	if len(app.blockRows) > 0 {
+		app.logger.Info("Flush block", "state", "Commit", "height", height, "size", count)
+	}
+	app.metrics.CommitBatchSize.Observe(float64(count))
+	app.metrics.CommitLatency.Observe(time.Since(startTime).Seconds())
 
-	app.mwb = app.db.NewBatch()
 	app.wb = app.db.NewBatch()
+	app.tree.committed()
+	app.pendingSettings = nil
+	app.blockRows = nil
+	app.blockRowKeys = nil
+	app.blockSeries = nil
+	app.blockChunks = nil
+	app.blockResolutions = nil
+	app.blockRollups = nil
+	app.blockLatest = nil
+	app.updateDBMetrics()
+
+	resp.Data = app.tree.root()
+	app.stateMtx.Lock()
+	app.lastHeight = height
+	app.lastBlockTime = time.Now()
+	app.treeSize = app.tree.size
+	app.appHash = resp.Data
+	app.addSnapshot(height, app.tree.size)
+	app.stateMtx.Unlock()
 
 	return
 }
 
+// updateDBMetrics는 column family별 rocksdb 내부 통계를 metrics에 반영함.
+func (app *MasterApplication) updateDBMetrics() {
+	for i, name := range db.ColumnFamilyNames {
+		app.metrics.MemtableSize.With("column_family", name).Set(app.dbProperty(i, "rocksdb.cur-size-all-mem-tables"))
+		app.metrics.CompactionPending.With("column_family", name).Set(app.dbProperty(i, "rocksdb.compaction-pending"))
+		app.metrics.SSTFilesSize.With("column_family", name).Set(app.dbProperty(i, "rocksdb.total-sst-files-size"))
+	}
+}
+
+func (app *MasterApplication) dbProperty(index int, propName string) float64 {
+	value, err := strconv.ParseFloat(app.db.GetPropertyFromColumnFamily(index, propName), 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
 func (app *MasterApplication) Query(reqQuery abciTypes.RequestQuery) abciTypes.ResponseQuery {
+	defer func(startTime time.Time) {
+		path := reqQuery.Path
+		switch path {
+		case consts.QueryPath, consts.FetchPath, consts.QueryDataPath, consts.HistoryPath, consts.SeriesPath, consts.RangePath, consts.LatestPath, consts.CountPath, consts.QLPath:
+		default:
+			path = "unknown"
+		}
+		app.metrics.QueryLatency.With("path", path).Observe(time.Since(startTime).Seconds())
+	}(time.Now())
+
+	app.dbMtx.RLock()
+	defer app.dbMtx.RUnlock()
+	if !app.dbOpen {
+		return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: "db is closed"}
+	}
+
+	// 요청한 height의 상태를 read함. response의 height와 proof는 read한 상태를 기준으로 함
+	reader, height, treeSize, release, err := app.stateAt(reqQuery.Height)
+	if err != nil {
+		return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: err.Error(), Height: reqQuery.Height}
+	}
+	defer release()
+
 	var responseValue []byte
+	var rowKeys [][]byte
 	switch reqQuery.Path {
 	case consts.QueryPath:
 		var queryObj = types.QueryObj{}
@@ -148,7 +711,7 @@ func (app *MasterApplication) Query(reqQuery abciTypes.RequestQuery) abciTypes.R
 			return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: err.Error()}
 		}
 
-		metaDataObjs, err := app.metaDataQuery(queryObj)
+		metaDataObjs, err := app.metaDataQuery(reader, queryObj)
 		if err != nil {
 			app.logger.Error("Error processing queryObj", "state", "Query", "err", err)
 			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
@@ -158,6 +721,9 @@ func (app *MasterApplication) Query(reqQuery abciTypes.RequestQuery) abciTypes.R
 			app.logger.Error("Error marshaling metaDataObj", "state", "Query", "err", err)
 			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
 		}
+		for _, metaDataObj := range metaDataObjs {
+			rowKeys = append(rowKeys, metaDataObj.RowKey)
+		}
 		app.logger.Info("Query success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
 
 	case consts.FetchPath:
@@ -167,7 +733,7 @@ func (app *MasterApplication) Query(reqQuery abciTypes.RequestQuery) abciTypes.R
 			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
 		}
 
-		realDataObjs, err := app.realDataFetch(fetchObj)
+		realDataObjs, err := app.realDataFetch(reader, fetchObj)
 		if err != nil {
 			app.logger.Error("Error processing fetchObj", "state", "Query", "err", err)
 			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
@@ -177,91 +743,363 @@ func (app *MasterApplication) Query(reqQuery abciTypes.RequestQuery) abciTypes.R
 			app.logger.Error("Error marshaling realDataObj", "state", "Query", "err", err)
 			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
 		}
+		rowKeys = fetchObj.RowKeys
 		app.logger.Info("Fetch success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
 
+	case consts.QueryDataPath:
+		var queryDataObj = types.QueryDataObj{}
+		if err := json.Unmarshal(reqQuery.Data, &queryDataObj); err != nil {
+			app.logger.Error("Error unmarshaling QueryDataObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+
+		if queryDataObj.Start >= queryDataObj.End {
+			err := errors.New("query end must be greater than start ")
+			return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+
+		queryDataResObj, err := app.queryData(reader, queryDataObj)
+		if err != nil {
+			app.logger.Error("Error processing queryDataObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		responseValue, err = json.Marshal(queryDataResObj)
+		if err != nil {
+			app.logger.Error("Error marshaling queryDataResObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		for _, baseDataObj := range queryDataResObj.Data {
+			rowKeys = append(rowKeys, baseDataObj.MetaData.RowKey)
+		}
+		app.logger.Info("QueryData success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
+
+	case consts.HistoryPath:
+		// history는 merkle tree에 포함되지 않으므로 proof를 제공하지 않음
+		var fetchObj = types.FetchObj{}
+		if err := json.Unmarshal(reqQuery.Data, &fetchObj); err != nil {
+			app.logger.Error("Error unmarshaling FetchObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+
+		historyObjs, err := app.historyFetch(reader, fetchObj)
+		if err != nil {
+			app.logger.Error("Error processing fetchObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		responseValue, err = json.Marshal(historyObjs)
+		if err != nil {
+			app.logger.Error("Error marshaling historyObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		app.logger.Info("History success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
+
+	case consts.SeriesPath:
+		var seriesQueryObj = types.SeriesQueryObj{}
+		if err := json.Unmarshal(reqQuery.Data, &seriesQueryObj); err != nil {
+			app.logger.Error("Error unmarshaling SeriesQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+
+		seriesObjs, err := app.seriesQuery(reader, seriesQueryObj)
+		if err != nil {
+			app.logger.Error("Error processing seriesQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		responseValue, err = json.Marshal(seriesObjs)
+		if err != nil {
+			app.logger.Error("Error marshaling seriesObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		app.logger.Info("Series success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
+
+	case consts.CountPath:
+		var queryObj = types.QueryObj{}
+		if err := json.Unmarshal(reqQuery.Data, &queryObj); err != nil {
+			app.logger.Error("Error unmarshaling QueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+
+		if queryObj.Start >= queryObj.End {
+			err := errors.New("query end must be greater than start ")
+			return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+
+		countResObj, err := app.countQuery(reader, queryObj)
+		if err != nil {
+			app.logger.Error("Error processing queryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		responseValue, err = json.Marshal(countResObj)
+		if err != nil {
+			app.logger.Error("Error marshaling countResObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		app.logger.Info("Count success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
+
+	case consts.LatestPath:
+		var latestQueryObj = types.LatestQueryObj{}
+		if err := json.Unmarshal(reqQuery.Data, &latestQueryObj); err != nil {
+			app.logger.Error("Error unmarshaling LatestQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+
+		baseDataObjs, err := app.latestQuery(reader, latestQueryObj)
+		if err != nil {
+			app.logger.Error("Error processing latestQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		responseValue, err = json.Marshal(baseDataObjs)
+		if err != nil {
+			app.logger.Error("Error marshaling baseDataObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		for _, baseDataObj := range baseDataObjs {
+			rowKeys = append(rowKeys, baseDataObj.MetaData.RowKey)
+		}
+		app.logger.Info("Latest success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
+
+	case consts.QLPath:
+		var qlQueryObj = types.QLQueryObj{}
+		if err := json.Unmarshal(reqQuery.Data, &qlQueryObj); err != nil {
+			app.logger.Error("Error unmarshaling QLQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+
+		qlResObj, err := app.qlQuery(reader, qlQueryObj)
+		if err != nil {
+			app.logger.Error("Error processing qlQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		responseValue, err = json.Marshal(qlResObj)
+		if err != nil {
+			app.logger.Error("Error marshaling qlResObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		for _, baseDataObj := range qlResObj.Data {
+			rowKeys = append(rowKeys, baseDataObj.MetaData.RowKey)
+		}
+		app.logger.Info("QL success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
+
+	case consts.RangePath:
+		var rangeQueryObj = types.RangeQueryObj{}
+		if err := json.Unmarshal(reqQuery.Data, &rangeQueryObj); err != nil {
+			app.logger.Error("Error unmarshaling RangeQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+
+		if rangeQueryObj.Start >= rangeQueryObj.End {
+			err := errors.New("query end must be greater than start ")
+			return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+
+		rangeResObjs, err := app.rangeQuery(reader, rangeQueryObj)
+		if err != nil {
+			app.logger.Error("Error processing rangeQueryObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+		responseValue, err = json.Marshal(rangeResObjs)
+		if err != nil {
+			app.logger.Error("Error marshaling rangeResObj", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeEncodingError, Log: err.Error()}
+		}
+		app.logger.Info("Range success", "state", "Query", "path", reqQuery.Path, "data", reqQuery.Data)
+
+	}
+
+	resQuery := abciTypes.ResponseQuery{Code: code.CodeTypeOK, Value: responseValue, Height: height}
+	if reqQuery.Prove {
+		proof, err := app.rowProofs(reader, rowKeys, treeSize)
+		if err != nil {
+			app.logger.Error("Error making proof", "state", "Query", "err", err)
+			return abciTypes.ResponseQuery{Code: code.CodeTypeUnknownError, Log: err.Error()}
+		}
+		resQuery.Proof = proof
 	}
 
-	return abciTypes.ResponseQuery{Code: code.CodeTypeOK, Value: responseValue}
+	return resQuery
 }
 
-func (app *MasterApplication) metaDataQuery(queryObj types.QueryObj) ([]types.MetaDataObj, error) {
-	var rawMetaDataObjs []types.MetaDataObj
-	var metaDataObjs []types.MetaDataObj
+// rowProofs는 rowKeys 각각의 RowProof를 ProofOp로 담은 merkle.Proof를 return.
+// proof가 없는 row(merkle tree 도입 이전 데이터, treeSize 이후에 write된 데이터)는 제외됨.
+func (app *MasterApplication) rowProofs(reader db.Reader, rowKeys [][]byte, treeSize uint64) (*merkle.Proof, error) {
+	proof := &merkle.Proof{}
+	for _, rowKey := range rowKeys {
+		proofObj, ok, err := rowProof(reader, rowKey, treeSize)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
 
-	// query field nil error 처리
-	if queryObj.Qualifier == nil {
-		return nil, errors.Errorf("Qualifier must not be nil")
+		data, err := json.Marshal(proofObj)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal proof failed")
+		}
+		proof.Ops = append(proof.Ops, merkle.ProofOp{Type: consts.RowProofOpType, Key: rowKey, Data: data})
 	}
 
-	if len(queryObj.OwnerId) > consts.OwnerIdLenLimit {
-		return nil, errors.Errorf("OwnerId must be %v or below", consts.OwnerIdLenLimit)
+	return proof, nil
+}
+
+func (app *MasterApplication) metaDataQuery(reader db.Reader, queryObj types.QueryObj) ([]types.MetaDataObj, error) {
+	metaDataObjs, _, err := app.metaDataScan(reader, queryObj, nil, 0)
+	return metaDataObjs, err
+}
+
+// metaDataScan은 reader에서 queryObj의 time range에 있고 OwnerId, Qualifier 조건에 맞는 metadata를 read함.
+// after가 주어지면 after rowKey 다음부터 read하며 limit이 0보다 크면 최대 limit개만 read함.
+// limit에 도달한 뒤에도 range 안에 데이터가 남아 있으면 마지막으로 read한 rowKey를 next로 return.
+func (app *MasterApplication) metaDataScan(reader db.Reader, queryObj types.QueryObj, after []byte, limit int) (metaDataObjs []types.MetaDataObj, next []byte, err error) {
+	// query field nil error 처리. Qualifiers로 qualifier 조건을 준 경우에는 Qualifier를 생략할 수 있음
+	if queryObj.Qualifier == nil && len(queryObj.Qualifiers) == 0 {
+		return nil, nil, errors.Errorf("Qualifier must not be nil")
 	}
 
-	// create start and end for iterator
-	salt := uint16(0)
+	filter, err := newMetaDataFilter(queryObj)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	startByte := types.GetRowKey(queryObj.Start, salt)
-	endByte := types.GetRowKey(queryObj.End, salt)
+	// create start and end for iterator. rowKey 형식과 관계없이 timestamp prefix로 range를 정함
+	startByte := types.TimestampKey(queryObj.Start)
+	endByte := types.TimestampKey(queryObj.End)
+	if after != nil && bytes.Compare(after, startByte) >= 0 {
+		startByte = after
+	}
 
-	itr := app.db.IteratorColumnFamily(startByte, endByte, app.db.ColumnFamilyHandles()[consts.MetaCFNum])
+	itr := reader.IteratorColumnFamily(startByte, endByte, reader.ColumnFamilyHandles()[consts.MetaCFNum])
 	defer itr.Close()
 
-	// time range에 해당하는 모든 데이터를 가져온다
+	// time range에 해당하는 데이터 중 제한사항에 맞는 데이터를 가져온다
+	scanned := 0
+	resolver := newSeriesResolver(reader)
 	for itr.Seek(startByte); itr.Valid() && bytes.Compare(itr.Key(), endByte) == -1; itr.Next() {
+		if after != nil && bytes.Equal(itr.Key(), after) {
+			continue
+		}
+		if limit > 0 && len(metaDataObjs) == limit {
+			next = metaDataObjs[len(metaDataObjs)-1].RowKey
+			break
+		}
+
 		var metaObj = types.MetaDataObj{}
 
-		var metaValue struct {
-			OwnerId   string `json:"ownerId"`
-			Qualifier []byte `json:"qualifier"`
-		}
-		if err := json.Unmarshal(itr.Value(), &metaValue); err != nil {
-			return nil, errors.Wrap(err, "metaValue unmarshal err")
+		ownerId, qualifier, err := resolver.resolve(itr.Value())
+		if err != nil {
+			return nil, nil, err
 		}
+		scanned++
 
 		metaObj.RowKey = make([]byte, len(itr.Key()))
 		copy(metaObj.RowKey, itr.Key())
-		metaObj.OwnerId = metaValue.OwnerId
-		metaObj.Qualifier = metaValue.Qualifier
+		metaObj.OwnerId = ownerId
+		metaObj.Qualifier = qualifier
+
+		if filter.match(ownerId, qualifier) {
+			metaDataObjs = append(metaDataObjs, metaObj)
+		}
+	}
+	app.metrics.RowsScanned.Add(float64(scanned))
+	app.metrics.RowsReturned.Add(float64(len(metaDataObjs)))
 
-		rawMetaDataObjs = append(rawMetaDataObjs, metaObj)
+	return metaDataObjs, next, nil
+}
+
+// metaDataFilter는 QueryObj의 ownerId, qualifier 조건을 scan 전에 한 번 준비하여 row마다 set lookup으로 검사함.
+type metaDataFilter struct {
+	owners        map[string]struct{}
+	ownerIdPrefix string
+	qualifiers    map[string]struct{}
+}
 
+// newMetaDataFilter는 queryObj의 ownerId 길이를 검사하고 metaDataFilter를 return.
+func newMetaDataFilter(queryObj types.QueryObj) (*metaDataFilter, error) {
+	filter := &metaDataFilter{ownerIdPrefix: queryObj.OwnerIdPrefix}
+	if len(queryObj.OwnerIdPrefix) > consts.OwnerIdLenLimit {
+		return nil, errors.Errorf("OwnerIdPrefix must be %v or below", consts.OwnerIdLenLimit)
 	}
 
-	// 가져온 데이터를 제한사항에 맞게 거른다
-	switch {
-	case strings.Compare(queryObj.OwnerId, "") == 0 && len(queryObj.Qualifier) == 0:
-		metaDataObjs = rawMetaDataObjs
-	case strings.Compare(queryObj.OwnerId, "") == 0:
-		for i, metaObj := range rawMetaDataObjs {
-			if bytes.Compare(metaObj.Qualifier, queryObj.Qualifier) == 0 {
-				metaDataObjs = append(metaDataObjs, rawMetaDataObjs[i])
-			}
+	ownerIds := queryObj.OwnerIds
+	if queryObj.OwnerId != "" {
+		ownerIds = append([]string{queryObj.OwnerId}, ownerIds...)
+	}
+	for _, ownerId := range ownerIds {
+		if len(ownerId) > consts.OwnerIdLenLimit {
+			return nil, errors.Errorf("OwnerId must be %v or below", consts.OwnerIdLenLimit)
 		}
-	case len(queryObj.Qualifier) == 0:
-		for i, metaObj := range rawMetaDataObjs {
-			if strings.Compare(metaObj.OwnerId, queryObj.OwnerId) == 0 {
-				metaDataObjs = append(metaDataObjs, rawMetaDataObjs[i])
-			}
+		if filter.owners == nil {
+			filter.owners = make(map[string]struct{})
 		}
-	default:
-		for i, metaObj := range rawMetaDataObjs {
-			if bytes.Compare(metaObj.Qualifier, queryObj.Qualifier) == 0 && strings.Compare(metaObj.OwnerId, queryObj.OwnerId) == 0 {
-				metaDataObjs = append(metaDataObjs, rawMetaDataObjs[i])
-			}
+		filter.owners[ownerId] = struct{}{}
+	}
+
+	qualifiers := queryObj.Qualifiers
+	if len(queryObj.Qualifier) != 0 {
+		qualifiers = append([][]byte{queryObj.Qualifier}, qualifiers...)
+	}
+	for _, qualifier := range qualifiers {
+		if filter.qualifiers == nil {
+			filter.qualifiers = make(map[string]struct{})
 		}
+		filter.qualifiers[string(qualifier)] = struct{}{}
 	}
-	return metaDataObjs, nil
 
+	return filter, nil
+}
+
+// match는 ownerId, qualifier가 filter의 조건에 맞는지 return. 빈 조건은 모든 값과 일치함.
+func (filter *metaDataFilter) match(ownerId string, qualifier []byte) bool {
+	if filter.owners != nil {
+		if _, ok := filter.owners[ownerId]; !ok {
+			return false
+		}
+	}
+	if !strings.HasPrefix(ownerId, filter.ownerIdPrefix) {
+		return false
+	}
+	if filter.qualifiers != nil {
+		if _, ok := filter.qualifiers[string(qualifier)]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
-func (app *MasterApplication) realDataFetch(fetchObj types.FetchObj) ([]types.RealDataObj, error) {
+// queryData는 queryDataObj 조건에 맞는 metadata와 실제 데이터를 함께 read함.
+func (app *MasterApplication) queryData(reader db.Reader, queryDataObj types.QueryDataObj) (types.QueryDataResObj, error) {
+	var queryDataResObj types.QueryDataResObj
+
+	metaDataObjs, next, err := app.metaDataScan(reader, queryDataObj.QueryObj, queryDataObj.After, queryDataObj.Limit)
+	if err != nil {
+		return queryDataResObj, err
+	}
+
+	var fetchObj types.FetchObj
+	for _, metaDataObj := range metaDataObjs {
+		fetchObj.RowKeys = append(fetchObj.RowKeys, metaDataObj.RowKey)
+	}
+	realDataObjs, err := app.realDataFetch(reader, fetchObj)
+	if err != nil {
+		return queryDataResObj, err
+	}
+
+	for i := range metaDataObjs {
+		queryDataResObj.Data = append(queryDataResObj.Data, types.BaseDataObj{MetaData: metaDataObjs[i], RealData: realDataObjs[i]})
+	}
+	queryDataResObj.Next = next
+
+	return queryDataResObj, nil
+}
+
+func (app *MasterApplication) realDataFetch(reader db.Reader, fetchObj types.FetchObj) ([]types.RealDataObj, error) {
 	var realDataObjs []types.RealDataObj
 
 	for _, rowKey := range fetchObj.RowKeys {
 		var realDataObj types.RealDataObj
 
 		realDataObj.RowKey = rowKey
-		valueSlice, err := app.db.GetDataFromColumnFamily(consts.RealCFNum, rowKey)
+		valueSlice, err := reader.GetDataFromColumnFamily(consts.RealCFNum, rowKey)
 		if err != nil {
 			return nil, errors.Wrap(err, "GetDataFromColumnFamily err")
 		}
@@ -276,6 +1114,74 @@ func (app *MasterApplication) realDataFetch(fetchObj types.FetchObj) ([]types.Re
 	return realDataObjs, nil
 }
 
+// historyFetch는 fetchObj의 rowKey마다 overwrite로 대체된 이전 데이터를 대체된 height 순서로 read함.
+func (app *MasterApplication) historyFetch(reader db.Reader, fetchObj types.FetchObj) ([]types.HistoryObj, error) {
+	var historyObjs []types.HistoryObj
+
+	for _, rowKey := range fetchObj.RowKeys {
+		if err := func() error {
+			itr := reader.IteratorColumnFamily(rowKey, nil, reader.ColumnFamilyHandles()[consts.HistoryCFNum])
+			defer itr.Close()
+
+			// legacy rowKey가 다른 rowKey의 prefix일 수 있으므로 key 길이도 확인함
+			for ; itr.Valid() && bytes.HasPrefix(itr.Key(), rowKey); itr.Next() {
+				if len(itr.Key()) != len(rowKey)+consts.TimestampLen {
+					continue
+				}
+				var historyObj types.HistoryObj
+				if err := json.Unmarshal(itr.Value(), &historyObj); err != nil {
+					return errors.Wrap(err, "historyObj unmarshal err")
+				}
+				historyObjs = append(historyObjs, historyObj)
+			}
+			return nil
+		}(); err != nil {
+			return nil, err
+		}
+	}
+
+	return historyObjs, nil
+}
+
+// Shutdown은 처리 중인 ABCI 요청이 끝날 때까지 기다린 뒤 DB를 close함.
+// 호출 전에 ABCI server나 tendermint node를 먼저 멈춰 새 block이 시작되지 않도록 해야 함.
+// commit되지 않은 block의 batch는 버리며 재시작 시 tendermint handshake에서 다시 replay됨.
+// ctx가 먼저 만료되면 error를 return하며 DB는 처리 중인 요청이 끝나는 대로 close됨.
+func (app *MasterApplication) Shutdown(ctx context.Context) error {
+	closed := make(chan struct{})
+	go func() {
+		app.Destroy()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "in-flight ABCI request did not finish before shutdown")
+	}
+
+	app.stateMtx.RLock()
+	height, lastHeight := app.height, app.lastHeight
+	app.stateMtx.RUnlock()
+	if height > lastHeight {
+		app.logger.Info("Dropped uncommitted block", "state", "Shutdown", "height", height)
+	}
+	app.logger.Info("DB closed", "state", "Shutdown")
+	return nil
+}
+
+// Destroy는 DB를 close함. 여러 번 호출해도 안전함.
+// close 이후의 CheckTx, Query는 error를 return하고 DeliverTx, Commit은 panic.
 func (app *MasterApplication) Destroy() {
+	app.dbMtx.Lock()
+	defer app.dbMtx.Unlock()
+	app.stateMtx.Lock()
+	defer app.stateMtx.Unlock()
+
+	if !app.dbOpen {
+		return
+	}
+	app.dbOpen = false
+	app.releaseSnapshots()
 	app.db.Close()
 }
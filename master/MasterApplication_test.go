@@ -1,10 +1,15 @@
 package master_test
 
 import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
 	"github.com/paust-team/paust-db/libs/log"
 	"github.com/paust-team/paust-db/master"
 	"github.com/paust-team/paust-db/types"
 	"github.com/stretchr/testify/suite"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
 	"os"
 	"testing"
 )
@@ -72,6 +77,51 @@ func (suite *MasterSuite) TearDownTest() {
 	suite.app.Destroy()
 }
 
+// givenDataObj는 timestamp와 내용으로 rowKey를 만든 test data를 return.
+func givenDataObj(timestamp uint64, ownerId string, qualifier string, data string) types.BaseDataObj {
+	rowKey := types.NewRowKey(timestamp, ownerId, []byte(qualifier), []byte(data))
+	return types.BaseDataObj{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: ownerId, Qualifier: []byte(qualifier)}, RealData: types.RealDataObj{RowKey: rowKey, Data: []byte(data)}}
+}
+
+// openApp은 app을 닫고 configure로 바꾼 config로 testDir의 app을 다시 생성함.
+func (suite *MasterSuite) openApp(configure func(cfg *config.Config)) {
+	err := suite.reopenApp(configure)
+	suite.Require().Nil(err, "err: %+v", err)
+}
+
+// reopenApp은 openApp과 같으나 app 생성 err를 return. 생성에 실패하면 suite.app은 닫힌 이전 app으로 남음.
+func (suite *MasterSuite) reopenApp(configure func(cfg *config.Config)) error {
+	suite.app.Destroy()
+	cfg := config.DefaultConfig().SetRoot(testDir)
+	configure(cfg)
+	app, err := master.NewMasterApplicationWithConfig(cfg, log.AllowDebug())
+	if err != nil {
+		return err
+	}
+	suite.app = app
+	return nil
+}
+
+// deliver는 baseDataObjs를 하나의 tx로 DeliverTx하고 성공했는지 확인함.
+func (suite *MasterSuite) deliver(baseDataObjs ...types.BaseDataObj) {
+	tx, err := json.Marshal(baseDataObjs)
+	suite.Require().Nil(err)
+	res := suite.app.DeliverTx(tx)
+	suite.Require().Equal(code.CodeTypeOK, res.Code, res.Log)
+}
+
+// queryRange는 rangeQueryObj로 range query한 결과를 return.
+func (suite *MasterSuite) queryRange(rangeQueryObj types.RangeQueryObj) []types.RangeResObj {
+	require := suite.Require()
+	rangeData, err := json.Marshal(rangeQueryObj)
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: rangeData, Path: consts.RangePath})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	var rangeResObjs []types.RangeResObj
+	require.Nil(json.Unmarshal(res.Value, &rangeResObjs))
+	return rangeResObjs
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, new(MasterSuite))
 }
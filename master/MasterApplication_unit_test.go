@@ -7,6 +7,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tendermint/abci/example/code"
 	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	cmn "github.com/tendermint/tendermint/libs/common"
 )
 
 func (suite *MasterSuite) TestMasterApplication_Info() {
@@ -92,6 +94,113 @@ func (suite *MasterSuite) TestMasterApplication_DeliverTx() {
 
 	//then
 	suite.Equal(code.CodeTypeOK, actualRes.Code)
+	suite.Equal([]cmn.KVPair{
+		{Key: []byte(consts.ActionTagKey), Value: []byte(consts.PutAction)},
+		{Key: []byte(consts.OwnerIdTagKey), Value: []byte(TestOwnerId)},
+		{Key: []byte(consts.OwnerIdTagKey), Value: []byte(TestOwnerId2)},
+		{Key: []byte(consts.OwnerIdsTagKey), Value: []byte("|" + TestOwnerId + "|" + TestOwnerId2 + "|")},
+		{Key: []byte(consts.MinTimestampTagKey), Value: []byte("1545982882435375000")},
+		{Key: []byte(consts.MaxTimestampTagKey), Value: []byte("1545982882435375001")},
+	}, actualRes.Tags)
+}
+
+func (suite *MasterSuite) TestMasterApplication_DeliverTx_duplicateRowKey() {
+	require := require.New(suite.T())
+
+	//given
+	suite.TestMasterApplication_InitChain()
+	givenTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj1})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+
+	conflictObj1 := givenBaseDataObj1
+	conflictObj1.RealData.Data = []byte("conflict")
+	conflictObj2 := givenBaseDataObj2
+	conflictObj2.RealData.Data = []byte("conflict")
+	conflictTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj2, conflictObj1})
+	require.Nil(err)
+	sameTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj2, conflictObj2})
+	require.Nil(err)
+
+	//when
+	// 같은 block, tx 안, commit된 rowKey에 다른 데이터를 write하면 모두 거부됨
+	sameBlockRes := suite.app.DeliverTx(conflictTx)
+	sameTxRes := suite.app.DeliverTx(sameTx)
+	suite.app.Commit()
+	committedCheckRes := suite.app.CheckTx(conflictTx)
+	committedDeliverRes := suite.app.DeliverTx(conflictTx)
+
+	//then
+	require.Equal(consts.CodeTypeDuplicateRowKey, sameBlockRes.Code)
+	require.Equal(consts.CodeTypeDuplicateRowKey, sameTxRes.Code)
+	require.Equal(consts.CodeTypeDuplicateRowKey, committedCheckRes.Code)
+	require.Equal(consts.CodeTypeDuplicateRowKey, committedDeliverRes.Code)
+
+	// 거부된 tx의 데이터는 write되지 않음
+	fetchData, err := json.Marshal(types.FetchObj{RowKeys: [][]byte{givenBaseDataObj2.MetaData.RowKey}})
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: fetchData, Path: consts.FetchPath})
+	var realDataObjs []types.RealDataObj
+	require.Nil(json.Unmarshal(res.Value, &realDataObjs))
+	require.Equal(0, len(realDataObjs[0].Data))
+
+	// 같은 데이터의 재전송은 거부하지 않으며 다시 write하지 않음
+	require.Equal(code.CodeTypeOK, suite.app.CheckTx(givenTx).Code)
+	duplicateRes := suite.app.DeliverTx(givenTx)
+	require.Equal(code.CodeTypeOK, duplicateRes.Code, duplicateRes.Log)
+	require.Equal("1 duplicate data skipped", duplicateRes.Info)
+	require.Equal(1, len(duplicateRes.Tags))
+
+	duplicateTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj2, givenBaseDataObj1, givenBaseDataObj2})
+	require.Nil(err)
+	duplicateRes = suite.app.DeliverTx(duplicateTx)
+	require.Equal(code.CodeTypeOK, duplicateRes.Code, duplicateRes.Log)
+	require.Equal("2 duplicate data skipped", duplicateRes.Info)
+	duplicateRes = suite.app.DeliverTx(duplicateTx)
+	require.Equal(code.CodeTypeOK, duplicateRes.Code, duplicateRes.Log)
+	require.Equal("3 duplicate data skipped", duplicateRes.Info)
+	suite.app.Commit()
+
+	res = suite.app.Query(abciTypes.RequestQuery{Data: fetchData, Path: consts.FetchPath})
+	require.Nil(json.Unmarshal(res.Value, &realDataObjs))
+	require.Equal([]types.RealDataObj{givenRealDataObj2}, realDataObjs)
+
+	// metadata와 realdata의 rowKey가 다르면 거부됨
+	wrongBaseDataObj := givenBaseDataObj2
+	wrongBaseDataObj.RealData.RowKey = types.GetRowKey(1545982882435375009, 0)
+	wrongTx, err := json.Marshal([]types.BaseDataObj{wrongBaseDataObj})
+	require.Nil(err)
+	require.Equal(consts.CodeTypeInvalidRowKey, suite.app.DeliverTx(wrongTx).Code)
+}
+
+func (suite *MasterSuite) TestMasterApplication_DeliverTx_qualifierTypeTag() {
+	require := require.New(suite.T())
+
+	//given
+	suite.TestMasterApplication_InitChain()
+	givenBaseDataObj := givenBaseDataObj1
+	givenBaseDataObj.MetaData.Qualifier = []byte(`{"type":"speed"}`)
+	// 같은 qualifier type의 데이터가 여러 개여도 tag는 하나만 추가됨
+	otherBaseDataObj := givenBaseDataObj
+	otherRowKey := types.GetRowKey(1545982882435375000, 1)
+	otherBaseDataObj.MetaData.RowKey, otherBaseDataObj.RealData.RowKey = otherRowKey, otherRowKey
+	givenTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj, otherBaseDataObj})
+	require.Nil(err)
+
+	//when
+	actualRes := suite.app.DeliverTx(givenTx)
+
+	//then
+	require.Equal(code.CodeTypeOK, actualRes.Code)
+	require.Equal([]cmn.KVPair{
+		{Key: []byte(consts.ActionTagKey), Value: []byte(consts.PutAction)},
+		{Key: []byte(consts.OwnerIdTagKey), Value: []byte(TestOwnerId)},
+		{Key: []byte(consts.QualifierTypeTagKey), Value: []byte("speed")},
+		{Key: []byte(consts.OwnerIdsTagKey), Value: []byte("|" + TestOwnerId + "|")},
+		{Key: []byte(consts.QualifierTypesTagKey), Value: []byte("|speed|")},
+		{Key: []byte(consts.MinTimestampTagKey), Value: []byte("1545982882435375000")},
+		{Key: []byte(consts.MaxTimestampTagKey), Value: []byte("1545982882435375000")},
+	}, actualRes.Tags)
 
 }
 
@@ -115,8 +224,13 @@ func (suite *MasterSuite) TestMasterApplication_Commit() {
 	actualRes := suite.app.Commit()
 
 	//then
-	//TODO app hash test
-	expectRes := abciTypes.ResponseCommit{}
+	var leaves [][]byte
+	for _, baseDataObj := range givenBaseDataObjs {
+		metaHash := types.MetaDataHash(baseDataObj.MetaData.OwnerId, baseDataObj.MetaData.Qualifier)
+		realHash := types.RealDataHash(baseDataObj.RealData.Data)
+		leaves = append(leaves, types.RowLeaf(baseDataObj.MetaData.RowKey, metaHash, realHash))
+	}
+	expectRes := abciTypes.ResponseCommit{Data: merkle.SimpleHashFromByteSlices(leaves)}
 	suite.Equal(expectRes, actualRes)
 }
 
@@ -371,3 +485,54 @@ func (suite *MasterSuite) TestMasterApplication_both_Query() {
 	suite.Equal(expectRealRes, actualRealRes)
 
 }
+
+func (suite *MasterSuite) TestMasterApplication_QueryData() {
+	require := suite.Require()
+	//given
+	suite.TestMasterApplication_Commit()
+	start := uint64(1545982882435375000)
+	end := uint64(1545982882435375002)
+
+	/*
+		chunk 없이 전체
+	*/
+	//when
+	queryDataObj := types.QueryDataObj{QueryObj: types.QueryObj{Start: start, End: end, Qualifier: []byte{}}}
+	queryDataByteArr, err := json.Marshal(queryDataObj)
+	require.Nil(err)
+	actualRes := suite.app.Query(abciTypes.RequestQuery{Data: queryDataByteArr, Path: consts.QueryDataPath})
+
+	//then
+	require.Equal(code.CodeTypeOK, actualRes.Code)
+	var actualResObj types.QueryDataResObj
+	require.Nil(json.Unmarshal(actualRes.Value, &actualResObj))
+	require.Equal(types.QueryDataResObj{Data: []types.BaseDataObj{givenBaseDataObj1, givenBaseDataObj2}}, actualResObj)
+
+	/*
+		첫 번째 chunk
+	*/
+	//when
+	queryDataObj.Limit = 1
+	queryDataByteArr, err = json.Marshal(queryDataObj)
+	require.Nil(err)
+	actualRes = suite.app.Query(abciTypes.RequestQuery{Data: queryDataByteArr, Path: consts.QueryDataPath})
+
+	//then
+	actualResObj = types.QueryDataResObj{}
+	require.Nil(json.Unmarshal(actualRes.Value, &actualResObj))
+	require.Equal(types.QueryDataResObj{Data: []types.BaseDataObj{givenBaseDataObj1}, Next: givenRowKey1}, actualResObj)
+
+	/*
+		두 번째 chunk
+	*/
+	//when
+	queryDataObj.After = actualResObj.Next
+	queryDataByteArr, err = json.Marshal(queryDataObj)
+	require.Nil(err)
+	actualRes = suite.app.Query(abciTypes.RequestQuery{Data: queryDataByteArr, Path: consts.QueryDataPath})
+
+	//then
+	actualResObj = types.QueryDataResObj{}
+	require.Nil(json.Unmarshal(actualRes.Value, &actualResObj))
+	require.Equal(types.QueryDataResObj{Data: []types.BaseDataObj{givenBaseDataObj2}}, actualResObj)
+}
@@ -0,0 +1,267 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/paust-team/paust-db/libs/tsz"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// numericValue는 data가 숫자의 10진수 표현(e.g. "21.5")이면 그 값을 return. NaN, Inf는 숫자로 보지 않음.
+func numericValue(data []byte) (float64, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(string(data), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// addChunkPoint는 metaDataObj의 timestamp와 숫자 data를 series의 chunk에 추가하고 해당 rollup bucket을 다시 집계하도록 표시함.
+// 숫자가 아닌 data는 무시함.
+func (app *MasterApplication) addChunkPoint(seriesId uint64, metaDataObj types.MetaDataObj, data []byte) error {
+	value, ok := numericValue(data)
+	if !ok {
+		return nil
+	}
+
+	timestamp := binary.BigEndian.Uint64(metaDataObj.RowKey[0:consts.TimestampLen])
+	key := app.chunkKey(seriesId, timestamp)
+	points, err := app.blockChunk(key)
+	if err != nil {
+		return err
+	}
+
+	// 같은 timestamp의 point는 write된 순서를 유지함
+	i := sort.Search(len(points), func(i int) bool { return points[i].T > timestamp })
+	points = append(points, tsz.Point{})
+	copy(points[i+1:], points[i:])
+	points[i] = tsz.Point{T: timestamp, V: value}
+	app.blockChunks[string(key)] = points
+
+	return app.markRollups(seriesId, metaDataObj.OwnerId, metaDataObj.Qualifier, timestamp)
+}
+
+// removeChunkPoint는 overwrite로 대체되는 ownerId, qualifier series의 rowKey 데이터를 chunk에서 제거하고 해당 rollup bucket을 다시 집계하도록 표시함.
+func (app *MasterApplication) removeChunkPoint(ownerId string, qualifier []byte, rowKey []byte, data []byte) error {
+	value, ok := numericValue(data)
+	if !ok {
+		return nil
+	}
+	// series 도입 이전 데이터는 chunk에 없음
+	seriesId, err := app.lookupSeriesId(seriesKey(ownerId, qualifier))
+	if err != nil || seriesId == 0 {
+		return err
+	}
+
+	timestamp := binary.BigEndian.Uint64(rowKey[0:consts.TimestampLen])
+	key := app.chunkKey(seriesId, timestamp)
+	points, err := app.blockChunk(key)
+	if err != nil {
+		return err
+	}
+	for i, point := range points {
+		if point.T == timestamp && math.Float64bits(point.V) == math.Float64bits(value) {
+			app.blockChunks[string(key)] = append(points[:i], points[i+1:]...)
+			return app.markRollups(seriesId, ownerId, qualifier, timestamp)
+		}
+	}
+
+	return nil
+}
+
+// blockChunk는 현재 block에서 수정 중인 chunk의 point를 return. 처음 수정하는 chunk이면 DB에서 read함.
+func (app *MasterApplication) blockChunk(key []byte) ([]tsz.Point, error) {
+	if points, ok := app.blockChunks[string(key)]; ok {
+		return points, nil
+	}
+
+	slice, err := app.db.GetDataFromColumnFamily(consts.ChunkCFNum, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	var points []tsz.Point
+	if slice.Exists() {
+		if points, err = tsz.Decode(slice.Data()); err != nil {
+			return nil, errors.Wrapf(err, "decode chunk %X failed", key)
+		}
+	}
+
+	if app.blockChunks == nil {
+		app.blockChunks = make(map[string][]tsz.Point)
+	}
+	app.blockChunks[string(key)] = points
+	return points, nil
+}
+
+// setChunks는 현재 block에서 수정된 chunk를 압축하여 block batch에 담음. Commit에서 block batch를 write하기 전에 호출해야 함.
+func (app *MasterApplication) setChunks() error {
+	if len(app.blockChunks) == 0 {
+		return nil
+	}
+
+	for key, points := range app.blockChunks {
+		value, err := tsz.Encode(points)
+		if err != nil {
+			return errors.Wrapf(err, "encode chunk %X failed", key)
+		}
+		app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.ChunkCFNum], []byte(key), value)
+	}
+
+	return nil
+}
+
+// chunkKey는 series id와 timestamp가 속한 chunk의 시작 timestamp로 이루어진 chunk column family key.
+func (app *MasterApplication) chunkKey(seriesId uint64, timestamp uint64) []byte {
+	return append(uint64Bytes(seriesId), uint64Bytes(timestamp-timestamp%app.chunkInterval)...)
+}
+
+// rangeQuery는 rangeQueryObj 조건에 맞는 series마다 time range의 숫자 데이터를 chunk에서 read함.
+// rangeQueryObj.Step이 0보다 크면 Start부터 Step 간격의 bucket으로 집계하며 데이터가 없는 series와 bucket은 제외함.
+// 집계에는 Start, End, Step을 모두 나누는 가장 큰 rollup resolution을 사용하며 그런 rollup이 없으면 원본 point를 집계함.
+func (app *MasterApplication) rangeQuery(reader db.Reader, rangeQueryObj types.RangeQueryObj) ([]types.RangeResObj, error) {
+	if !app.chunkStorage {
+		return nil, errors.New("chunk storage is disabled")
+	}
+
+	seriesObjs, err := app.seriesQuery(reader, types.SeriesQueryObj{OwnerId: rangeQueryObj.OwnerId, Qualifier: rangeQueryObj.Qualifier})
+	if err != nil {
+		return nil, err
+	}
+
+	var rangeResObjs []types.RangeResObj
+	scanned, returned := 0, 0
+	for _, seriesObj := range seriesObjs {
+		if rangeQueryObj.Step > 0 {
+			buckets, resolution, seriesScanned, err := app.seriesBuckets(reader, seriesObj.Id, rangeQueryObj)
+			if err != nil {
+				return nil, err
+			}
+			scanned += seriesScanned
+			if len(buckets) > 0 {
+				rangeResObjs = append(rangeResObjs, types.RangeResObj{Series: seriesObj, Resolution: resolution, Buckets: buckets})
+				returned += len(buckets)
+			}
+			continue
+		}
+
+		points, err := app.chunkPoints(reader, nil, seriesObj.Id, rangeQueryObj.Start, rangeQueryObj.End)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) == 0 {
+			continue
+		}
+		scanned += len(points)
+
+		rangeResObj := types.RangeResObj{Series: seriesObj}
+		for _, point := range points {
+			rangeResObj.Points = append(rangeResObj.Points, types.PointObj{Timestamp: point.T, Value: point.V})
+		}
+		returned += len(rangeResObj.Points)
+		rangeResObjs = append(rangeResObjs, rangeResObj)
+	}
+	app.metrics.RowsScanned.Add(float64(scanned))
+	app.metrics.RowsReturned.Add(float64(returned))
+
+	return rangeResObjs, nil
+}
+
+// seriesBuckets는 series의 time range 숫자 데이터를 rangeQueryObj.Start부터 Step 간격의 bucket으로 집계하여 시간 순서로 return.
+// 집계에 사용한 rollup resolution(원본 point를 집계했으면 0)과 read한 point 혹은 rollup bucket 수도 return.
+func (app *MasterApplication) seriesBuckets(reader db.Reader, seriesId uint64, rangeQueryObj types.RangeQueryObj) ([]types.BucketObj, uint64, int, error) {
+	// bucket을 정확히 나누는 rollup이 있으면 원본 point 대신 rollup bucket을 합침
+	resolution, err := rangeResolution(reader, seriesId, rangeQueryObj)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if resolution > 0 {
+		buckets, scanned, err := rollupBuckets(reader, seriesId, resolution, rangeQueryObj)
+		return buckets, resolution, scanned, err
+	}
+
+	points, err := app.chunkPoints(reader, nil, seriesId, rangeQueryObj.Start, rangeQueryObj.End)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return aggregate(points, rangeQueryObj.Start, rangeQueryObj.Step), 0, len(points), nil
+}
+
+// chunkPoints는 series의 chunk 중 start 이상 end 미만의 point를 시간 순서로 read함.
+// blockChunks가 nil이 아니면 reader의 chunk 대신 blockChunks에서 수정 중인 chunk를 사용함.
+func (app *MasterApplication) chunkPoints(reader db.Reader, blockChunks map[string][]tsz.Point, seriesId uint64, start, end uint64) ([]tsz.Point, error) {
+	startKey := app.chunkKey(seriesId, start)
+	endKey := append(uint64Bytes(seriesId), uint64Bytes(end)...)
+	itr := reader.IteratorColumnFamily(startKey, endKey, reader.ColumnFamilyHandles()[consts.ChunkCFNum])
+	defer itr.Close()
+
+	chunks := make(map[string][]tsz.Point)
+	for ; itr.Valid() && bytes.Compare(itr.Key(), endKey) == -1; itr.Next() {
+		if _, ok := blockChunks[string(itr.Key())]; ok {
+			continue
+		}
+		points, err := tsz.Decode(itr.Value())
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode chunk %X failed", itr.Key())
+		}
+		chunks[string(itr.Key())] = points
+	}
+	for key, points := range blockChunks {
+		if bytes.Compare([]byte(key), startKey) >= 0 && bytes.Compare([]byte(key), endKey) == -1 {
+			chunks[key] = points
+		}
+	}
+
+	// chunk key는 chunk 시작 timestamp 순서이므로 key 순서로 이어 붙이면 시간 순서가 됨
+	keys := make([]string, 0, len(chunks))
+	for key := range chunks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var points []tsz.Point
+	for _, key := range keys {
+		for _, point := range chunks[key] {
+			if point.T >= start && point.T < end {
+				points = append(points, point)
+			}
+		}
+	}
+
+	return points, nil
+}
+
+// aggregate는 시간 순서로 정렬된 points를 start부터 step 간격의 bucket으로 집계함.
+func aggregate(points []tsz.Point, start, step uint64) []types.BucketObj {
+	var buckets []types.BucketObj
+	for _, point := range points {
+		buckets = mergeBucket(buckets, start+(point.T-start)/step*step, types.BucketObj{Count: 1, Sum: point.V, Min: point.V, Max: point.V, First: point.V, Last: point.V})
+	}
+
+	return buckets
+}
+
+// mergeBucket은 bucket을 bucketStart에서 시작하는 buckets의 마지막 bucket에 합침. 마지막 bucket의 시작이 다르면 새 bucket을 추가함.
+// bucket은 시간 순서로 merge해야 함.
+func mergeBucket(buckets []types.BucketObj, bucketStart uint64, bucket types.BucketObj) []types.BucketObj {
+	if len(buckets) == 0 || buckets[len(buckets)-1].Start != bucketStart {
+		buckets = append(buckets, types.BucketObj{Start: bucketStart, Min: bucket.Min, Max: bucket.Max, First: bucket.First})
+	}
+
+	last := &buckets[len(buckets)-1]
+	last.Count += bucket.Count
+	last.Sum += bucket.Sum
+	last.Min = math.Min(last.Min, bucket.Min)
+	last.Max = math.Max(last.Max, bucket.Max)
+	last.Last = bucket.Last
+	return buckets
+}
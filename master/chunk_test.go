@@ -0,0 +1,101 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"time"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_range() {
+	require := suite.Require()
+
+	//given
+	// 1초 chunk를 사용하는 app에 두 series의 숫자 데이터를 순서와 관계없이 두 block에 나누어 write함
+	suite.openApp(func(cfg *config.Config) {
+		cfg.Features.ChunkStorage = true
+		cfg.DB.ChunkInterval = time.Second
+	})
+
+	start := uint64(1545982882000000000)
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.deliver(givenDataObj(start+1.5e9, TestOwnerId, "temperature", "3"), givenDataObj(start, TestOwnerId, "temperature", "1"), givenDataObj(start+1e9, TestOwnerId2, "temperature", "10"))
+	suite.app.Commit()
+	// 숫자가 아닌 데이터는 chunk에 저장되지 않음
+	suite.deliver(givenDataObj(start+0.5e9, TestOwnerId, "temperature", "2"), givenDataObj(start+0.7e9, TestOwnerId, "temperature", "not a number"), givenDataObj(start+2.5e9, TestOwnerId, "temperature", "4"))
+	suite.app.Commit()
+
+	//when
+	actualPoints := suite.queryRange(types.RangeQueryObj{Start: start, End: start + 2e9, OwnerId: TestOwnerId})
+	actualBuckets := suite.queryRange(types.RangeQueryObj{Start: start, End: start + 3e9, Step: 2e9})
+
+	//then
+	// chunk 경계를 넘는 range의 point가 시간 순서로 read됨
+	series1 := types.SeriesObj{Id: 1, OwnerId: TestOwnerId, Qualifier: []byte("temperature")}
+	series2 := types.SeriesObj{Id: 2, OwnerId: TestOwnerId2, Qualifier: []byte("temperature")}
+	require.Equal([]types.RangeResObj{{Series: series1, Points: []types.PointObj{{Timestamp: start, Value: 1}, {Timestamp: start + 0.5e9, Value: 2}, {Timestamp: start + 1.5e9, Value: 3}}}}, actualPoints)
+
+	// step 간격의 bucket으로 집계됨
+	require.Equal([]types.RangeResObj{
+		{Series: series1, Buckets: []types.BucketObj{
+			{Start: start, Count: 3, Sum: 6, Min: 1, Max: 3, First: 1, Last: 3},
+			{Start: start + 2e9, Count: 1, Sum: 4, Min: 4, Max: 4, First: 4, Last: 4},
+		}},
+		{Series: series2, Buckets: []types.BucketObj{{Start: start, Count: 1, Sum: 10, Min: 10, Max: 10, First: 10, Last: 10}}},
+	}, actualBuckets)
+
+	// overwrite된 데이터는 chunk에서도 대체됨
+	overwriteObj := givenDataObj(start, TestOwnerId, "temperature", "1")
+	overwriteObj.RealData.Data = []byte("5")
+	overwriteObj.Conflict = consts.ConflictOverwrite
+	suite.deliver(overwriteObj)
+	suite.app.Commit()
+	actualPoints = suite.queryRange(types.RangeQueryObj{Start: start, End: start + 1e9, OwnerId: TestOwnerId})
+	require.Equal([]types.PointObj{{Timestamp: start, Value: 5}, {Timestamp: start + 0.5e9, Value: 2}}, actualPoints[0].Points)
+}
+
+func (suite *MasterSuite) TestMasterApplication_Query_range_disabled() {
+	require := suite.Require()
+
+	//given
+	rangeData, err := json.Marshal(types.RangeQueryObj{Start: 1, End: 2})
+	require.Nil(err)
+
+	//when
+	res := suite.app.Query(abciTypes.RequestQuery{Data: rangeData, Path: consts.RangePath})
+
+	//then
+	// chunk storage가 꺼져 있으면 error
+	require.Equal(code.CodeTypeUnknownError, res.Code)
+}
+
+func (suite *MasterSuite) TestMasterApplication_chunkSettings() {
+	require := suite.Require()
+
+	//given
+	// 1초 chunk를 사용하는 app에서 한 block을 commit함
+	configure := func(chunkStorage bool, chunkInterval time.Duration) func(cfg *config.Config) {
+		return func(cfg *config.Config) {
+			cfg.Features.ChunkStorage = chunkStorage
+			cfg.DB.ChunkInterval = chunkInterval
+		}
+	}
+	suite.openApp(configure(true, time.Second))
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.deliver(givenDataObj(1545982882000000000, TestOwnerId, "temperature", "1"))
+	suite.app.Commit()
+
+	//when
+	intervalErr := suite.reopenApp(configure(true, time.Minute))
+	storageErr := suite.reopenApp(configure(false, time.Second))
+	sameErr := suite.reopenApp(configure(true, time.Second))
+
+	//then
+	// commit한 뒤에는 chunk storage, chunk interval을 바꿔서 시작할 수 없음
+	require.NotNil(intervalErr)
+	require.NotNil(storageErr)
+	require.Nil(sameErr)
+}
@@ -0,0 +1,121 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func (suite *MasterSuite) TestMasterApplication_DeliverTx_overwrite() {
+	require := suite.Require()
+
+	//given
+	// height 1에 write된 데이터를 height 2에서 overwrite함
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 1}})
+	givenTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj1})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	suite.app.Commit()
+
+	overwriteObj := givenBaseDataObj1
+	overwriteObj.RealData.Data = []byte("corrected")
+	overwriteObj.Conflict = consts.ConflictOverwrite
+	overwriteTx, err := json.Marshal([]types.BaseDataObj{overwriteObj})
+	require.Nil(err)
+
+	//when
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 2}})
+	checkRes := suite.app.CheckTx(overwriteTx)
+	deliverRes := suite.app.DeliverTx(overwriteTx)
+	// 같은 block에서 아직 commit되지 않은 데이터는 다른 데이터로 다시 overwrite할 수 없음
+	sameBlockObj := overwriteObj
+	sameBlockObj.RealData.Data = []byte("corrected again")
+	sameBlockTx, err := json.Marshal([]types.BaseDataObj{sameBlockObj})
+	require.Nil(err)
+	sameBlockRes := suite.app.DeliverTx(sameBlockTx)
+	suite.app.Commit()
+
+	//then
+	require.Equal(code.CodeTypeOK, checkRes.Code, checkRes.Log)
+	require.Equal(code.CodeTypeOK, deliverRes.Code, deliverRes.Log)
+	require.Equal(consts.CodeTypeDuplicateRowKey, sameBlockRes.Code)
+
+	fetchData, err := json.Marshal(types.FetchObj{RowKeys: [][]byte{givenRowKey1}})
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: fetchData, Path: consts.FetchPath, Prove: true})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	var realDataObjs []types.RealDataObj
+	require.Nil(json.Unmarshal(res.Value, &realDataObjs))
+	require.Equal([]byte("corrected"), realDataObjs[0].Data)
+	var rowProof types.RowProof
+	require.Nil(json.Unmarshal(res.Proof.Ops[0].Data, &rowProof))
+	require.Equal(types.RealDataHash([]byte("corrected")), rowProof.RealHash)
+
+	// 대체된 데이터는 대체된 height와 함께 history에 남음
+	res = suite.app.Query(abciTypes.RequestQuery{Data: fetchData, Path: consts.HistoryPath})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	var historyObjs []types.HistoryObj
+	require.Nil(json.Unmarshal(res.Value, &historyObjs))
+	require.Equal([]types.HistoryObj{{RowKey: givenRowKey1, OwnerId: TestOwnerId, Qualifier: givenMetaDataObj1.Qualifier, Data: givenRealDataObj1.Data, Height: 2}}, historyObjs)
+
+	// 다른 owner의 데이터는 overwrite할 수 없음
+	otherOwnerObj := overwriteObj
+	otherOwnerObj.MetaData.OwnerId = TestOwnerId2
+	otherOwnerTx, err := json.Marshal([]types.BaseDataObj{otherOwnerObj})
+	require.Nil(err)
+	require.Equal(code.CodeTypeUnauthorized, suite.app.CheckTx(otherOwnerTx).Code)
+	require.Equal(code.CodeTypeUnauthorized, suite.app.DeliverTx(otherOwnerTx).Code)
+}
+
+func (suite *MasterSuite) TestMasterApplication_DeliverTx_keepFirst() {
+	require := suite.Require()
+
+	//given
+	suite.TestMasterApplication_InitChain()
+	givenTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj1})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	suite.app.Commit()
+
+	keepFirstObj1 := givenBaseDataObj1
+	keepFirstObj1.RealData.Data = []byte("ignored")
+	keepFirstObj1.Conflict = consts.ConflictKeepFirst
+	keepFirstObj2 := givenBaseDataObj2
+	keepFirstObj2.Conflict = consts.ConflictKeepFirst
+	keepFirstTx, err := json.Marshal([]types.BaseDataObj{keepFirstObj1, keepFirstObj2, keepFirstObj2})
+	require.Nil(err)
+
+	//when
+	actualRes := suite.app.DeliverTx(keepFirstTx)
+	suite.app.Commit()
+
+	//then
+	// 이미 write된 rowKey의 데이터와 tx 안에서 중복된 데이터는 write하지 않고 나머지만 write함
+	require.Equal(code.CodeTypeOK, actualRes.Code, actualRes.Log)
+	require.Equal("1 data skipped by keep-first, 1 duplicate data skipped", actualRes.Info)
+	var putResObj types.PutResObj
+	require.Nil(json.Unmarshal(actualRes.Data, &putResObj))
+	require.Equal([]int{0, 2}, putResObj.Skipped)
+
+	fetchData, err := json.Marshal(types.FetchObj{RowKeys: [][]byte{givenRowKey1, givenRowKey2}})
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: fetchData, Path: consts.FetchPath})
+	var realDataObjs []types.RealDataObj
+	require.Nil(json.Unmarshal(res.Value, &realDataObjs))
+	require.Equal([]types.RealDataObj{givenRealDataObj1, givenRealDataObj2}, realDataObjs)
+
+	res = suite.app.Query(abciTypes.RequestQuery{Data: fetchData, Path: consts.HistoryPath})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	require.Equal("null", string(res.Value))
+
+	// 알 수 없는 conflict policy는 거부됨
+	invalidObj := givenBaseDataObj1
+	invalidObj.Conflict = "replace"
+	invalidTx, err := json.Marshal([]types.BaseDataObj{invalidObj})
+	require.Nil(err)
+	require.Equal(consts.CodeTypeInvalidConflict, suite.app.CheckTx(invalidTx).Code)
+	require.Equal(consts.CodeTypeInvalidConflict, suite.app.DeliverTx(invalidTx).Code)
+}
@@ -0,0 +1,50 @@
+package master
+
+import (
+	"bytes"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/paust-team/paust-db/types"
+)
+
+// countQuery는 queryObj 조건에 맞는 데이터 수와 서로 다른 series, ownerId, qualifier 수를 return.
+// metadata를 하나씩 scan하며 결과 slice를 만들지 않으므로 memory 사용량은 서로 다른 series 수에만 비례함.
+func (app *MasterApplication) countQuery(reader db.Reader, queryObj types.QueryObj) (types.CountResObj, error) {
+	var countResObj types.CountResObj
+	filter, err := newMetaDataFilter(queryObj)
+	if err != nil {
+		return countResObj, err
+	}
+
+	startByte := types.TimestampKey(queryObj.Start)
+	endByte := types.TimestampKey(queryObj.End)
+	itr := reader.IteratorColumnFamily(startByte, endByte, reader.ColumnFamilyHandles()[consts.MetaCFNum])
+	defer itr.Close()
+
+	series := make(map[string]struct{})
+	owners := make(map[string]struct{})
+	qualifiers := make(map[string]struct{})
+	scanned := 0
+	resolver := newSeriesResolver(reader)
+	for itr.Seek(startByte); itr.Valid() && bytes.Compare(itr.Key(), endByte) == -1; itr.Next() {
+		ownerId, qualifier, err := resolver.resolve(itr.Value())
+		if err != nil {
+			return countResObj, err
+		}
+		scanned++
+		if !filter.match(ownerId, qualifier) {
+			continue
+		}
+
+		countResObj.Count++
+		series[string(seriesKey(ownerId, qualifier))] = struct{}{}
+		owners[ownerId] = struct{}{}
+		qualifiers[string(qualifier)] = struct{}{}
+	}
+	countResObj.Series = uint64(len(series))
+	countResObj.Owners = uint64(len(owners))
+	countResObj.Qualifiers = uint64(len(qualifiers))
+	app.metrics.RowsScanned.Add(float64(scanned))
+
+	return countResObj, nil
+}
@@ -0,0 +1,45 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_count() {
+	require := suite.Require()
+
+	//given
+	// owner1의 두 series와 owner2의 한 series에 데이터 네 개를 write함
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	tx, err := json.Marshal([]types.BaseDataObj{
+		givenDataObj(1545982882000000000, TestOwnerId, "temperature", "data"),
+		givenDataObj(1545982882000000001, TestOwnerId, "temperature", "data"),
+		givenDataObj(1545982882000000002, TestOwnerId, "humidity", "data"),
+		givenDataObj(1545982882000000003, TestOwnerId2, "temperature", "data"),
+	})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(tx).Code)
+	suite.app.Commit()
+
+	queryCount := func(queryObj types.QueryObj) types.CountResObj {
+		countData, err := json.Marshal(queryObj)
+		require.Nil(err)
+		res := suite.app.Query(abciTypes.RequestQuery{Data: countData, Path: consts.CountPath})
+		require.Equal(code.CodeTypeOK, res.Code, res.Log)
+		var countResObj types.CountResObj
+		require.Nil(json.Unmarshal(res.Value, &countResObj))
+		return countResObj
+	}
+
+	//when
+	actual := queryCount(types.QueryObj{Start: 1545982882000000000, End: 1545982882000000004})
+
+	//then
+	require.Equal(types.CountResObj{Count: 4, Series: 3, Owners: 2, Qualifiers: 2}, actual)
+	require.Equal(types.CountResObj{Count: 3, Series: 2, Owners: 1, Qualifiers: 2}, queryCount(types.QueryObj{Start: 1545982882000000000, End: 1545982882000000004, OwnerId: TestOwnerId}))
+	require.Equal(types.CountResObj{Count: 2, Series: 1, Owners: 1, Qualifiers: 1}, queryCount(types.QueryObj{Start: 1545982882000000000, End: 1545982882000000003, Qualifier: []byte("temperature")}))
+	require.Equal(types.CountResObj{}, queryCount(types.QueryObj{Start: 1545982882000000004, End: 1545982882000000005}))
+}
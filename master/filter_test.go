@@ -0,0 +1,60 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_filters() {
+	require := suite.Require()
+
+	//given
+	// 네 owner의 데이터를 write함
+	owner1Temperature := givenDataObj(1545982882000000000, TestOwnerId, "temperature", "data")
+	owner2Humidity := givenDataObj(1545982882000000001, TestOwnerId2, "humidity", "data")
+	fleetATemperature := givenDataObj(1545982882000000002, "fleet-a", "temperature", "data")
+	fleetBHumidity := givenDataObj(1545982882000000003, "fleet-b", "humidity", "data")
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	tx, err := json.Marshal([]types.BaseDataObj{owner1Temperature, owner2Humidity, fleetATemperature, fleetBHumidity})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(tx).Code)
+	suite.app.Commit()
+
+	query := func(queryObj types.QueryObj) []types.MetaDataObj {
+		queryObj.Start, queryObj.End = 1545982882000000000, 1545982882000000004
+		queryData, err := json.Marshal(queryObj)
+		require.Nil(err)
+		res := suite.app.Query(abciTypes.RequestQuery{Data: queryData, Path: consts.QueryPath})
+		require.Equal(code.CodeTypeOK, res.Code, res.Log)
+		var metaDataObjs []types.MetaDataObj
+		require.Nil(json.Unmarshal(res.Value, &metaDataObjs))
+		return metaDataObjs
+	}
+
+	//when
+	actual := query(types.QueryObj{OwnerIds: []string{TestOwnerId, TestOwnerId2}, Qualifier: []byte{}})
+
+	//then
+	// OwnerIds 중 하나와 일치하는 데이터를 한 번의 scan으로 read함
+	require.Equal([]types.MetaDataObj{owner1Temperature.MetaData, owner2Humidity.MetaData}, actual)
+
+	// OwnerId와 OwnerIds는 합쳐서 하나의 set으로 검사함
+	require.Equal([]types.MetaDataObj{owner1Temperature.MetaData, fleetBHumidity.MetaData}, query(types.QueryObj{OwnerId: TestOwnerId, OwnerIds: []string{"fleet-b"}, Qualifier: []byte{}}))
+
+	// ownerId prefix와 qualifier set 조건을 함께 검사하며 Qualifiers가 있으면 Qualifier를 생략할 수 있음
+	require.Equal([]types.MetaDataObj{fleetATemperature.MetaData, fleetBHumidity.MetaData}, query(types.QueryObj{OwnerIdPrefix: "fleet-", Qualifier: []byte{}}))
+	require.Equal([]types.MetaDataObj{fleetBHumidity.MetaData}, query(types.QueryObj{OwnerIdPrefix: "fleet-", Qualifiers: [][]byte{[]byte("humidity"), []byte("speed")}}))
+	require.Equal(0, len(query(types.QueryObj{OwnerIds: []string{TestOwnerId}, OwnerIdPrefix: "fleet-", Qualifier: []byte{}})))
+
+	// /count도 같은 조건을 사용함
+	countData, err := json.Marshal(types.QueryObj{Start: 1545982882000000000, End: 1545982882000000004, OwnerIdPrefix: "owner", Qualifiers: [][]byte{[]byte("temperature"), []byte("humidity")}})
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: countData, Path: consts.CountPath})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	var countResObj types.CountResObj
+	require.Nil(json.Unmarshal(res.Value, &countResObj))
+	require.Equal(types.CountResObj{Count: 2, Series: 2, Owners: 2, Qualifiers: 2}, countResObj)
+}
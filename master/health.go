@@ -0,0 +1,62 @@
+package master
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"net/http"
+	"time"
+)
+
+// Health는 DB open/write 가능 여부, 마지막 commit height와 시각, ABCI handshake 여부를 return.
+// health check는 consensus 상태가 담긴 DB를 바꾸지 않아야 하므로 write 없이 마지막 height를 read하고
+// rocksdb가 write를 멈췄는지(rocksdb.is-write-stopped)를 확인하여 write 가능 여부를 판단함.
+func (app *MasterApplication) Health() types.HealthObj {
+	app.dbMtx.RLock()
+	defer app.dbMtx.RUnlock()
+	app.stateMtx.RLock()
+	defer app.stateMtx.RUnlock()
+
+	health := types.HealthObj{
+		DBOpen:        app.dbOpen,
+		LastHeight:    app.lastHeight,
+		LastBlockTime: app.lastBlockTime,
+		Handshaked:    app.handshaked,
+	}
+	if !app.lastBlockTime.IsZero() {
+		health.SinceLastBlock = time.Since(app.lastBlockTime).Round(time.Millisecond).String()
+	}
+	if app.dbOpen {
+		_, err := getUint64(app.db, consts.DefaultCFNum, lastHeightKey)
+		health.DBWritable = err == nil && app.db.GetPropertyFromColumnFamily(consts.DefaultCFNum, "rocksdb.is-write-stopped") != "1"
+	}
+	health.Ready = health.DBOpen && health.DBWritable && health.Handshaked
+
+	return health
+}
+
+// HealthzHandler는 liveness check handler. DB가 open 상태이면 200, 아니면 503을 return.
+func (app *MasterApplication) HealthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := app.Health()
+		writeHealth(w, health, health.DBOpen)
+	}
+}
+
+// ReadyzHandler는 readiness check handler. DB write가 가능하고 ABCI handshake를 마쳤으면 200, 아니면 503을 return.
+func (app *MasterApplication) ReadyzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := app.Health()
+		writeHealth(w, health, health.Ready)
+	}
+}
+
+func writeHealth(w http.ResponseWriter, health types.HealthObj, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
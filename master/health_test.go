@@ -0,0 +1,82 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Health() {
+	require := suite.Require()
+
+	/*
+		ABCI 연결 전
+	*/
+	//when
+	actualHealth := suite.app.Health()
+
+	//then
+	require.True(actualHealth.DBOpen)
+	require.True(actualHealth.DBWritable)
+	require.False(actualHealth.Handshaked)
+	require.False(actualHealth.Ready)
+	require.Equal(int64(0), actualHealth.LastHeight)
+	require.Equal("", actualHealth.SinceLastBlock)
+
+	/*
+		block commit 후
+	*/
+	//given
+	suite.app.Info(abciTypes.RequestInfo{})
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 3}})
+	suite.app.Commit()
+
+	//when
+	actualHealth = suite.app.Health()
+
+	//then
+	require.True(actualHealth.Handshaked)
+	require.True(actualHealth.Ready)
+	require.Equal(int64(3), actualHealth.LastHeight)
+	require.False(actualHealth.LastBlockTime.IsZero())
+	require.NotEqual("", actualHealth.SinceLastBlock)
+}
+
+func (suite *MasterSuite) TestMasterApplication_HealthHandler() {
+	require := suite.Require()
+
+	/*
+		not ready
+	*/
+	//when
+	healthzRecorder := httptest.NewRecorder()
+	suite.app.HealthzHandler()(healthzRecorder, httptest.NewRequest("GET", "/healthz", nil))
+	readyzRecorder := httptest.NewRecorder()
+	suite.app.ReadyzHandler()(readyzRecorder, httptest.NewRequest("GET", "/readyz", nil))
+
+	//then
+	require.Equal(http.StatusOK, healthzRecorder.Code)
+	require.Equal(http.StatusServiceUnavailable, readyzRecorder.Code)
+
+	var actualHealth types.HealthObj
+	require.Nil(json.Unmarshal(readyzRecorder.Body.Bytes(), &actualHealth))
+	require.False(actualHealth.Ready)
+
+	/*
+		ready
+	*/
+	//given
+	suite.app.Info(abciTypes.RequestInfo{})
+
+	//when
+	readyzRecorder = httptest.NewRecorder()
+	suite.app.ReadyzHandler()(readyzRecorder, httptest.NewRequest("GET", "/readyz", nil))
+
+	//then
+	require.Equal(http.StatusOK, readyzRecorder.Code)
+	require.Nil(json.Unmarshal(readyzRecorder.Body.Bytes(), &actualHealth))
+	require.True(actualHealth.Ready)
+}
@@ -0,0 +1,79 @@
+package master
+
+import (
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/pkg/errors"
+)
+
+// heightSnapshot은 commit된 height의 DB snapshot과 그 height의 merkle tree 크기.
+type heightSnapshot struct {
+	height   int64
+	treeSize uint64
+	snapshot *db.Snapshot
+	// refs는 snapshot을 read 중인 Query 수. pruned된 snapshot은 refs가 0이 될 때 release됨.
+	refs   int
+	pruned bool
+}
+
+// addSnapshot은 height의 snapshot을 추가하고 historyHeights개를 넘는 오래된 snapshot을 제거함. stateMtx를 lock한 상태로 호출해야 함.
+// Query가 Commit 중인 DB를 read하지 않도록 historyHeights가 0이어도 마지막 height의 snapshot은 유지함.
+func (app *MasterApplication) addSnapshot(height int64, treeSize uint64) {
+	limit := app.historyHeights
+	if limit < 1 {
+		limit = 1
+	}
+
+	app.snapshots = append(app.snapshots, &heightSnapshot{height: height, treeSize: treeSize, snapshot: app.db.NewSnapshot()})
+	for len(app.snapshots) > limit {
+		pruned := app.snapshots[0]
+		app.snapshots[0] = nil
+		app.snapshots = app.snapshots[1:]
+		pruned.pruned = true
+		if pruned.refs == 0 {
+			pruned.snapshot.Release()
+		}
+	}
+}
+
+// releaseSnapshots는 모든 snapshot을 release함. DB를 close하기 전에 stateMtx를 lock한 상태로 호출해야 함.
+func (app *MasterApplication) releaseSnapshots() {
+	for _, heightSnapshot := range app.snapshots {
+		heightSnapshot.snapshot.Release()
+	}
+	app.snapshots = nil
+}
+
+// stateAt은 height에 commit된 상태를 read하는 reader와 그 상태의 height, merkle tree 크기를 return. height가 0이면 마지막 height를 사용함.
+// 마지막 height의 snapshot은 항상 있으므로 Commit 중에도 commit된 상태만 read함.
+// 사용이 끝나면 release를 호출해야 함. 아직 commit되지 않았거나 snapshot이 제거된 height이면 error를 return.
+func (app *MasterApplication) stateAt(height int64) (reader db.Reader, stateHeight int64, treeSize uint64, release func(), err error) {
+	app.stateMtx.Lock()
+	defer app.stateMtx.Unlock()
+
+	if height == 0 {
+		height = app.lastHeight
+	}
+	if height > app.lastHeight {
+		return nil, 0, 0, nil, errors.Errorf("height %v is not committed yet. Latest height is %v", height, app.lastHeight)
+	}
+
+	for i := len(app.snapshots) - 1; i >= 0; i-- {
+		heightSnapshot := app.snapshots[i]
+		if heightSnapshot.height != height {
+			continue
+		}
+
+		heightSnapshot.refs++
+		release = func() {
+			app.stateMtx.Lock()
+			defer app.stateMtx.Unlock()
+			heightSnapshot.refs--
+			if heightSnapshot.pruned && heightSnapshot.refs == 0 {
+				heightSnapshot.snapshot.Release()
+			}
+		}
+		return heightSnapshot.snapshot, heightSnapshot.height, heightSnapshot.treeSize, release, nil
+	}
+
+	return nil, 0, 0, nil, errors.Errorf("height %v is pruned. Latest height is %v", height, app.lastHeight)
+}
@@ -0,0 +1,104 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_height() {
+	require := suite.Require()
+
+	//given
+	// 최근 2개 height의 snapshot만 유지하는 app에 height마다 row를 하나씩 write함
+	suite.openApp(func(cfg *config.Config) {
+		cfg.DB.HistoryHeights = 2
+	})
+
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	start := uint64(1545982882435375000)
+	for height := int64(1); height <= 4; height++ {
+		suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: height}})
+		rowKey := types.GetRowKey(start+uint64(height), 0)
+		givenTx, err := json.Marshal([]types.BaseDataObj{{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId, Qualifier: []byte("Memory")}, RealData: types.RealDataObj{RowKey: rowKey, Data: []byte{byte(height)}}}})
+		require.Nil(err)
+		require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+		suite.app.Commit()
+	}
+	queryData, err := json.Marshal(types.QueryDataObj{QueryObj: types.QueryObj{Start: start, End: start + 10, Qualifier: []byte{}}})
+	require.Nil(err)
+	queryAt := func(height int64) abciTypes.ResponseQuery {
+		return suite.app.Query(abciTypes.RequestQuery{Data: queryData, Path: consts.QueryDataPath, Height: height, Prove: true})
+	}
+
+	//when
+	res := queryAt(3)
+
+	//then
+	// height 3의 상태에는 height 3까지 write된 row만 있음
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	require.Equal(int64(3), res.Height)
+	var queryDataResObj types.QueryDataResObj
+	require.Nil(json.Unmarshal(res.Value, &queryDataResObj))
+	require.Equal(3, len(queryDataResObj.Data))
+	require.Equal(3, len(res.Proof.Ops))
+	var rowProof types.RowProof
+	require.Nil(json.Unmarshal(res.Proof.Ops[0].Data, &rowProof))
+	require.Equal(3, rowProof.Total)
+
+	// height를 지정하지 않으면 마지막 height를 read함
+	res = queryAt(0)
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	require.Equal(int64(4), res.Height)
+	require.Nil(json.Unmarshal(res.Value, &queryDataResObj))
+	require.Equal(4, len(queryDataResObj.Data))
+
+	// 유지 범위를 벗어난 height와 아직 commit되지 않은 height는 error
+	require.NotEqual(code.CodeTypeOK, queryAt(2).Code)
+	require.NotEqual(code.CodeTypeOK, queryAt(5).Code)
+}
+
+func (suite *MasterSuite) TestMasterApplication_Query_noHistory() {
+	require := suite.Require()
+
+	//given
+	// 과거 height의 snapshot을 유지하지 않는 app
+	suite.openApp(func(cfg *config.Config) {
+		cfg.DB.HistoryHeights = 0
+	})
+
+	queryData, err := json.Marshal(types.QueryObj{Start: 1545982882435375000, End: 1545982882435375002, Qualifier: []byte{}})
+	require.Nil(err)
+	queryAt := func(height int64) abciTypes.ResponseQuery {
+		return suite.app.Query(abciTypes.RequestQuery{Data: queryData, Path: consts.QueryPath, Height: height})
+	}
+	givenTx, err := json.Marshal(givenBaseDataObjs)
+	require.Nil(err)
+
+	//when
+	// 첫 Commit 이전에도 height 0의 snapshot을 read함
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 1}})
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	beforeCommitRes := queryAt(0)
+	suite.app.Commit()
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 2}})
+	suite.app.Commit()
+
+	//then
+	require.Equal(code.CodeTypeOK, beforeCommitRes.Code, beforeCommitRes.Log)
+	require.Equal(int64(0), beforeCommitRes.Height)
+	require.Equal("null", string(beforeCommitRes.Value))
+
+	// 마지막 height의 snapshot만 유지함
+	res := queryAt(0)
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	require.Equal(int64(2), res.Height)
+	var metaDataObjs []types.MetaDataObj
+	require.Nil(json.Unmarshal(res.Value, &metaDataObjs))
+	require.Equal(2, len(metaDataObjs))
+	require.NotEqual(code.CodeTypeOK, queryAt(1).Code)
+}
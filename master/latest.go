@@ -0,0 +1,206 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	"math"
+	"time"
+)
+
+// latest column family는 series id를 key로 series의 가장 최신 timestamp 데이터의 rowKey를 저장함.
+
+// setLatest는 rowKey의 timestamp가 series에 저장된 최신 rowKey의 timestamp보다 크면 latest index를 rowKey로 갱신하여 block batch에 담음.
+// DeliverTx에서만 호출해야 함.
+func (app *MasterApplication) setLatest(seriesId uint64, rowKey []byte) error {
+	latest, ok := app.blockLatest[seriesId]
+	if !ok {
+		var err error
+		if latest, err = getLatest(app.db, seriesId); err != nil {
+			return err
+		}
+	}
+	if latest != nil && binary.BigEndian.Uint64(rowKey[0:consts.TimestampLen]) <= binary.BigEndian.Uint64(latest[0:consts.TimestampLen]) {
+		return nil
+	}
+
+	if app.blockLatest == nil {
+		app.blockLatest = make(map[uint64][]byte)
+	}
+	app.blockLatest[seriesId] = rowKey
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.LatestCFNum], uint64Bytes(seriesId), rowKey)
+	return nil
+}
+
+// latestRepairWindow는 latest index를 고칠 때 commit된 데이터를 거꾸로 찾는 첫 timestamp 범위(nano second).
+// 범위 안에서 찾지 못하면 범위를 두 배씩 넓혀 더 이전 데이터를 찾음.
+const latestRepairWindow = uint64(time.Minute)
+
+// repairLatest는 overwrite로 rowKey가 ownerId, qualifier series에서 빠질 때 rowKey가 series의 최신 rowKey이면
+// series에 남은 가장 최신 데이터로 latest index를 고쳐 block batch에 담음. 남은 데이터가 없으면 latest index를 그대로 두며
+// latestQuery에서 series가 제외됨. rowKey의 새 데이터를 blockRowKeys에 담은 뒤 DeliverTx에서만 호출해야 함.
+func (app *MasterApplication) repairLatest(ownerId string, qualifier []byte, rowKey []byte) error {
+	seriesId, err := app.lookupSeriesId(seriesKey(ownerId, qualifier))
+	if err != nil || seriesId == 0 {
+		return err
+	}
+	latest, ok := app.blockLatest[seriesId]
+	if !ok {
+		if latest, err = getLatest(app.db, seriesId); err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(latest, rowKey) {
+		return nil
+	}
+
+	// 현재 block에서 write된 데이터는 아직 DB에 없으므로 blockRowKeys에서 찾음
+	var candidate []byte
+	for key, baseDataObj := range app.blockRowKeys {
+		if baseDataObj.MetaData.OwnerId == ownerId && bytes.Equal(baseDataObj.MetaData.Qualifier, qualifier) && bytes.Compare([]byte(key), candidate) > 0 {
+			candidate = []byte(key)
+		}
+	}
+
+	// commit된 데이터는 rowKey의 timestamp부터 범위를 넓혀가며 거꾸로 찾음
+	timestamp := binary.BigEndian.Uint64(rowKey[0:consts.TimestampLen])
+	var end []byte
+	if timestamp < math.MaxUint64 {
+		end = types.TimestampKey(timestamp + 1)
+	}
+	resolver := newSeriesResolver(app.db)
+	window := latestRepairWindow
+	for {
+		start := uint64(0)
+		if timestamp > window {
+			start = timestamp - window
+		}
+		found, err := app.lastSeriesRow(resolver, ownerId, qualifier, types.TimestampKey(start), end)
+		if err != nil {
+			return err
+		}
+		if bytes.Compare(found, candidate) > 0 {
+			candidate = found
+		}
+		// 범위 이전의 데이터는 찾은 데이터보다 최신일 수 없음
+		if candidate != nil && binary.BigEndian.Uint64(candidate[0:consts.TimestampLen]) >= start {
+			break
+		}
+		if start == 0 {
+			break
+		}
+		end = types.TimestampKey(start)
+		if window > math.MaxUint64/2 {
+			window = math.MaxUint64
+		} else {
+			window *= 2
+		}
+	}
+	if candidate == nil {
+		return nil
+	}
+
+	if app.blockLatest == nil {
+		app.blockLatest = make(map[uint64][]byte)
+	}
+	app.blockLatest[seriesId] = candidate
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.LatestCFNum], uint64Bytes(seriesId), candidate)
+	return nil
+}
+
+// lastSeriesRow는 commit된 metadata 중 [start, end) 범위에서 ownerId, qualifier series의 가장 마지막 rowKey를 return.
+// 현재 block에서 다시 write된 rowKey는 commit된 series가 바뀌었을 수 있으므로 제외함. 없으면 nil을 return.
+func (app *MasterApplication) lastSeriesRow(resolver *seriesResolver, ownerId string, qualifier []byte, start, end []byte) ([]byte, error) {
+	itr := app.db.IteratorColumnFamily(start, end, app.db.ColumnFamilyHandles()[consts.MetaCFNum])
+	defer itr.Close()
+
+	var last []byte
+	for ; itr.Valid(); itr.Next() {
+		if _, ok := app.blockRowKeys[string(itr.Key())]; ok {
+			continue
+		}
+		rowOwnerId, rowQualifier, err := resolver.resolve(itr.Value())
+		if err != nil {
+			return nil, err
+		}
+		if rowOwnerId == ownerId && bytes.Equal(rowQualifier, qualifier) {
+			last = append(last[:0], itr.Key()...)
+		}
+	}
+
+	return last, nil
+}
+
+// getLatest는 reader에 저장된 series의 최신 rowKey를 return. 저장된 rowKey가 없으면 nil을 return.
+func getLatest(reader db.Reader, seriesId uint64) ([]byte, error) {
+	slice, err := reader.GetDataFromColumnFamily(consts.LatestCFNum, uint64Bytes(seriesId))
+	if err != nil {
+		return nil, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	if !slice.Exists() {
+		return nil, nil
+	}
+	if slice.Size() < consts.TimestampLen {
+		return nil, errors.Errorf("wrong latest rowKey length of series %v", seriesId)
+	}
+
+	return append([]byte{}, slice.Data()...), nil
+}
+
+// latestQuery는 latestQueryObj와 일치하는 series마다 가장 최신 timestamp 데이터의 metadata와 실제 데이터를 series id 순서로 read함.
+// metadata는 rowKey에 저장된 값을 read하며, latest index가 없는 series(latest index 도입 이전에만 write된 series)와
+// 모든 데이터가 overwrite로 다른 series의 데이터가 된 series는 제외됨.
+func (app *MasterApplication) latestQuery(reader db.Reader, latestQueryObj types.LatestQueryObj) ([]types.BaseDataObj, error) {
+	seriesObjs, err := app.matchSeries(reader, latestQueryObj.OwnerIds, latestQueryObj.Qualifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	var fetchObj types.FetchObj
+	var metaDataObjs []types.MetaDataObj
+	resolver := newSeriesResolver(reader)
+	for _, seriesObj := range seriesObjs {
+		rowKey, err := getLatest(reader, seriesObj.Id)
+		if err != nil {
+			return nil, err
+		}
+		if rowKey == nil {
+			continue
+		}
+		slice, err := reader.GetDataFromColumnFamily(consts.MetaCFNum, rowKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetDataFromColumnFamily err")
+		}
+		if !slice.Exists() {
+			slice.Free()
+			continue
+		}
+		ownerId, qualifier, err := resolver.resolve(slice.Data())
+		slice.Free()
+		if err != nil {
+			return nil, err
+		}
+		if ownerId != seriesObj.OwnerId || !bytes.Equal(qualifier, seriesObj.Qualifier) {
+			continue
+		}
+		fetchObj.RowKeys = append(fetchObj.RowKeys, rowKey)
+		metaDataObjs = append(metaDataObjs, types.MetaDataObj{RowKey: rowKey, OwnerId: ownerId, Qualifier: qualifier})
+	}
+	realDataObjs, err := app.realDataFetch(reader, fetchObj)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseDataObjs []types.BaseDataObj
+	for i := range metaDataObjs {
+		baseDataObjs = append(baseDataObjs, types.BaseDataObj{MetaData: metaDataObjs[i], RealData: realDataObjs[i]})
+	}
+	app.metrics.RowsScanned.Add(float64(len(seriesObjs)))
+	app.metrics.RowsReturned.Add(float64(len(baseDataObjs)))
+
+	return baseDataObjs, nil
+}
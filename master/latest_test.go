@@ -0,0 +1,85 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_latest() {
+	require := suite.Require()
+
+	//given
+	// 두 block에 걸쳐 세 series의 데이터를 timestamp 순서와 관계없이 write함
+	newest := givenDataObj(1545982882000000030, TestOwnerId, "temperature", "c")
+	owner2Newest := givenDataObj(1545982882000000020, TestOwnerId2, "temperature", "b")
+	humidityNewest := givenDataObj(1545982882000000005, TestOwnerId, "humidity", "d")
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.deliver(givenDataObj(1545982882000000010, TestOwnerId, "temperature", "a"), newest, owner2Newest)
+	suite.app.Commit()
+	// 저장된 최신 데이터보다 오래된 데이터는 latest index를 바꾸지 않음
+	suite.deliver(givenDataObj(1545982882000000020, TestOwnerId, "temperature", "e"), humidityNewest)
+	suite.app.Commit()
+
+	queryLatest := func(latestQueryObj types.LatestQueryObj) abciTypes.ResponseQuery {
+		latestData, err := json.Marshal(latestQueryObj)
+		require.Nil(err)
+		res := suite.app.Query(abciTypes.RequestQuery{Data: latestData, Path: consts.LatestPath, Prove: true})
+		require.Equal(code.CodeTypeOK, res.Code, res.Log)
+		return res
+	}
+	latestData := func(res abciTypes.ResponseQuery) []types.BaseDataObj {
+		var baseDataObjs []types.BaseDataObj
+		require.Nil(json.Unmarshal(res.Value, &baseDataObjs))
+		return baseDataObjs
+	}
+
+	//when
+	res := queryLatest(types.LatestQueryObj{})
+
+	//then
+	// 모든 series의 최신 데이터를 series id 순서로 read하며 각 row의 proof를 함께 제공함
+	require.Equal([]types.BaseDataObj{newest, owner2Newest, humidityNewest}, latestData(res))
+	require.Equal(3, len(res.Proof.Ops))
+
+	// 여러 owner, qualifier 중 하나와 일치하는 series만 read함
+	require.Equal([]types.BaseDataObj{newest}, latestData(queryLatest(types.LatestQueryObj{OwnerIds: []string{TestOwnerId}, Qualifiers: [][]byte{[]byte("temperature")}})))
+	require.Equal([]types.BaseDataObj{newest, owner2Newest}, latestData(queryLatest(types.LatestQueryObj{OwnerIds: []string{TestOwnerId2, TestOwnerId}, Qualifiers: [][]byte{[]byte("temperature")}})))
+	require.Equal([]types.BaseDataObj{humidityNewest}, latestData(queryLatest(types.LatestQueryObj{Qualifiers: [][]byte{[]byte("humidity")}})))
+	require.Equal(0, len(latestData(queryLatest(types.LatestQueryObj{OwnerIds: []string{"owner3"}}))))
+
+	// 최신 rowKey가 다른 qualifier의 데이터로 overwrite되면 이전 series는 남은 데이터 중 최신 데이터를 read하고
+	// overwrite된 rowKey는 저장된 metadata로 read됨
+	overwritten := newest
+	overwritten.MetaData.Qualifier = []byte("pressure")
+	overwritten.RealData.Data = []byte("f")
+	overwritten.Conflict = consts.ConflictOverwrite
+	suite.deliver(overwritten)
+	suite.app.Commit()
+	overwritten.Conflict = ""
+	olderNewest := givenDataObj(1545982882000000020, TestOwnerId, "temperature", "e")
+	require.Equal([]types.BaseDataObj{olderNewest, owner2Newest, humidityNewest, overwritten}, latestData(queryLatest(types.LatestQueryObj{})))
+
+	// 같은 block에서 write된 데이터도 남은 데이터로 찾으며 같은 block에서 다른 series로 overwrite된 데이터는 제외됨
+	pendingNewest := givenDataObj(1545982882000000005, TestOwnerId, "temperature", "g")
+	var overwriteObjs []types.BaseDataObj
+	for _, baseDataObj := range []types.BaseDataObj{olderNewest, givenDataObj(1545982882000000010, TestOwnerId, "temperature", "a")} {
+		baseDataObj.MetaData.Qualifier = []byte("pressure")
+		baseDataObj.Conflict = consts.ConflictOverwrite
+		overwriteObjs = append(overwriteObjs, baseDataObj)
+	}
+	suite.deliver(pendingNewest, overwriteObjs[1])
+	suite.deliver(overwriteObjs[0])
+	suite.app.Commit()
+	temperatureLatest := types.LatestQueryObj{OwnerIds: []string{TestOwnerId}, Qualifiers: [][]byte{[]byte("temperature")}}
+	require.Equal([]types.BaseDataObj{pendingNewest}, latestData(queryLatest(temperatureLatest)))
+
+	// series에 남은 데이터가 없으면 결과에서 제외됨
+	pendingNewest.MetaData.Qualifier = []byte("pressure")
+	pendingNewest.Conflict = consts.ConflictOverwrite
+	suite.deliver(pendingNewest)
+	suite.app.Commit()
+	require.Equal(0, len(latestData(queryLatest(temperatureLatest))))
+}
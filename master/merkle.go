@@ -0,0 +1,335 @@
+package master
+
+import (
+	"encoding/binary"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"math/bits"
+)
+
+// proof column family key prefix
+var (
+	nodeKeyPrefix = []byte("n")
+	rowKeyPrefix  = []byte("r")
+)
+
+// default column family에 저장하는 app state key
+var (
+	lastHeightKey = []byte("lastHeight")
+	treeSizeKey   = []byte("treeSize")
+)
+
+// rowEntryLen은 proof column family에 저장되는 row entry(leaf index, metadata hash, realdata hash)의 길이.
+const rowEntryLen = 8 + 2*tmhash.Size
+
+// stateTree는 write된 모든 rowKey의 leaf를 처음 write된 순서대로 담는 merkle tree이며 root를 app hash로 사용함.
+// overwrite된 rowKey의 leaf는 같은 자리에서 새 데이터의 leaf로 교체되므로 tree는 rowKey별 현재 데이터만 commit함.
+// root는 tendermint의 merkle.SimpleHashFromByteSlices(leaves)와 같으므로 merkle.SimpleProof로 검증할 수 있음.
+// 완성된 perfect subtree의 node만 저장하며 size의 set bit마다 하나씩 있는 peak를 메모리에 유지함.
+type stateTree struct {
+	size uint64
+	// peaks[level]은 size의 level번째 bit가 1일 때 해당 perfect subtree의 hash. 아니면 nil.
+	peaks [][]byte
+	// nodes는 batch에 기록되었지만 아직 DB에 write되지 않은 node로 같은 block 안의 leaf 교체에 사용함.
+	nodes map[string][]byte
+}
+
+// loadStateTree는 DB에 저장된 tree size와 node로 stateTree를 복원함.
+func loadStateTree(database db.DB) (*stateTree, error) {
+	tree := &stateTree{}
+	size, err := getUint64(database, consts.DefaultCFNum, treeSizeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var offset uint64
+	for level := 63; level >= 0; level-- {
+		if size&(1<<uint(level)) == 0 {
+			continue
+		}
+		hash, err := getNode(database, uint8(level), offset>>uint(level))
+		if err != nil {
+			return nil, err
+		}
+		tree.setPeak(level, hash)
+		offset += 1 << uint(level)
+	}
+	tree.size = size
+
+	return tree, nil
+}
+
+// append는 leaf를 tree에 추가하고 새로 완성된 node와 tree size를 batch에 기록한 뒤 leaf index를 return.
+func (tree *stateTree) append(database db.DB, batch db.Batch, leaf []byte) uint64 {
+	index := tree.size
+	hash := leafHash(leaf)
+	tree.setNode(database, batch, 0, index, hash)
+
+	level := 0
+	for i := index; i&1 == 1; i >>= 1 {
+		hash = innerHash(tree.peaks[level], hash)
+		tree.peaks[level] = nil
+		level++
+		tree.setNode(database, batch, uint8(level), i>>1, hash)
+	}
+	tree.setPeak(level, hash)
+	tree.size++
+
+	batch.SetColumnFamily(database.ColumnFamilyHandles()[consts.DefaultCFNum], treeSizeKey, uint64Bytes(tree.size))
+	return index
+}
+
+// replace는 index번째 leaf를 leaf로 교체하고 다시 계산한 node를 batch에 기록함.
+func (tree *stateTree) replace(database db.DB, batch db.Batch, index uint64, leaf []byte) error {
+	if index >= tree.size {
+		return errors.Errorf("leaf index %v is out of tree size %v", index, tree.size)
+	}
+	hash := leafHash(leaf)
+	tree.setNode(database, batch, 0, index, hash)
+
+	// leaf를 포함하는 완성된 perfect subtree를 따라 올라가며 마지막 subtree는 peak임
+	level := 0
+	for i := index; ((i|1)+1)<<uint(level) <= tree.size; i >>= 1 {
+		sibling, err := tree.node(database, uint8(level), i^1)
+		if err != nil {
+			return err
+		}
+		if i&1 == 0 {
+			hash = innerHash(hash, sibling)
+		} else {
+			hash = innerHash(sibling, hash)
+		}
+		level++
+		tree.setNode(database, batch, uint8(level), i>>1, hash)
+	}
+	tree.setPeak(level, hash)
+
+	return nil
+}
+
+// committed는 batch가 DB에 write된 뒤 아직 write되지 않은 node를 비움.
+func (tree *stateTree) committed() {
+	tree.nodes = nil
+}
+
+func (tree *stateTree) setNode(database db.DB, batch db.Batch, level uint8, index uint64, hash []byte) {
+	if tree.nodes == nil {
+		tree.nodes = make(map[string][]byte)
+	}
+	key := nodeKey(level, index)
+	tree.nodes[string(key)] = hash
+	batch.SetColumnFamily(database.ColumnFamilyHandles()[consts.ProofCFNum], key, hash)
+}
+
+func (tree *stateTree) node(database db.Reader, level uint8, index uint64) ([]byte, error) {
+	if hash, ok := tree.nodes[string(nodeKey(level, index))]; ok {
+		return hash, nil
+	}
+	return getNode(database, level, index)
+}
+
+// root는 tree의 merkle root를 return. leaf가 없으면 nil.
+func (tree *stateTree) root() []byte {
+	var root []byte
+	for _, peak := range tree.peaks {
+		if peak == nil {
+			continue
+		}
+		if root == nil {
+			root = peak
+		} else {
+			root = innerHash(peak, root)
+		}
+	}
+	return root
+}
+
+func (tree *stateTree) setPeak(level int, hash []byte) {
+	for len(tree.peaks) <= level {
+		tree.peaks = append(tree.peaks, nil)
+	}
+	tree.peaks[level] = hash
+}
+
+// rowProof는 size개의 leaf를 가진 tree에서 rowKey leaf의 RowProof를 return.
+// rowKey가 proof를 갖고 있지 않거나 size 이후에 write되었으면 ok가 false.
+func rowProof(database db.Reader, rowKey []byte, size uint64) (proof types.RowProof, ok bool, err error) {
+	entry, err := getRowEntry(database, rowKey)
+	if err != nil || entry == nil {
+		return proof, false, err
+	}
+
+	index := binary.BigEndian.Uint64(entry[0:8])
+	if index >= size {
+		return proof, false, nil
+	}
+
+	aunts, err := proofAunts(database, 0, index, size)
+	if err != nil {
+		return proof, false, err
+	}
+	proof = types.RowProof{
+		Index:    int(index),
+		Total:    int(size),
+		MetaHash: entry[8 : 8+tmhash.Size],
+		RealHash: entry[8+tmhash.Size:],
+		Aunts:    aunts,
+	}
+
+	return proof, true, nil
+}
+
+// rowIndex는 rowKey leaf의 index를 return. rowKey가 tree에 없으면 ok가 false.
+func rowIndex(database db.Reader, rowKey []byte) (index uint64, ok bool, err error) {
+	entry, err := getRowEntry(database, rowKey)
+	if err != nil || entry == nil {
+		return 0, false, err
+	}
+
+	return binary.BigEndian.Uint64(entry[0:8]), true, nil
+}
+
+// getRowEntry는 rowKey의 row entry를 return. 없으면 nil.
+func getRowEntry(database db.Reader, rowKey []byte) ([]byte, error) {
+	slice, err := database.GetDataFromColumnFamily(consts.ProofCFNum, rowEntryKey(rowKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	if !slice.Exists() || slice.Size() != rowEntryLen {
+		return nil, nil
+	}
+
+	return append([]byte{}, slice.Data()...), nil
+}
+
+// setRowEntry는 rowKey의 leaf index와 hash를 batch에 기록함.
+func setRowEntry(database db.DB, batch db.Batch, rowKey []byte, index uint64, metaHash, realHash []byte) {
+	entry := make([]byte, 0, rowEntryLen)
+	entry = append(entry, uint64Bytes(index)...)
+	entry = append(entry, metaHash...)
+	entry = append(entry, realHash...)
+	batch.SetColumnFamily(database.ColumnFamilyHandles()[consts.ProofCFNum], rowEntryKey(rowKey), entry)
+}
+
+// proofAunts는 offset부터 size개의 leaf를 가진 subtree에서 index번째 leaf의 aunt를 leaf의 sibling부터 root의 child 순서로 return.
+// tendermint simple tree와 같이 size보다 작은 가장 큰 2의 거듭제곱에서 나눔.
+func proofAunts(database db.Reader, offset, index, size uint64) ([][]byte, error) {
+	if size == 1 {
+		return nil, nil
+	}
+
+	k := splitPoint(size)
+	var aunts [][]byte
+	var sibling []byte
+	var err error
+	if index < k {
+		if aunts, err = proofAunts(database, offset, index, k); err != nil {
+			return nil, err
+		}
+		sibling, err = subtreeHash(database, offset+k, size-k)
+	} else {
+		if aunts, err = proofAunts(database, offset+k, index-k, size-k); err != nil {
+			return nil, err
+		}
+		sibling, err = subtreeHash(database, offset, k)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return append(aunts, sibling), nil
+}
+
+// subtreeHash는 offset부터 size개의 leaf를 가진 subtree의 hash를 저장된 perfect subtree node로 계산함.
+func subtreeHash(database db.Reader, offset, size uint64) ([]byte, error) {
+	if size&(size-1) == 0 {
+		level := bits.TrailingZeros64(size)
+		return getNode(database, uint8(level), offset>>uint(level))
+	}
+
+	k := splitPoint(size)
+	left, err := subtreeHash(database, offset, k)
+	if err != nil {
+		return nil, err
+	}
+	right, err := subtreeHash(database, offset+k, size-k)
+	if err != nil {
+		return nil, err
+	}
+
+	return innerHash(left, right), nil
+}
+
+func getNode(database db.Reader, level uint8, index uint64) ([]byte, error) {
+	slice, err := database.GetDataFromColumnFamily(consts.ProofCFNum, nodeKey(level, index))
+	if err != nil {
+		return nil, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	if !slice.Exists() {
+		return nil, errors.Errorf("merkle node (%v, %v) not found", level, index)
+	}
+
+	return append([]byte{}, slice.Data()...), nil
+}
+
+func getUint64(database db.Reader, cfNum int, key []byte) (uint64, error) {
+	slice, err := database.GetDataFromColumnFamily(cfNum, key)
+	if err != nil {
+		return 0, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	if !slice.Exists() {
+		return 0, nil
+	}
+	if slice.Size() != 8 {
+		return 0, errors.Errorf("wrong value length of %s. Expect 8, got %v", key, slice.Size())
+	}
+
+	return binary.BigEndian.Uint64(slice.Data()), nil
+}
+
+func nodeKey(level uint8, index uint64) []byte {
+	key := make([]byte, 0, len(nodeKeyPrefix)+9)
+	key = append(key, nodeKeyPrefix...)
+	key = append(key, level)
+	return append(key, uint64Bytes(index)...)
+}
+
+func rowEntryKey(rowKey []byte) []byte {
+	key := make([]byte, 0, len(rowKeyPrefix)+len(rowKey))
+	key = append(key, rowKeyPrefix...)
+	return append(key, rowKey...)
+}
+
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// splitPoint는 length보다 작은 가장 큰 2의 거듭제곱을 return.
+func splitPoint(length uint64) uint64 {
+	k := uint64(1) << uint(63-bits.LeadingZeros64(length))
+	if k == length {
+		k >>= 1
+	}
+	return k
+}
+
+// leafHash, innerHash는 tendermint simple merkle tree의 leaf, inner node hash와 같음.
+func leafHash(leaf []byte) []byte {
+	return merkle.SimpleHashFromByteSlices([][]byte{leaf})
+}
+
+func innerHash(left, right []byte) []byte {
+	data := make([]byte, 0, 1+len(left)+len(right))
+	data = append(data, 1)
+	data = append(data, left...)
+	return tmhash.Sum(append(data, right...))
+}
@@ -0,0 +1,167 @@
+package master
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "master"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of delivered transactions.
+	TxsDelivered metrics.Counter
+	// Number of delivered data objects.
+	ObjectsDelivered metrics.Counter
+	// Number of transactions rejected by CheckTx, labeled by reason.
+	CheckTxRejects metrics.Counter
+	// Number of keys written per Commit.
+	CommitBatchSize metrics.Histogram
+	// Time spent writing batches in Commit, in seconds.
+	CommitLatency metrics.Histogram
+	// Time spent serving a Query, in seconds, labeled by path.
+	QueryLatency metrics.Histogram
+	// Number of rows, series, points or rollup buckets read by all query paths.
+	RowsScanned metrics.Counter
+	// Number of rows, points, buckets or groups returned by all query paths.
+	RowsReturned metrics.Counter
+	// Approximate size of active and unflushed memtables in bytes, labeled by column_family.
+	MemtableSize metrics.Gauge
+	// 1 if at least one compaction is pending, labeled by column_family.
+	CompactionPending metrics.Gauge
+	// Total size of all SST files in bytes, labeled by column_family.
+	SSTFilesSize metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library
+// and registered on registerer. Collectors already registered on registerer
+// with the same options are reused, so several MasterApplications in one
+// process share the same metrics instead of panicking.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(registerer stdprometheus.Registerer, namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		TxsDelivered: prometheus.NewCounter(registerCounter(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "txs_delivered",
+			Help:      "Number of delivered transactions.",
+		}, labels)).With(labelsAndValues...),
+		ObjectsDelivered: prometheus.NewCounter(registerCounter(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "objects_delivered",
+			Help:      "Number of delivered data objects.",
+		}, labels)).With(labelsAndValues...),
+		CheckTxRejects: prometheus.NewCounter(registerCounter(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "check_tx_rejects",
+			Help:      "Number of transactions rejected by CheckTx.",
+		}, append(labels, "reason"))).With(labelsAndValues...),
+		CommitBatchSize: prometheus.NewHistogram(registerHistogram(registerer, stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "commit_batch_size",
+			Help:      "Number of keys written per commit.",
+			Buckets:   stdprometheus.ExponentialBuckets(1, 4, 10),
+		}, labels)).With(labelsAndValues...),
+		CommitLatency: prometheus.NewHistogram(registerHistogram(registerer, stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "commit_latency_seconds",
+			Help:      "Time spent writing batches in commit, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, labels)).With(labelsAndValues...),
+		QueryLatency: prometheus.NewHistogram(registerHistogram(registerer, stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "query_latency_seconds",
+			Help:      "Time spent serving a query, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, append(labels, "path"))).With(labelsAndValues...),
+		RowsScanned: prometheus.NewCounter(registerCounter(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rows_scanned",
+			Help:      "Number of rows, points or rollup buckets read by queries.",
+		}, labels)).With(labelsAndValues...),
+		RowsReturned: prometheus.NewCounter(registerCounter(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rows_returned",
+			Help:      "Number of rows, points, buckets or groups returned by queries.",
+		}, labels)).With(labelsAndValues...),
+		MemtableSize: prometheus.NewGauge(registerGauge(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rocksdb_memtable_size_bytes",
+			Help:      "Approximate size of active and unflushed memtables in bytes.",
+		}, append(labels, "column_family"))).With(labelsAndValues...),
+		CompactionPending: prometheus.NewGauge(registerGauge(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rocksdb_compaction_pending",
+			Help:      "1 if at least one compaction is pending, otherwise 0.",
+		}, append(labels, "column_family"))).With(labelsAndValues...),
+		SSTFilesSize: prometheus.NewGauge(registerGauge(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rocksdb_sst_files_size_bytes",
+			Help:      "Total size of all SST files in bytes.",
+		}, append(labels, "column_family"))).With(labelsAndValues...),
+	}
+}
+
+// registerCounter는 opts의 CounterVec을 registerer에 등록하고 return. 이미 등록되어 있으면 기존 CounterVec을 return.
+func registerCounter(registerer stdprometheus.Registerer, opts stdprometheus.CounterOpts, labels []string) *stdprometheus.CounterVec {
+	return register(registerer, stdprometheus.NewCounterVec(opts, labels)).(*stdprometheus.CounterVec)
+}
+
+// registerHistogram은 opts의 HistogramVec을 registerer에 등록하고 return. 이미 등록되어 있으면 기존 HistogramVec을 return.
+func registerHistogram(registerer stdprometheus.Registerer, opts stdprometheus.HistogramOpts, labels []string) *stdprometheus.HistogramVec {
+	return register(registerer, stdprometheus.NewHistogramVec(opts, labels)).(*stdprometheus.HistogramVec)
+}
+
+// registerGauge는 opts의 GaugeVec을 registerer에 등록하고 return. 이미 등록되어 있으면 기존 GaugeVec을 return.
+func registerGauge(registerer stdprometheus.Registerer, opts stdprometheus.GaugeOpts, labels []string) *stdprometheus.GaugeVec {
+	return register(registerer, stdprometheus.NewGaugeVec(opts, labels)).(*stdprometheus.GaugeVec)
+}
+
+func register(registerer stdprometheus.Registerer, collector stdprometheus.Collector) stdprometheus.Collector {
+	if err := registerer.Register(collector); err != nil {
+		if alreadyRegistered, ok := err.(stdprometheus.AlreadyRegisteredError); ok {
+			return alreadyRegistered.ExistingCollector
+		}
+		// 같은 이름을 다른 label로 등록하는 등 code의 오류이므로 prometheus.MustRegister와 같이 panic함
+		panic(err)
+	}
+	return collector
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		TxsDelivered:      discard.NewCounter(),
+		ObjectsDelivered:  discard.NewCounter(),
+		CheckTxRejects:    discard.NewCounter(),
+		CommitBatchSize:   discard.NewHistogram(),
+		CommitLatency:     discard.NewHistogram(),
+		QueryLatency:      discard.NewHistogram(),
+		RowsScanned:       discard.NewCounter(),
+		RowsReturned:      discard.NewCounter(),
+		MemtableSize:      discard.NewGauge(),
+		CompactionPending: discard.NewGauge(),
+		SSTFilesSize:      discard.NewGauge(),
+	}
+}
@@ -0,0 +1,76 @@
+package master_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+func (suite *MasterSuite) TestMasterApplication_metrics() {
+	require := suite.Require()
+
+	//given
+	// Prometheus를 켠 app을 두 번 생성해도 같은 metric을 공유하며 panic하지 않음
+	openApp := func() {
+		suite.openApp(func(cfg *config.Config) {
+			cfg.Instrumentation.Prometheus = true
+			cfg.Instrumentation.Namespace = "metricstest"
+		})
+	}
+	openApp()
+	openApp()
+
+	srv := httptest.NewServer(promhttp.Handler())
+	defer srv.Close()
+	scrape := func() map[string]float64 {
+		res, err := http.Get(srv.URL)
+		require.Nil(err)
+		defer res.Body.Close()
+		samples := make(map[string]float64)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "metricstest_") {
+				continue
+			}
+			i := strings.LastIndex(line, " ")
+			value, err := strconv.ParseFloat(line[i+1:], 64)
+			require.Nil(err)
+			samples[line[:i]] = value
+		}
+		require.Nil(scanner.Err())
+		return samples
+	}
+	before := scrape()
+
+	//when
+	tx, err := json.Marshal(givenBaseDataObjs)
+	require.Nil(err)
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(tx).Code)
+	suite.app.Commit()
+	require.NotEqual(code.CodeTypeOK, suite.app.CheckTx([]byte("not json")).Code)
+	queryData, err := json.Marshal(types.QueryObj{Start: 1545982882435375000, End: 1545982882435375002, Qualifier: []byte{}})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.Query(abciTypes.RequestQuery{Data: queryData, Path: consts.QueryPath}).Code)
+
+	//then
+	after := scrape()
+	delta := func(sample string) float64 {
+		return after[sample] - before[sample]
+	}
+	require.Equal(float64(1), delta("metricstest_master_txs_delivered"))
+	require.Equal(float64(2), delta("metricstest_master_objects_delivered"))
+	require.Equal(float64(1), delta(`metricstest_master_check_tx_rejects{reason="encoding"}`))
+	require.Equal(float64(1), delta(`metricstest_master_query_latency_seconds_count{path="/query"}`))
+	require.Equal(float64(1), delta("metricstest_master_commit_latency_seconds_count"))
+}
@@ -0,0 +1,170 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/log"
+	"github.com/paust-team/paust-db/master"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_prove() {
+	require := suite.Require()
+
+	//given
+	// block마다 다른 개수의 row를 write하여 tree가 여러 perfect subtree로 나뉘도록 함
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	var leaves [][]byte
+	var appHash []byte
+	timestamp := uint64(1545982882435375000)
+	for height, rows := range []int{3, 4, 6} {
+		suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: int64(height + 1)}})
+		var baseDataObjs []types.BaseDataObj
+		for i := 0; i < rows; i++ {
+			rowKey := types.GetRowKey(timestamp, 0)
+			timestamp++
+			baseDataObjs = append(baseDataObjs, types.BaseDataObj{
+				MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId, Qualifier: []byte("Memory")},
+				RealData: types.RealDataObj{RowKey: rowKey, Data: []byte{byte(i)}},
+			})
+			leaves = append(leaves, types.RowLeaf(rowKey, types.MetaDataHash(TestOwnerId, []byte("Memory")), types.RealDataHash([]byte{byte(i)})))
+		}
+		givenTx, err := json.Marshal(baseDataObjs)
+		require.Nil(err)
+		require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+		appHash = suite.app.Commit().Data
+	}
+
+	//when
+	queryDataObj := types.QueryDataObj{QueryObj: types.QueryObj{Start: 1545982882435375000, End: timestamp, Qualifier: []byte{}}}
+	queryData, err := json.Marshal(queryDataObj)
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: queryData, Path: consts.QueryDataPath, Prove: true})
+
+	//then
+	require.Equal(code.CodeTypeOK, res.Code)
+	require.Equal(int64(3), res.Height)
+	// app hash는 모든 leaf의 tendermint simple merkle root와 같음
+	require.Equal(merkle.SimpleHashFromByteSlices(leaves), appHash)
+
+	var queryDataResObj types.QueryDataResObj
+	require.Nil(json.Unmarshal(res.Value, &queryDataResObj))
+	require.Equal(len(leaves), len(queryDataResObj.Data))
+	require.Equal(len(leaves), len(res.Proof.Ops))
+	for i, op := range res.Proof.Ops {
+		baseDataObj := queryDataResObj.Data[i]
+		require.Equal(consts.RowProofOpType, op.Type)
+		require.Equal(baseDataObj.MetaData.RowKey, op.Key)
+
+		var rowProof types.RowProof
+		require.Nil(json.Unmarshal(op.Data, &rowProof))
+		require.Equal(types.MetaDataHash(baseDataObj.MetaData.OwnerId, baseDataObj.MetaData.Qualifier), rowProof.MetaHash)
+		require.Equal(types.RealDataHash(baseDataObj.RealData.Data), rowProof.RealHash)
+		require.Equal(appHash, rowProof.ComputeRootHash(op.Key))
+	}
+
+	// 재시작 후 Info는 마지막 height와 app hash를 return
+	suite.app.Destroy()
+	suite.app, err = master.NewMasterApplication(true, testDir, log.AllowDebug())
+	require.Nil(err)
+	info := suite.app.Info(abciTypes.RequestInfo{})
+	require.Equal(int64(3), info.LastBlockHeight)
+	require.Equal(appHash, info.LastBlockAppHash)
+
+	// 재시작 후에도 이어서 tree에 추가됨
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 4}})
+	rowKey := types.GetRowKey(timestamp, 0)
+	givenTx, err := json.Marshal([]types.BaseDataObj{{MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId, Qualifier: []byte("Memory")}, RealData: types.RealDataObj{RowKey: rowKey, Data: []byte("data")}}})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	leaves = append(leaves, types.RowLeaf(rowKey, types.MetaDataHash(TestOwnerId, []byte("Memory")), types.RealDataHash([]byte("data"))))
+	require.Equal(merkle.SimpleHashFromByteSlices(leaves), suite.app.Commit().Data)
+}
+
+func (suite *MasterSuite) TestMasterApplication_Query_proveOverwrite() {
+	require := suite.Require()
+
+	//given
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	var leaves [][]byte
+	var rowKeys [][]byte
+	timestamp := uint64(1545982882435375000)
+	for height, rows := range []int{3, 4, 6} {
+		suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: int64(height + 1)}})
+		var baseDataObjs []types.BaseDataObj
+		for i := 0; i < rows; i++ {
+			rowKey := types.GetRowKey(timestamp, 0)
+			timestamp++
+			baseDataObjs = append(baseDataObjs, types.BaseDataObj{
+				MetaData: types.MetaDataObj{RowKey: rowKey, OwnerId: TestOwnerId, Qualifier: []byte("Memory")},
+				RealData: types.RealDataObj{RowKey: rowKey, Data: []byte{byte(i)}},
+			})
+			rowKeys = append(rowKeys, rowKey)
+			leaves = append(leaves, types.RowLeaf(rowKey, types.MetaDataHash(TestOwnerId, []byte("Memory")), types.RealDataHash([]byte{byte(i)})))
+		}
+		givenTx, err := json.Marshal(baseDataObjs)
+		require.Nil(err)
+		require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+		suite.app.Commit()
+	}
+
+	// 새 row 뒤에 overwrite하여 같은 block에서 추가된 node로 교체된 leaf의 hash를 다시 계산하도록 함
+	newRowKey := types.GetRowKey(timestamp, 0)
+	newObj := types.BaseDataObj{
+		MetaData: types.MetaDataObj{RowKey: newRowKey, OwnerId: TestOwnerId, Qualifier: []byte("Memory")},
+		RealData: types.RealDataObj{RowKey: newRowKey, Data: []byte("new")},
+	}
+	overwriteObjs := []types.BaseDataObj{newObj}
+	leaves = append(leaves, types.RowLeaf(newRowKey, types.MetaDataHash(TestOwnerId, []byte("Memory")), types.RealDataHash([]byte("new"))))
+	for _, i := range []int{0, 5, 12} {
+		overwriteObjs = append(overwriteObjs, types.BaseDataObj{
+			MetaData: types.MetaDataObj{RowKey: rowKeys[i], OwnerId: TestOwnerId, Qualifier: []byte("Memory")},
+			RealData: types.RealDataObj{RowKey: rowKeys[i], Data: []byte("overwritten")},
+			Conflict: consts.ConflictOverwrite,
+		})
+		leaves[i] = types.RowLeaf(rowKeys[i], types.MetaDataHash(TestOwnerId, []byte("Memory")), types.RealDataHash([]byte("overwritten")))
+	}
+	overwriteTx, err := json.Marshal(overwriteObjs)
+	require.Nil(err)
+
+	//when
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 4}})
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(overwriteTx).Code)
+	appHash := suite.app.Commit().Data
+
+	//then
+	// overwrite된 rowKey의 leaf는 새 leaf를 추가하지 않고 같은 자리에서 교체됨
+	require.Equal(merkle.SimpleHashFromByteSlices(leaves), appHash)
+
+	fetchData, err := json.Marshal(types.FetchObj{RowKeys: [][]byte{rowKeys[5]}})
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: fetchData, Path: consts.FetchPath, Prove: true})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	require.Equal(1, len(res.Proof.Ops))
+	var rowProof types.RowProof
+	require.Nil(json.Unmarshal(res.Proof.Ops[0].Data, &rowProof))
+	require.Equal(appHash, rowProof.ComputeRootHash(rowKeys[5]))
+	// 대체된 데이터는 현재 app hash로 검증되지 않음
+	require.Equal(types.RealDataHash([]byte("overwritten")), rowProof.RealHash)
+	rowProof.RealHash = types.RealDataHash([]byte{byte(2)})
+	require.NotEqual(appHash, rowProof.ComputeRootHash(rowKeys[5]))
+
+	// 재시작 후에도 교체된 tree에 이어서 leaf를 교체함
+	suite.app.Destroy()
+	suite.app, err = master.NewMasterApplication(true, testDir, log.AllowDebug())
+	require.Nil(err)
+	require.Equal(appHash, suite.app.Info(abciTypes.RequestInfo{}).LastBlockAppHash)
+
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 5}})
+	overwriteObj := overwriteObjs[0]
+	overwriteObj.RealData.Data = []byte("again")
+	overwriteObj.Conflict = consts.ConflictOverwrite
+	overwriteTx, err = json.Marshal([]types.BaseDataObj{overwriteObj})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(overwriteTx).Code)
+	leaves[13] = types.RowLeaf(newRowKey, types.MetaDataHash(TestOwnerId, []byte("Memory")), types.RealDataHash([]byte("again")))
+	require.Equal(merkle.SimpleHashFromByteSlices(leaves), suite.app.Commit().Data)
+}
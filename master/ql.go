@@ -0,0 +1,252 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/paust-team/paust-db/libs/ql"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	"math"
+	"sort"
+)
+
+// qlGroupKey는 집계 query에서 데이터가 속한 group.
+type qlGroupKey struct {
+	ownerId string
+	start   uint64
+}
+
+// qlQuery는 qlQueryObj의 query를 query plan으로 parse하여 time range의 metadata를 한 번 scan하며 실행함.
+// 조건 검사 결과는 series마다 한 번만 계산하며 집계 함수는 숫자 데이터만 집계함.
+// chunk storage가 켜져 있으면 집계 query는 metadata 대신 조건에 맞는 series의 chunk와 rollup을 read하여 집계함.
+func (app *MasterApplication) qlQuery(reader db.Reader, qlQueryObj types.QLQueryObj) (types.QLResObj, error) {
+	var qlResObj types.QLResObj
+	query, err := ql.Parse(qlQueryObj.Query)
+	if err != nil {
+		return qlResObj, errors.Wrap(err, "parse query err")
+	}
+
+	if len(query.Fields) > 0 && app.chunkStorage {
+		seriesObjs, ok, err := app.qlSeries(reader, query)
+		if err != nil {
+			return qlResObj, err
+		}
+		if ok {
+			return app.qlChunkQuery(reader, query, seriesObjs)
+		}
+	}
+
+	startByte := types.TimestampKey(query.Start)
+	endByte := types.TimestampKey(query.End)
+	itr := reader.IteratorColumnFamily(startByte, endByte, reader.ColumnFamilyHandles()[consts.MetaCFNum])
+	defer itr.Close()
+
+	var fetchObj types.FetchObj
+	var metaDataObjs []types.MetaDataObj
+	var groupKeys []qlGroupKey
+	groups := make(map[qlGroupKey]*types.BucketObj)
+	matches := make(map[string]bool)
+	scanned := 0
+	resolver := newSeriesResolver(reader)
+	for itr.Seek(startByte); itr.Valid() && bytes.Compare(itr.Key(), endByte) == -1; itr.Next() {
+		ownerId, qualifier, err := resolver.resolve(itr.Value())
+		if err != nil {
+			return qlResObj, err
+		}
+		scanned++
+		key := string(seriesKey(ownerId, qualifier))
+		match, ok := matches[key]
+		if !ok {
+			match = query.Match(ownerId, qualifier)
+			matches[key] = match
+		}
+		if !match {
+			continue
+		}
+
+		rowKey := make([]byte, len(itr.Key()))
+		copy(rowKey, itr.Key())
+		if len(query.Fields) == 0 {
+			fetchObj.RowKeys = append(fetchObj.RowKeys, rowKey)
+			metaDataObjs = append(metaDataObjs, types.MetaDataObj{RowKey: rowKey, OwnerId: ownerId, Qualifier: qualifier})
+			if query.Limit > 0 && len(metaDataObjs) >= query.Limit {
+				break
+			}
+			continue
+		}
+
+		valueSlice, err := reader.GetDataFromColumnFamily(consts.RealCFNum, rowKey)
+		if err != nil {
+			return qlResObj, errors.Wrap(err, "GetDataFromColumnFamily err")
+		}
+		value, ok := numericValue(valueSlice.Data())
+		valueSlice.Free()
+		if !ok {
+			continue
+		}
+
+		// rowKey는 timestamp 순서이므로 group마다 first, last가 시간 순서로 집계됨
+		groupKey := qlGroupKey{start: query.Start}
+		if query.GroupByTime > 0 {
+			timestamp := binary.BigEndian.Uint64(rowKey[0:consts.TimestampLen])
+			groupKey.start = query.Start + (timestamp-query.Start)/query.GroupByTime*query.GroupByTime
+		}
+		if query.GroupByOwner {
+			groupKey.ownerId = ownerId
+		}
+		groupKeys = mergeQLGroup(groups, groupKeys, groupKey, types.BucketObj{Count: 1, Sum: value, Min: value, Max: value, First: value, Last: value})
+	}
+	app.metrics.RowsScanned.Add(float64(scanned))
+
+	if len(query.Fields) == 0 {
+		realDataObjs, err := app.realDataFetch(reader, fetchObj)
+		if err != nil {
+			return qlResObj, err
+		}
+		for i := range metaDataObjs {
+			qlResObj.Data = append(qlResObj.Data, types.BaseDataObj{MetaData: metaDataObjs[i], RealData: realDataObjs[i]})
+		}
+		app.metrics.RowsReturned.Add(float64(len(qlResObj.Data)))
+		return qlResObj, nil
+	}
+
+	qlResObj = qlGroupResult(query, groups, groupKeys)
+	app.metrics.RowsReturned.Add(float64(len(qlResObj.Groups)))
+
+	return qlResObj, nil
+}
+
+// qlSeries는 query 조건에 맞는 series를 id 순서로 return.
+// bucket은 first, last의 시각을 담지 않으므로 first, last 집계에서 여러 series가 한 group에 속하면 false를 return.
+func (app *MasterApplication) qlSeries(reader db.Reader, query *ql.Query) ([]types.SeriesObj, bool, error) {
+	allSeries, err := app.seriesQuery(reader, types.SeriesQueryObj{})
+	if err != nil {
+		return nil, false, err
+	}
+	ordered := false
+	for _, field := range query.Fields {
+		ordered = ordered || field.Func == "first" || field.Func == "last"
+	}
+
+	var seriesObjs []types.SeriesObj
+	groupOwners := make(map[string]bool)
+	for _, seriesObj := range allSeries {
+		if !query.Match(seriesObj.OwnerId, seriesObj.Qualifier) {
+			continue
+		}
+		var groupOwner string
+		if query.GroupByOwner {
+			groupOwner = seriesObj.OwnerId
+		}
+		if ordered && groupOwners[groupOwner] {
+			return nil, false, nil
+		}
+		groupOwners[groupOwner] = true
+		seriesObjs = append(seriesObjs, seriesObj)
+	}
+
+	return seriesObjs, true, nil
+}
+
+// qlChunkQuery는 seriesObjs의 chunk와 rollup을 /range와 같은 방식으로 bucket 집계하여 query의 group으로 합침.
+// GROUP BY time이 없으면 time range 전체를 하나의 bucket으로 집계하며 time range가 비어 있으면 group 없이 column만 return.
+// chunk가 없는 데이터(chunk storage 도입 이전에 write된 데이터)는 집계에 포함되지 않음.
+func (app *MasterApplication) qlChunkQuery(reader db.Reader, query *ql.Query, seriesObjs []types.SeriesObj) (types.QLResObj, error) {
+	// step이 0인 bucket은 만들 수 없으므로 빈 time range는 chunk를 read하지 않음
+	if query.End <= query.Start {
+		return types.QLResObj{Columns: query.Columns()}, nil
+	}
+	rangeQueryObj := types.RangeQueryObj{Start: query.Start, End: query.End, Step: query.GroupByTime}
+	if rangeQueryObj.Step == 0 {
+		rangeQueryObj.Step = query.End - query.Start
+	}
+
+	var groupKeys []qlGroupKey
+	groups := make(map[qlGroupKey]*types.BucketObj)
+	scanned := 0
+	for _, seriesObj := range seriesObjs {
+		buckets, _, seriesScanned, err := app.seriesBuckets(reader, seriesObj.Id, rangeQueryObj)
+		if err != nil {
+			return types.QLResObj{}, err
+		}
+		scanned += seriesScanned
+		for _, bucket := range buckets {
+			groupKey := qlGroupKey{start: bucket.Start}
+			if query.GroupByOwner {
+				groupKey.ownerId = seriesObj.OwnerId
+			}
+			groupKeys = mergeQLGroup(groups, groupKeys, groupKey, bucket)
+		}
+	}
+	app.metrics.RowsScanned.Add(float64(scanned))
+
+	qlResObj := qlGroupResult(query, groups, groupKeys)
+	app.metrics.RowsReturned.Add(float64(len(qlResObj.Groups)))
+
+	return qlResObj, nil
+}
+
+// mergeQLGroup은 bucket을 groups의 groupKey group에 합치고 새 group이면 groupKeys에 추가하여 return.
+// 같은 group의 bucket은 시간 순서로 합쳐야 first, last가 맞게 집계됨.
+func mergeQLGroup(groups map[qlGroupKey]*types.BucketObj, groupKeys []qlGroupKey, groupKey qlGroupKey, bucket types.BucketObj) []qlGroupKey {
+	group, ok := groups[groupKey]
+	if !ok {
+		bucket.Start = groupKey.start
+		groups[groupKey] = &bucket
+		return append(groupKeys, groupKey)
+	}
+
+	group.Count += bucket.Count
+	group.Sum += bucket.Sum
+	group.Min = math.Min(group.Min, bucket.Min)
+	group.Max = math.Max(group.Max, bucket.Max)
+	group.Last = bucket.Last
+	return groupKeys
+}
+
+// qlGroupResult는 groups를 ownerId, 시작 시각 순서로 정렬하여 최대 query.Limit개의 group을 query의 column으로 return.
+func qlGroupResult(query *ql.Query, groups map[qlGroupKey]*types.BucketObj, groupKeys []qlGroupKey) types.QLResObj {
+	var qlResObj types.QLResObj
+	sort.Slice(groupKeys, func(i, j int) bool {
+		if groupKeys[i].ownerId != groupKeys[j].ownerId {
+			return groupKeys[i].ownerId < groupKeys[j].ownerId
+		}
+		return groupKeys[i].start < groupKeys[j].start
+	})
+	if query.Limit > 0 && len(groupKeys) > query.Limit {
+		groupKeys = groupKeys[:query.Limit]
+	}
+	qlResObj.Columns = query.Columns()
+	for _, groupKey := range groupKeys {
+		group := groups[groupKey]
+		qlGroupObj := types.QLGroupObj{Time: groupKey.start, OwnerId: groupKey.ownerId}
+		for _, field := range query.Fields {
+			qlGroupObj.Values = append(qlGroupObj.Values, fieldValue(field, group))
+		}
+		qlResObj.Groups = append(qlResObj.Groups, qlGroupObj)
+	}
+
+	return qlResObj
+}
+
+// fieldValue는 group의 집계 결과에서 field 집계 함수의 value를 return.
+func fieldValue(field ql.Field, group *types.BucketObj) float64 {
+	switch field.Func {
+	case "count":
+		return float64(group.Count)
+	case "sum":
+		return group.Sum
+	case "avg":
+		return group.Sum / float64(group.Count)
+	case "min":
+		return group.Min
+	case "max":
+		return group.Max
+	case "first":
+		return group.First
+	default:
+		return group.Last
+	}
+}
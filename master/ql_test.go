@@ -0,0 +1,138 @@
+package master_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"time"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_ql() {
+	require := suite.Require()
+
+	//given
+	// 두 owner의 temperature, humidity series에 숫자와 숫자가 아닌 데이터를 write함
+	start := uint64(1545982860000000000)
+	temperature := `{"type":"temperature"}`
+	first := givenDataObj(start, TestOwnerId, temperature, "1")
+	second := givenDataObj(start+uint64(30*time.Second), TestOwnerId, temperature, "3")
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	tx, err := json.Marshal([]types.BaseDataObj{
+		first,
+		givenDataObj(start+uint64(10*time.Second), TestOwnerId2, temperature, "8"),
+		givenDataObj(start+uint64(20*time.Second), TestOwnerId, `{"type":"humidity"}`, "50"),
+		second,
+		givenDataObj(start+uint64(40*time.Second), TestOwnerId, temperature, "broken"),
+		givenDataObj(start+uint64(70*time.Second), TestOwnerId, temperature, "5"),
+	})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(tx).Code)
+	suite.app.Commit()
+
+	queryQL := func(query string) abciTypes.ResponseQuery {
+		qlData, err := json.Marshal(types.QLQueryObj{Query: fmt.Sprintf(query, start, start+uint64(2*time.Minute))})
+		require.Nil(err)
+		return suite.app.Query(abciTypes.RequestQuery{Data: qlData, Path: consts.QLPath, Prove: true})
+	}
+	qlResult := func(res abciTypes.ResponseQuery) types.QLResObj {
+		require.Equal(code.CodeTypeOK, res.Code, res.Log)
+		var qlResObj types.QLResObj
+		require.Nil(json.Unmarshal(res.Value, &qlResObj))
+		return qlResObj
+	}
+
+	//when
+	actual := qlResult(queryQL("SELECT avg(value), count(value), max(value) FROM qualifier.type='temperature' WHERE time >= %v AND time < %v GROUP BY time(1m)"))
+
+	//then
+	// qualifier JSON의 type이 일치하는 series의 숫자 데이터만 1분 group으로 집계함
+	require.Equal(types.QLResObj{
+		Columns: []string{"avg(value)", "count(value)", "max(value)"},
+		Groups: []types.QLGroupObj{
+			{Time: start, Values: []float64{4, 3, 8}},
+			{Time: start + uint64(time.Minute), Values: []float64{5, 1, 5}},
+		},
+	}, actual)
+
+	// ownerId마다 집계하며 ownerId 조건을 함께 검사함
+	require.Equal([]types.QLGroupObj{
+		{Time: start, OwnerId: TestOwnerId, Values: []float64{1, 5, 9}},
+	}, qlResult(queryQL("SELECT first(value), last(value), sum(value) FROM qualifier.type='temperature' WHERE owner LIKE 'owner1%%' AND time >= %v AND time < %v GROUP BY owner")).Groups)
+
+	// SELECT *는 조건에 맞는 데이터를 LIMIT개까지 read하며 각 row의 proof를 함께 제공함
+	res := queryQL("SELECT * FROM qualifier.type='temperature' WHERE owner='owner1' AND time >= %v AND time < %v LIMIT 2")
+	require.Equal([]types.BaseDataObj{first, second}, qlResult(res).Data)
+	require.Equal(2, len(res.Proof.Ops))
+
+	// time range가 없으면 모든 데이터를 Unix epoch부터 나눈 group으로 집계함
+	qlData, err := json.Marshal(types.QLQueryObj{Query: "SELECT avg(value) FROM qualifier.type='temperature' GROUP BY time(1m)"})
+	require.Nil(err)
+	require.Equal([]types.QLGroupObj{
+		{Time: start, Values: []float64{4}},
+		{Time: start + uint64(time.Minute), Values: []float64{5}},
+	}, qlResult(suite.app.Query(abciTypes.RequestQuery{Data: qlData, Path: consts.QLPath})).Groups)
+
+	// 잘못된 query는 parse 단계에서 거부함
+	res = queryQL("SELECT avg(value) FROM * WHERE time >= %v AND time < %v GROUP BY qualifier")
+	require.Equal(code.CodeTypeEncodingError, res.Code)
+	require.Contains(res.Log, "expected time(<interval>) or owner")
+}
+
+func (suite *MasterSuite) TestMasterApplication_Query_ql_chunk() {
+	require := suite.Require()
+
+	//given
+	// 1초 chunk와 10초 rollup을 사용하는 app에 두 owner의 temperature, humidity series를 write함
+	suite.openApp(func(cfg *config.Config) {
+		cfg.Features.ChunkStorage = true
+		cfg.DB.ChunkInterval = time.Second
+		cfg.Rollup.Policies = []string{"*=10s"}
+	})
+	start := uint64(1545982860000000000)
+	temperature := `{"type":"temperature"}`
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.deliver(
+		givenDataObj(start, TestOwnerId, temperature, "1"),
+		givenDataObj(start+uint64(10*time.Second), TestOwnerId2, temperature, "8"),
+		givenDataObj(start+uint64(20*time.Second), TestOwnerId, `{"type":"humidity"}`, "50"),
+		givenDataObj(start+uint64(30*time.Second), TestOwnerId, temperature, "3"),
+		givenDataObj(start+uint64(40*time.Second), TestOwnerId, temperature, "broken"),
+		givenDataObj(start+uint64(70*time.Second), TestOwnerId, temperature, "5"),
+	)
+	suite.app.Commit()
+
+	queryQL := func(query string) []types.QLGroupObj {
+		qlData, err := json.Marshal(types.QLQueryObj{Query: fmt.Sprintf(query, start, start+uint64(2*time.Minute))})
+		require.Nil(err)
+		res := suite.app.Query(abciTypes.RequestQuery{Data: qlData, Path: consts.QLPath})
+		require.Equal(code.CodeTypeOK, res.Code, res.Log)
+		var qlResObj types.QLResObj
+		require.Nil(json.Unmarshal(res.Value, &qlResObj))
+		return qlResObj.Groups
+	}
+
+	//when
+	actual := queryQL("SELECT avg(value), count(value), max(value) FROM qualifier.type='temperature' WHERE time >= %v AND time < %v GROUP BY time(1m)")
+
+	//then
+	// chunk와 rollup을 집계해도 metadata를 scan한 것과 같은 결과를 return함
+	require.Equal([]types.QLGroupObj{
+		{Time: start, Values: []float64{4, 3, 8}},
+		{Time: start + uint64(time.Minute), Values: []float64{5, 1, 5}},
+	}, actual)
+
+	// ownerId마다 series가 하나이면 first, last도 chunk로 집계함
+	require.Equal([]types.QLGroupObj{
+		{Time: start, OwnerId: TestOwnerId, Values: []float64{1, 5, 9}},
+		{Time: start, OwnerId: TestOwnerId2, Values: []float64{8, 8, 8}},
+	}, queryQL("SELECT first(value), last(value), sum(value) FROM qualifier.type='temperature' WHERE time >= %v AND time < %v GROUP BY owner"))
+
+	// 여러 series가 한 group에 속하는 first, last는 metadata를 scan하여 시간 순서로 집계함
+	require.Equal([]types.QLGroupObj{
+		{Time: start, Values: []float64{1, 5}},
+	}, queryQL("SELECT first(value), last(value) FROM qualifier.type='temperature' WHERE time >= %v AND time < %v"))
+}
@@ -0,0 +1,219 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/paust-team/paust-db/libs/tsz"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+)
+
+// rollup column family key prefix. rollupPolicyPrefix는 series id로 series의 resolution을,
+// rollupBucketPrefix는 series id, resolution, bucket 시작 timestamp로 집계된 bucket을 찾음.
+var (
+	rollupPolicyPrefix = []byte("p")
+	rollupBucketPrefix = []byte("b")
+)
+
+func rollupPolicyKey(seriesId uint64) []byte {
+	return append(append([]byte{}, rollupPolicyPrefix...), uint64Bytes(seriesId)...)
+}
+
+func rollupBucketKey(seriesId uint64, resolution uint64, bucketStart uint64) []byte {
+	key := append(append([]byte{}, rollupBucketPrefix...), uint64Bytes(seriesId)...)
+	key = append(key, uint64Bytes(resolution)...)
+	return append(key, uint64Bytes(bucketStart)...)
+}
+
+// matchRollupPolicy는 ownerId, qualifier series와 일치하는 policy 중 가장 구체적인 policy의 resolution(nano second)을 return.
+// ownerId, qualifier가 모두 일치하는 policy, ownerId만 일치하는 policy, "*" policy 순서로 우선함.
+func matchRollupPolicy(policies []config.RollupPolicy, ownerId string, qualifier []byte) []uint64 {
+	rank := -1
+	var matched config.RollupPolicy
+	for _, policy := range policies {
+		policyRank := -1
+		switch {
+		case policy.OwnerId == "*":
+			policyRank = 0
+		case policy.OwnerId == ownerId && policy.Qualifier == "":
+			policyRank = 1
+		case policy.OwnerId == ownerId && policy.Qualifier == string(qualifier):
+			policyRank = 2
+		}
+		if policyRank > rank {
+			rank, matched = policyRank, policy
+		}
+	}
+
+	var resolutions []uint64
+	for _, resolution := range matched.Resolutions {
+		resolutions = append(resolutions, uint64(resolution))
+	}
+	return resolutions
+}
+
+// encodeResolutions는 resolution 개수와 resolution을 차례로 담음. resolution이 없어도 빈 value가 되지 않음.
+func encodeResolutions(resolutions []uint64) []byte {
+	value := make([]byte, binary.MaxVarintLen64)
+	value = value[:binary.PutUvarint(value, uint64(len(resolutions)))]
+	for _, resolution := range resolutions {
+		value = append(value, uint64Bytes(resolution)...)
+	}
+	return value
+}
+
+// getResolutions는 reader에 저장된 series의 resolution을 return. 저장된 resolution이 없으면 false를 return.
+func getResolutions(reader db.Reader, seriesId uint64) ([]uint64, bool, error) {
+	slice, err := reader.GetDataFromColumnFamily(consts.RollupCFNum, rollupPolicyKey(seriesId))
+	if err != nil {
+		return nil, false, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	if !slice.Exists() {
+		return nil, false, nil
+	}
+
+	value := slice.Data()
+	count, n := binary.Uvarint(value)
+	if n <= 0 || uint64(len(value)-n) != count*8 {
+		return nil, false, errors.Errorf("wrong rollup policy of series %v", seriesId)
+	}
+	var resolutions []uint64
+	for i := uint64(0); i < count; i++ {
+		resolutions = append(resolutions, binary.BigEndian.Uint64(value[n+int(i)*8:]))
+	}
+	return resolutions, true, nil
+}
+
+// seriesResolutions는 series의 resolution을 return. 처음 rollup되는 series이면 config의 policy로 정하여 block batch에 담고
+// 이미 chunk에 저장된 point의 bucket도 다시 집계하도록 표시함. DeliverTx에서만 호출해야 함.
+func (app *MasterApplication) seriesResolutions(seriesId uint64, ownerId string, qualifier []byte) ([]uint64, error) {
+	if resolutions, ok := app.blockResolutions[seriesId]; ok {
+		return resolutions, nil
+	}
+
+	resolutions, ok, err := getResolutions(app.db, seriesId)
+	if err != nil {
+		return nil, err
+	}
+	if app.blockResolutions == nil {
+		app.blockResolutions = make(map[uint64][]uint64)
+	}
+	app.blockResolutions[seriesId] = resolutions
+	if ok {
+		return resolutions, nil
+	}
+
+	resolutions = matchRollupPolicy(app.rollupPolicies, ownerId, qualifier)
+	app.blockResolutions[seriesId] = resolutions
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.RollupCFNum], rollupPolicyKey(seriesId), encodeResolutions(resolutions))
+	if len(resolutions) == 0 {
+		return nil, nil
+	}
+
+	// rollup policy가 저장되기 전에 write된 chunk도 rollup에 포함함
+	itr := app.db.IteratorColumnFamily(uint64Bytes(seriesId), nil, app.db.ColumnFamilyHandles()[consts.ChunkCFNum])
+	defer itr.Close()
+	for ; itr.Valid() && bytes.HasPrefix(itr.Key(), uint64Bytes(seriesId)); itr.Next() {
+		points, err := tsz.Decode(itr.Value())
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode chunk %X failed", itr.Key())
+		}
+		for _, point := range points {
+			app.markBuckets(seriesId, resolutions, point.T)
+		}
+	}
+
+	return resolutions, nil
+}
+
+// markRollups는 series의 timestamp가 속한 rollup bucket을 Commit에서 다시 집계하도록 표시함.
+func (app *MasterApplication) markRollups(seriesId uint64, ownerId string, qualifier []byte, timestamp uint64) error {
+	resolutions, err := app.seriesResolutions(seriesId, ownerId, qualifier)
+	if err != nil {
+		return err
+	}
+
+	app.markBuckets(seriesId, resolutions, timestamp)
+	return nil
+}
+
+func (app *MasterApplication) markBuckets(seriesId uint64, resolutions []uint64, timestamp uint64) {
+	if app.blockRollups == nil {
+		app.blockRollups = make(map[string]struct{})
+	}
+	for _, resolution := range resolutions {
+		app.blockRollups[string(rollupBucketKey(seriesId, resolution, timestamp-timestamp%resolution))] = struct{}{}
+	}
+}
+
+// setRollups는 현재 block에서 수정된 chunk를 반영하여 표시된 rollup bucket을 다시 집계하고 block batch에 담음.
+// bucket의 point가 모두 제거되면 Count가 0인 bucket을 담음. Commit에서 block batch를 write하기 전에 호출해야 함.
+func (app *MasterApplication) setRollups() error {
+	for key := range app.blockRollups {
+		seriesId := binary.BigEndian.Uint64([]byte(key[len(rollupBucketPrefix):]))
+		resolution := binary.BigEndian.Uint64([]byte(key[len(rollupBucketPrefix)+8:]))
+		bucketStart := binary.BigEndian.Uint64([]byte(key[len(rollupBucketPrefix)+16:]))
+
+		points, err := app.chunkPoints(app.db, app.blockChunks, seriesId, bucketStart, bucketStart+resolution)
+		if err != nil {
+			return err
+		}
+		bucket := types.BucketObj{Start: bucketStart}
+		if buckets := aggregate(points, bucketStart, resolution); len(buckets) > 0 {
+			bucket = buckets[0]
+		}
+		value, err := json.Marshal(bucket)
+		if err != nil {
+			return errors.Wrap(err, "marshal rollup bucket failed")
+		}
+		app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.RollupCFNum], []byte(key), value)
+	}
+
+	return nil
+}
+
+// rangeResolution은 rangeQueryObj의 bucket을 정확히 나누는 series resolution 중 가장 큰 resolution을 return.
+// Start, End, Step이 모두 resolution의 배수여야 하며 그런 resolution이 없으면 0을 return.
+func rangeResolution(reader db.Reader, seriesId uint64, rangeQueryObj types.RangeQueryObj) (uint64, error) {
+	resolutions, _, err := getResolutions(reader, seriesId)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(resolutions) - 1; i >= 0; i-- {
+		resolution := resolutions[i]
+		if rangeQueryObj.Step%resolution == 0 && rangeQueryObj.Start%resolution == 0 && rangeQueryObj.End%resolution == 0 {
+			return resolution, nil
+		}
+	}
+	return 0, nil
+}
+
+// rollupBuckets는 series의 resolution rollup bucket을 rangeQueryObj의 Step 간격 bucket으로 합쳐 return하며 read한 rollup bucket 수도 return.
+func rollupBuckets(reader db.Reader, seriesId uint64, resolution uint64, rangeQueryObj types.RangeQueryObj) ([]types.BucketObj, int, error) {
+	startKey := rollupBucketKey(seriesId, resolution, rangeQueryObj.Start)
+	endKey := rollupBucketKey(seriesId, resolution, rangeQueryObj.End)
+	itr := reader.IteratorColumnFamily(startKey, endKey, reader.ColumnFamilyHandles()[consts.RollupCFNum])
+	defer itr.Close()
+
+	var buckets []types.BucketObj
+	scanned := 0
+	for ; itr.Valid() && bytes.Compare(itr.Key(), endKey) == -1; itr.Next() {
+		var bucket types.BucketObj
+		if err := json.Unmarshal(itr.Value(), &bucket); err != nil {
+			return nil, 0, errors.Wrapf(err, "rollup bucket %X unmarshal err", itr.Key())
+		}
+		scanned++
+		if bucket.Count == 0 {
+			continue
+		}
+		buckets = mergeBucket(buckets, rangeQueryObj.Start+(bucket.Start-rangeQueryObj.Start)/rangeQueryObj.Step*rangeQueryObj.Step, bucket)
+	}
+
+	return buckets, scanned, nil
+}
@@ -0,0 +1,65 @@
+package master_test
+
+import (
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"time"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_rollup() {
+	require := suite.Require()
+
+	//given
+	// owner1 series는 10초 rollup을, owner2 series는 rollup을 사용하지 않는 app에 1초 chunk로 숫자 데이터를 write함
+	configure := func(policies ...string) func(cfg *config.Config) {
+		return func(cfg *config.Config) {
+			cfg.Features.ChunkStorage = true
+			cfg.DB.ChunkInterval = time.Second
+			cfg.Rollup.Policies = policies
+		}
+	}
+	suite.openApp(configure("*=10s", TestOwnerId2+"="))
+
+	start := uint64(1545982860000000000)
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.deliver(givenDataObj(start, TestOwnerId, "temperature", "1"), givenDataObj(start+uint64(5*time.Second), TestOwnerId, "temperature", "2"), givenDataObj(start+uint64(12*time.Second), TestOwnerId, "temperature", "4"), givenDataObj(start, TestOwnerId2, "temperature", "10"))
+	suite.app.Commit()
+	suite.deliver(givenDataObj(start+uint64(7*time.Second), TestOwnerId, "temperature", "3"))
+	suite.app.Commit()
+
+	end := start + uint64(20*time.Second)
+
+	//when
+	actual := suite.queryRange(types.RangeQueryObj{Start: start, End: end, Step: uint64(20 * time.Second)})
+
+	//then
+	// step을 나누는 rollup이 있는 series는 rollup bucket을 합쳐서, 없는 series는 원본 point로 집계함
+	series1 := types.SeriesObj{Id: 1, OwnerId: TestOwnerId, Qualifier: []byte("temperature")}
+	series2 := types.SeriesObj{Id: 2, OwnerId: TestOwnerId2, Qualifier: []byte("temperature")}
+	require.Equal([]types.RangeResObj{
+		{Series: series1, Resolution: uint64(10 * time.Second), Buckets: []types.BucketObj{{Start: start, Count: 4, Sum: 10, Min: 1, Max: 4, First: 1, Last: 4}}},
+		{Series: series2, Buckets: []types.BucketObj{{Start: start, Count: 1, Sum: 10, Min: 10, Max: 10, First: 10, Last: 10}}},
+	}, actual)
+
+	// step이 rollup resolution의 배수가 아니면 원본 point로 집계함
+	actual = suite.queryRange(types.RangeQueryObj{Start: start, End: end, OwnerId: TestOwnerId, Step: uint64(3 * time.Second)})
+	require.Equal(uint64(0), actual[0].Resolution)
+	require.Equal(4, len(actual[0].Buckets))
+
+	// commit한 뒤에는 rollup policy를 바꿔서 시작할 수 없으며 순서만 다른 같은 policy로는 시작할 수 있음
+	require.NotNil(suite.reopenApp(configure("*=1s")))
+	suite.openApp(configure(TestOwnerId2+"=", "*=10s"))
+
+	// overwrite된 데이터는 rollup에서도 대체됨
+	overwriteObj := givenDataObj(start+uint64(5*time.Second), TestOwnerId, "temperature", "2")
+	overwriteObj.RealData.Data = []byte("6")
+	overwriteObj.Conflict = consts.ConflictOverwrite
+	suite.deliver(overwriteObj)
+	suite.app.Commit()
+	actual = suite.queryRange(types.RangeQueryObj{Start: start, End: end, OwnerId: TestOwnerId, Step: uint64(20 * time.Second)})
+	require.Equal([]types.RangeResObj{
+		{Series: series1, Resolution: uint64(10 * time.Second), Buckets: []types.BucketObj{{Start: start, Count: 4, Sum: 14, Min: 1, Max: 6, First: 1, Last: 4}}},
+	}, actual)
+}
@@ -0,0 +1,214 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/paust-team/paust-db/types"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// series column family key prefix. seriesKeyPrefix는 ownerId, qualifier로 series id를, seriesIdPrefix는 series id로 series를 찾음.
+var (
+	seriesKeyPrefix = []byte("k")
+	seriesIdPrefix  = []byte("i")
+)
+
+// default column family에 저장하는 다음 series id key
+var nextSeriesIdKey = []byte("nextSeriesId")
+
+// seriesRefMarker는 metadata column family의 value가 json metadata가 아닌 series id 참조임을 나타냄.
+// series 도입 이전 데이터의 value는 json object이므로 '{'로 시작함.
+const seriesRefMarker = byte(0)
+
+// seriesId는 ownerId, qualifier의 series id를 return. 처음 write되는 series이면 새 id를 부여하고 series 등록을 block batch에 담음.
+// DeliverTx에서만 호출해야 함.
+func (app *MasterApplication) seriesId(ownerId string, qualifier []byte) (uint64, error) {
+	key := seriesKey(ownerId, qualifier)
+	id, err := app.lookupSeriesId(key)
+	if err != nil || id != 0 {
+		return id, err
+	}
+
+	value, err := json.Marshal(types.SeriesObj{Id: app.nextSeriesId, OwnerId: ownerId, Qualifier: qualifier})
+	if err != nil {
+		return 0, errors.Wrap(err, "marshal series failed")
+	}
+	id = app.nextSeriesId
+	app.nextSeriesId++
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.SeriesCFNum], key, uint64Bytes(id))
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.SeriesCFNum], seriesIdKey(id), value)
+	app.wb.SetColumnFamily(app.db.ColumnFamilyHandles()[consts.DefaultCFNum], nextSeriesIdKey, uint64Bytes(app.nextSeriesId))
+	if app.blockSeries == nil {
+		app.blockSeries = make(map[string]uint64)
+	}
+	app.blockSeries[string(key)] = id
+
+	return id, nil
+}
+
+// lookupSeriesId는 현재 block에서 등록되었거나 commit된 series key의 id를 return. 등록되지 않은 series이면 0을 return.
+func (app *MasterApplication) lookupSeriesId(key []byte) (uint64, error) {
+	if id, ok := app.blockSeries[string(key)]; ok {
+		return id, nil
+	}
+
+	return getUint64(app.db, consts.SeriesCFNum, key)
+}
+
+// seriesResolver는 metadata column family의 value를 ownerId, qualifier로 변환함. 한 번 read한 series는 다시 read하지 않음.
+type seriesResolver struct {
+	reader db.Reader
+	series map[uint64]types.SeriesObj
+}
+
+func newSeriesResolver(reader db.Reader) *seriesResolver {
+	return &seriesResolver{reader: reader, series: make(map[uint64]types.SeriesObj)}
+}
+
+// resolve는 series id 참조이면 series를, series 도입 이전의 json metadata이면 그 값을 return.
+func (resolver *seriesResolver) resolve(metaValue []byte) (ownerId string, qualifier []byte, err error) {
+	if len(metaValue) == 0 || metaValue[0] != seriesRefMarker {
+		var legacyValue struct {
+			OwnerId   string `json:"ownerId"`
+			Qualifier []byte `json:"qualifier"`
+		}
+		if err := json.Unmarshal(metaValue, &legacyValue); err != nil {
+			return "", nil, errors.Wrap(err, "metaValue unmarshal err")
+		}
+		return legacyValue.OwnerId, legacyValue.Qualifier, nil
+	}
+
+	id, n := binary.Uvarint(metaValue[1:])
+	if n <= 0 {
+		return "", nil, errors.Errorf("wrong series reference %X", metaValue)
+	}
+	if seriesObj, ok := resolver.series[id]; ok {
+		return seriesObj.OwnerId, seriesObj.Qualifier, nil
+	}
+
+	slice, err := resolver.reader.GetDataFromColumnFamily(consts.SeriesCFNum, seriesIdKey(id))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	if !slice.Exists() {
+		return "", nil, errors.Errorf("series %v not found", id)
+	}
+	var seriesObj types.SeriesObj
+	if err := json.Unmarshal(slice.Data(), &seriesObj); err != nil {
+		return "", nil, errors.Wrap(err, "series unmarshal err")
+	}
+	resolver.series[id] = seriesObj
+
+	return seriesObj.OwnerId, seriesObj.Qualifier, nil
+}
+
+// seriesQuery는 reader에 등록된 series 중 seriesQueryObj 조건에 맞는 series를 id 순서로 read함.
+func (app *MasterApplication) seriesQuery(reader db.Reader, seriesQueryObj types.SeriesQueryObj) ([]types.SeriesObj, error) {
+	if len(seriesQueryObj.OwnerId) > consts.OwnerIdLenLimit {
+		return nil, errors.Errorf("OwnerId must be %v or below", consts.OwnerIdLenLimit)
+	}
+
+	// ownerId가 주어지면 해당 owner의 series key만 read함
+	prefix := seriesIdPrefix
+	if seriesQueryObj.OwnerId != "" {
+		prefix = seriesKey(seriesQueryObj.OwnerId, nil)
+	}
+	itr := reader.IteratorColumnFamily(prefix, nil, reader.ColumnFamilyHandles()[consts.SeriesCFNum])
+	defer itr.Close()
+
+	var seriesObjs []types.SeriesObj
+	for ; itr.Valid() && bytes.HasPrefix(itr.Key(), prefix); itr.Next() {
+		var seriesObj types.SeriesObj
+		if seriesQueryObj.OwnerId == "" {
+			if err := json.Unmarshal(itr.Value(), &seriesObj); err != nil {
+				return nil, errors.Wrap(err, "series unmarshal err")
+			}
+		} else {
+			if len(itr.Value()) != 8 {
+				return nil, errors.Errorf("wrong series id length of %X. Expect 8, got %v", itr.Key(), len(itr.Value()))
+			}
+			seriesObj.Id = binary.BigEndian.Uint64(itr.Value())
+			seriesObj.OwnerId = seriesQueryObj.OwnerId
+			seriesObj.Qualifier = append([]byte{}, itr.Key()[len(prefix):]...)
+		}
+
+		if len(seriesQueryObj.Qualifier) == 0 || bytes.Equal(seriesObj.Qualifier, seriesQueryObj.Qualifier) {
+			seriesObjs = append(seriesObjs, seriesObj)
+		}
+	}
+	if seriesQueryObj.OwnerId != "" {
+		sort.Slice(seriesObjs, func(i, j int) bool { return seriesObjs[i].Id < seriesObjs[j].Id })
+	}
+
+	return seriesObjs, nil
+}
+
+// matchSeries는 reader에 등록된 series 중 ownerIds, qualifiers 중 하나와 일치하는 series를 id 순서로 read함.
+// ownerIds, qualifiers가 비어 있으면 모든 값과 일치하며 ownerIds가 주어지면 해당 owner의 series key만 read함.
+func (app *MasterApplication) matchSeries(reader db.Reader, ownerIds []string, qualifiers [][]byte) ([]types.SeriesObj, error) {
+	var seriesObjs []types.SeriesObj
+	if len(ownerIds) == 0 {
+		all, err := app.seriesQuery(reader, types.SeriesQueryObj{})
+		if err != nil {
+			return nil, err
+		}
+		seriesObjs = all
+	} else {
+		seen := make(map[string]bool)
+		for _, ownerId := range ownerIds {
+			if ownerId == "" || seen[ownerId] {
+				continue
+			}
+			seen[ownerId] = true
+			ownerSeries, err := app.seriesQuery(reader, types.SeriesQueryObj{OwnerId: ownerId})
+			if err != nil {
+				return nil, err
+			}
+			seriesObjs = append(seriesObjs, ownerSeries...)
+		}
+		sort.Slice(seriesObjs, func(i, j int) bool { return seriesObjs[i].Id < seriesObjs[j].Id })
+	}
+	if len(qualifiers) == 0 {
+		return seriesObjs, nil
+	}
+
+	qualifierSet := make(map[string]bool)
+	for _, qualifier := range qualifiers {
+		qualifierSet[string(qualifier)] = true
+	}
+	var matched []types.SeriesObj
+	for _, seriesObj := range seriesObjs {
+		if qualifierSet[string(seriesObj.Qualifier)] {
+			matched = append(matched, seriesObj)
+		}
+	}
+	return matched, nil
+}
+
+// seriesRef는 metadata column family에 저장하는 series id 참조.
+func seriesRef(id uint64) []byte {
+	ref := make([]byte, 1+binary.MaxVarintLen64)
+	ref[0] = seriesRefMarker
+	n := binary.PutUvarint(ref[1:], id)
+	return ref[:1+n]
+}
+
+// seriesKey는 ownerId, qualifier로 series id를 찾는 key. 같은 owner의 series가 모이도록 uvarint로 encode한 ownerId 길이와 ownerId를 앞에 둠.
+// 128 byte 미만의 ownerId 길이는 1 byte로 encode됨.
+func seriesKey(ownerId string, qualifier []byte) []byte {
+	key := make([]byte, 0, len(seriesKeyPrefix)+binary.MaxVarintLen64+len(ownerId)+len(qualifier))
+	key = append(key, seriesKeyPrefix...)
+	var length [binary.MaxVarintLen64]byte
+	key = append(key, length[:binary.PutUvarint(length[:], uint64(len(ownerId)))]...)
+	key = append(key, ownerId...)
+	return append(key, qualifier...)
+}
+
+func seriesIdKey(id uint64) []byte {
+	return append(append([]byte{}, seriesIdPrefix...), uint64Bytes(id)...)
+}
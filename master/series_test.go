@@ -0,0 +1,101 @@
+package master_test
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"strings"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Query_series() {
+	require := suite.Require()
+
+	//given
+	// 같은 series의 데이터 두 개와 다른 series의 데이터 하나를 두 block에 나누어 write함
+	suite.TestMasterApplication_InitChain()
+	sameSeriesObj := givenBaseDataObj1
+	sameSeriesRowKey := types.GetRowKey(1545982882435375002, 0)
+	sameSeriesObj.MetaData.RowKey, sameSeriesObj.RealData.RowKey = sameSeriesRowKey, sameSeriesRowKey
+	givenTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj1, sameSeriesObj})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	suite.app.Commit()
+	givenTx, err = json.Marshal([]types.BaseDataObj{givenBaseDataObj2})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	suite.app.Commit()
+
+	querySeries := func(seriesQueryObj types.SeriesQueryObj) []types.SeriesObj {
+		seriesData, err := json.Marshal(seriesQueryObj)
+		require.Nil(err)
+		res := suite.app.Query(abciTypes.RequestQuery{Data: seriesData, Path: consts.SeriesPath})
+		require.Equal(code.CodeTypeOK, res.Code, res.Log)
+		var seriesObjs []types.SeriesObj
+		require.Nil(json.Unmarshal(res.Value, &seriesObjs))
+		return seriesObjs
+	}
+
+	//when
+	allSeries := querySeries(types.SeriesQueryObj{})
+
+	//then
+	// series는 처음 write된 순서대로 1부터 id를 부여받음
+	series1 := types.SeriesObj{Id: 1, OwnerId: TestOwnerId, Qualifier: givenMetaDataObj1.Qualifier}
+	series2 := types.SeriesObj{Id: 2, OwnerId: TestOwnerId2, Qualifier: givenMetaDataObj2.Qualifier}
+	require.Equal([]types.SeriesObj{series1, series2}, allSeries)
+	require.Equal([]types.SeriesObj{series2}, querySeries(types.SeriesQueryObj{OwnerId: TestOwnerId2}))
+	require.Equal([]types.SeriesObj{series1}, querySeries(types.SeriesQueryObj{Qualifier: givenMetaDataObj1.Qualifier}))
+	require.Equal(0, len(querySeries(types.SeriesQueryObj{OwnerId: TestOwnerId, Qualifier: givenMetaDataObj2.Qualifier})))
+
+	// series id로 저장된 metadata도 ownerId, qualifier로 read됨
+	queryData, err := json.Marshal(types.QueryObj{Start: 1545982882435375000, End: 1545982882435375003, Qualifier: []byte{}})
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: queryData, Path: consts.QueryPath})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	var metaDataObjs []types.MetaDataObj
+	require.Nil(json.Unmarshal(res.Value, &metaDataObjs))
+	require.Equal([]types.MetaDataObj{givenMetaDataObj1, givenMetaDataObj2, sameSeriesObj.MetaData}, metaDataObjs)
+}
+
+func (suite *MasterSuite) TestMasterApplication_DeliverTx_invalidOwnerId() {
+	require := suite.Require()
+
+	//given
+	suite.TestMasterApplication_InitChain()
+	// ownerId 길이가 256 byte 이상이면 길이 prefix가 다른 owner의 series key와 겹칠 수 있으므로 제한보다 긴 ownerId는 거부됨
+	longOwnerObj := givenBaseDataObj1
+	longOwnerObj.MetaData.OwnerId = TestOwnerId + strings.Repeat("a", 256)
+	emptyOwnerObj := givenBaseDataObj1
+	emptyOwnerObj.MetaData.OwnerId = ""
+	limitOwnerObj := givenBaseDataObj1
+	limitOwnerObj.MetaData.OwnerId = strings.Repeat("a", consts.OwnerIdLenLimit)
+
+	for _, baseDataObj := range []types.BaseDataObj{longOwnerObj, emptyOwnerObj} {
+		givenTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObj2, baseDataObj})
+		require.Nil(err)
+
+		//when
+		checkRes := suite.app.CheckTx(givenTx)
+		deliverRes := suite.app.DeliverTx(givenTx)
+
+		//then
+		require.Equal(consts.CodeTypeInvalidOwnerId, checkRes.Code)
+		require.Equal(consts.CodeTypeInvalidOwnerId, deliverRes.Code)
+	}
+
+	// 제한 길이의 ownerId는 write됨
+	givenTx, err := json.Marshal([]types.BaseDataObj{limitOwnerObj})
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	suite.app.Commit()
+
+	seriesData, err := json.Marshal(types.SeriesQueryObj{})
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: seriesData, Path: consts.SeriesPath})
+	require.Equal(code.CodeTypeOK, res.Code, res.Log)
+	var seriesObjs []types.SeriesObj
+	require.Nil(json.Unmarshal(res.Value, &seriesObjs))
+	require.Equal([]types.SeriesObj{{Id: 1, OwnerId: limitOwnerObj.MetaData.OwnerId, Qualifier: givenMetaDataObj1.Qualifier}}, seriesObjs)
+}
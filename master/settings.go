@@ -0,0 +1,69 @@
+package master
+
+import (
+	"encoding/json"
+	"github.com/paust-team/paust-db/config"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/db"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// storageSettingsKey는 chunk, rollup 설정을 저장하는 default column family key.
+var storageSettingsKey = []byte("storageSettings")
+
+// storageSettings는 chunk, rollup 저장 방식을 정하는 설정. chunk, rollup은 app hash에 포함되지 않으므로
+// node마다 설정이 다르거나 도중에 설정을 바꾸면 같은 range query의 결과가 달라짐.
+// 처음 commit할 때 DB에 저장하며 이후 config가 저장된 설정과 다르면 시작하지 않음.
+type storageSettings struct {
+	ChunkStorage   bool     `json:"chunkStorage"`
+	ChunkInterval  uint64   `json:"chunkInterval,omitempty"`
+	RollupPolicies []string `json:"rollupPolicies,omitempty"`
+}
+
+// newStorageSettings는 cfg의 chunk, rollup 설정을 return. chunk storage가 꺼져 있으면 chunk interval과 rollup policy는 사용되지 않으므로 비워 둠.
+// rollup policy는 순서와 표기에 관계없이 비교할 수 있도록 selector 순서로 정렬하여 다시 씀.
+func newStorageSettings(cfg *config.Config, policies []config.RollupPolicy) storageSettings {
+	if !cfg.Features.ChunkStorage {
+		return storageSettings{}
+	}
+
+	settings := storageSettings{ChunkStorage: true, ChunkInterval: uint64(cfg.DB.ChunkInterval)}
+	for _, policy := range policies {
+		selector := policy.OwnerId
+		if policy.Qualifier != "" {
+			selector += "/" + policy.Qualifier
+		}
+		var resolutions []string
+		for _, resolution := range policy.Resolutions {
+			resolutions = append(resolutions, resolution.String())
+		}
+		settings.RollupPolicies = append(settings.RollupPolicies, selector+"="+strings.Join(resolutions, " "))
+	}
+	sort.Strings(settings.RollupPolicies)
+
+	return settings
+}
+
+// checkStorageSettings는 DB에 저장된 설정이 settings와 같은지 확인함. 저장된 설정이 없으면 false를 return.
+func checkStorageSettings(database db.Reader, settings storageSettings) (bool, error) {
+	slice, err := database.GetDataFromColumnFamily(consts.DefaultCFNum, storageSettingsKey)
+	if err != nil {
+		return false, errors.Wrap(err, "GetDataFromColumnFamily err")
+	}
+	defer slice.Free()
+	if !slice.Exists() {
+		return false, nil
+	}
+
+	var stored storageSettings
+	if err := json.Unmarshal(slice.Data(), &stored); err != nil {
+		return false, errors.Wrap(err, "wrong storage settings")
+	}
+	if !reflect.DeepEqual(stored, settings) {
+		return true, errors.Errorf("chunk_storage, chunk_interval, rollup policies %+v differ from the settings stored at the first commit %+v. These settings cannot be changed", settings, stored)
+	}
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package master_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/paust-team/paust-db/libs/log"
+	"github.com/paust-team/paust-db/master"
+	"github.com/paust-team/paust-db/types"
+	"github.com/tendermint/tendermint/abci/example/code"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"time"
+)
+
+func (suite *MasterSuite) TestMasterApplication_Shutdown() {
+	require := suite.Require()
+
+	//given
+	// block 1은 commit되고 block 2는 commit되기 전에 ABCI server가 멈춤
+	givenTx, err := json.Marshal(givenBaseDataObjs)
+	require.Nil(err)
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 1}})
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+	suite.app.Commit()
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 2}})
+	secondTx, err := json.Marshal([]types.BaseDataObj{givenBaseDataObjs[0]})
+	require.Nil(err)
+	suite.app.DeliverTx(secondTx)
+
+	//when
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = suite.app.Shutdown(ctx)
+
+	//then
+	require.Nil(err)
+	require.False(suite.app.Health().DBOpen)
+
+	// close 이후의 CheckTx, Query는 error, DeliverTx와 Commit은 결과가 block에 기록되지 않도록 panic
+	require.Equal(code.CodeTypeUnknownError, suite.app.CheckTx(givenTx).Code)
+	require.Equal(code.CodeTypeUnknownError, suite.app.Query(abciTypes.RequestQuery{Path: consts.QueryPath}).Code)
+	require.Panics(func() { suite.app.DeliverTx(givenTx) })
+	require.Panics(func() { suite.app.Commit() })
+
+	// 재시작 후 block 1의 데이터를 read하며 block 2는 tendermint가 다시 replay하도록 last height가 1임
+	suite.app, err = master.NewMasterApplication(true, testDir, log.AllowDebug())
+	require.Nil(err)
+	require.Equal(2, len(suite.queryMetaData()))
+	require.Equal(int64(1), suite.app.Info(abciTypes.RequestInfo{}).LastBlockHeight)
+}
+
+func (suite *MasterSuite) TestMasterApplication_Shutdown_uncommittedBlock() {
+	require := suite.Require()
+
+	//given
+	suite.app.InitChain(abciTypes.RequestInitChain{})
+	suite.app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: 1}})
+	givenTx, err := json.Marshal(givenBaseDataObjs)
+	require.Nil(err)
+	require.Equal(code.CodeTypeOK, suite.app.DeliverTx(givenTx).Code)
+
+	//when
+	err = suite.app.Shutdown(context.Background())
+
+	//then
+	require.Nil(err)
+
+	// shutdown 이후 도착한 Commit은 빈 app hash를 return하지 않고 panic
+	require.Panics(func() { suite.app.Commit() })
+
+	// commit되지 않은 block의 데이터는 write되지 않으며 재시작 시 block 1부터 replay됨
+	suite.app, err = master.NewMasterApplication(true, testDir, log.AllowDebug())
+	require.Nil(err)
+	require.Equal(0, len(suite.queryMetaData()))
+	require.Equal(int64(0), suite.app.Info(abciTypes.RequestInfo{}).LastBlockHeight)
+}
+
+func (suite *MasterSuite) queryMetaData() []types.MetaDataObj {
+	require := suite.Require()
+
+	queryObj := types.QueryObj{Start: 1545982882435375000, End: 1545982882435375002, Qualifier: []byte{}}
+	queryData, err := json.Marshal(queryObj)
+	require.Nil(err)
+	res := suite.app.Query(abciTypes.RequestQuery{Data: queryData, Path: consts.QueryPath})
+	require.Equal(code.CodeTypeOK, res.Code)
+
+	var metaDataObjs []types.MetaDataObj
+	require.Nil(json.Unmarshal(res.Value, &metaDataObjs))
+
+	return metaDataObjs
+}
@@ -2,6 +2,10 @@ package types
 
 import (
 	"encoding/binary"
+	"github.com/paust-team/paust-db/consts"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"time"
 )
 
 type MetaDataObj struct {
@@ -15,26 +19,220 @@ type RealDataObj struct {
 	Data   []byte `json:"data"`
 }
 
+// BaseDataObj는 put tx에 담기는 write model. Conflict는 rowKey가 이미 write되어 있을 때의 처리 방법(consts.ConflictReject |
+// consts.ConflictOverwrite | consts.ConflictKeepFirst)이며 비어 있으면 consts.ConflictReject임.
 type BaseDataObj struct {
 	MetaData MetaDataObj `json:"meta"`
 	RealData RealDataObj `json:"real"`
+	Conflict string      `json:"conflict,omitempty"`
 }
 
-type QueryObj struct {
+// HistoryObj는 overwrite로 대체된 이전 데이터. Height는 데이터가 대체된 block height임.
+type HistoryObj struct {
+	RowKey    []byte `json:"rowKey"`
+	OwnerId   string `json:"ownerId"`
+	Qualifier []byte `json:"qualifier"`
+	Data      []byte `json:"data"`
+	Height    int64  `json:"height"`
+}
+
+// SeriesObj는 ownerId와 qualifier가 같은 데이터의 묶음인 series. Id는 series가 처음 write될 때 부여되는 고유한 id임.
+type SeriesObj struct {
+	Id        uint64 `json:"id"`
+	OwnerId   string `json:"ownerId"`
+	Qualifier []byte `json:"qualifier"`
+}
+
+// SeriesQueryObj는 /series의 read model. OwnerId, Qualifier가 비어 있으면 모든 값과 일치함.
+type SeriesQueryObj struct {
+	OwnerId   string `json:"ownerId"`
+	Qualifier []byte `json:"qualifier"`
+}
+
+// CountResObj는 /count의 response model.
+// Count는 조건에 맞는 데이터 수이며 Series, Owners, Qualifiers는 그 데이터의 서로 다른 series, ownerId, qualifier 수.
+type CountResObj struct {
+	Count      uint64 `json:"count"`
+	Series     uint64 `json:"series"`
+	Owners     uint64 `json:"owners"`
+	Qualifiers uint64 `json:"qualifiers"`
+}
+
+// PutResObj는 put tx의 DeliverTx response data. Skipped는 keep-first 혹은 같은 데이터의 재전송이어서 write하지 않은 데이터의 tx 안 index이며
+// 모든 데이터를 write했으면 DeliverTx response data는 비어 있음.
+type PutResObj struct {
+	Skipped []int `json:"skipped"`
+}
+
+// QLQueryObj는 /ql의 read model. Query는 libs/ql 문법의 query 문자열.
+type QLQueryObj struct {
+	Query string `json:"query"`
+}
+
+// QLResObj는 /ql의 response model. SELECT * query는 Data를, 집계 query는 Columns와 group마다 Columns 순서로 집계한 Groups를 return.
+type QLResObj struct {
+	Columns []string      `json:"columns,omitempty"`
+	Groups  []QLGroupObj  `json:"groups,omitempty"`
+	Data    []BaseDataObj `json:"data,omitempty"`
+}
+
+// QLGroupObj는 집계 query의 group 하나. Time은 group의 시작 시각이며 GROUP BY time이 없으면 query의 시작 시각임.
+// OwnerId는 GROUP BY owner일 때만 채워짐.
+type QLGroupObj struct {
+	Time    uint64    `json:"time"`
+	OwnerId string    `json:"ownerId,omitempty"`
+	Values  []float64 `json:"values"`
+}
+
+// LatestQueryObj는 /latest의 read model. OwnerIds, Qualifiers 중 하나와 일치하는 series마다 가장 최신 데이터를 read하며
+// OwnerIds, Qualifiers가 비어 있으면 모든 값과 일치함.
+type LatestQueryObj struct {
+	OwnerIds   []string `json:"ownerIds"`
+	Qualifiers [][]byte `json:"qualifiers"`
+}
+
+// RangeQueryObj는 /range의 read model. Start 이상 End 미만의 time range에서 OwnerId, Qualifier 조건에 맞는 series의 숫자 데이터를 read함.
+// Step이 0이면 point를 그대로 return하고 0보다 크면 Start부터 Step 간격의 bucket으로 집계하여 return.
+type RangeQueryObj struct {
 	Start     uint64 `json:"start"`
 	End       uint64 `json:"end"`
 	OwnerId   string `json:"ownerId"`
 	Qualifier []byte `json:"qualifier"`
+	Step      uint64 `json:"step"`
+}
+
+// RangeResObj는 /range의 response model로 series 하나의 point 혹은 bucket을 시간 순서로 담음.
+// Resolution은 bucket 집계에 사용한 rollup의 resolution(nano second)이며 0이면 원본 point로 집계함.
+type RangeResObj struct {
+	Series     SeriesObj   `json:"series"`
+	Resolution uint64      `json:"resolution,omitempty"`
+	Points     []PointObj  `json:"points,omitempty"`
+	Buckets    []BucketObj `json:"buckets,omitempty"`
+}
+
+// PointObj는 숫자 데이터 하나의 timestamp와 value.
+type PointObj struct {
+	Timestamp uint64  `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// BucketObj는 Start부터 Step 동안의 point를 집계한 값. First, Last는 bucket 안에서 가장 이른, 늦은 point의 value임.
+type BucketObj struct {
+	Start uint64  `json:"start"`
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+}
+
+// QueryObj는 /query의 read model. Start 이상 End 미만의 time range에서 ownerId, qualifier 조건에 맞는 데이터를 read함.
+// ownerId는 OwnerId와 OwnerIds 중 하나와 일치해야 하며 OwnerIdPrefix가 주어지면 그 prefix로 시작해야 함.
+// qualifier는 Qualifier와 Qualifiers 중 하나와 일치해야 함. 비어 있는 조건은 모든 값과 일치함.
+type QueryObj struct {
+	Start         uint64   `json:"start"`
+	End           uint64   `json:"end"`
+	OwnerId       string   `json:"ownerId"`
+	Qualifier     []byte   `json:"qualifier"`
+	OwnerIds      []string `json:"ownerIds,omitempty"`
+	Qualifiers    [][]byte `json:"qualifiers,omitempty"`
+	OwnerIdPrefix string   `json:"ownerIdPrefix,omitempty"`
+}
+
+// QueryDataObj는 /querydata의 read model. QueryObj 조건에 더해 Limit이 0보다 크면 최대 Limit개의 데이터만 read하며
+// After가 주어지면 After rowKey 다음 데이터부터 read함.
+type QueryDataObj struct {
+	QueryObj
+	Limit int    `json:"limit"`
+	After []byte `json:"after"`
+}
+
+// QueryDataResObj는 /querydata의 response model. Next가 nil이 아니면 남은 데이터가 있으며 Next를 After로 하여 이어서 read함.
+type QueryDataResObj struct {
+	Data []BaseDataObj `json:"data"`
+	Next []byte        `json:"next"`
 }
 
 type FetchObj struct {
 	RowKeys [][]byte `json:"rowKeys"`
 }
 
+// HealthObj는 master의 /healthz, /readyz response model.
+// Handshaked는 master가 시작된 뒤 tendermint의 Info handshake를 받았는지 여부이며 현재 연결 상태를 뜻하지 않음.
+type HealthObj struct {
+	DBOpen         bool      `json:"dbOpen"`
+	DBWritable     bool      `json:"dbWritable"`
+	LastHeight     int64     `json:"lastHeight"`
+	LastBlockTime  time.Time `json:"lastBlockTime"`
+	SinceLastBlock string    `json:"sinceLastBlock"`
+	Handshaked     bool      `json:"handshaked"`
+	Ready          bool      `json:"ready"`
+}
+
+// app hash는 지금까지 write된 모든 rowKey의 현재 데이터 leaf를 rowKey가 처음 write된 순서대로 놓은 tendermint simple merkle tree의 root이며
+// app hash는 지금까지 write된 모든 row의 leaf를 write 순서대로 놓은 tendermint simple merkle tree의 root이며
+// leaf는 RowLeaf(rowKey, MetaHash, RealHash)임.
+type RowProof struct {
+	Index    int      `json:"index"`
+	Total    int      `json:"total"`
+	MetaHash []byte   `json:"metaHash"`
+	RealHash []byte   `json:"realHash"`
+	Aunts    [][]byte `json:"aunts"`
+}
+
+// ComputeRootHash는 rowKey의 leaf와 proof로 계산한 merkle root를 return. 결과가 app hash와 같으면 검증된 것임.
+func (proof RowProof) ComputeRootHash(rowKey []byte) []byte {
+	simpleProof := merkle.SimpleProof{
+		Total:    proof.Total,
+		Index:    proof.Index,
+		LeafHash: merkle.SimpleHashFromByteSlices([][]byte{RowLeaf(rowKey, proof.MetaHash, proof.RealHash)}),
+		Aunts:    proof.Aunts,
+	}
+	return simpleProof.ComputeRootHash()
+}
+
+// MetaDataHash는 metadata의 hash를 return.
+func MetaDataHash(ownerId string, qualifier []byte) []byte {
+	return merkle.SimpleHashFromByteSlices([][]byte{[]byte(ownerId), qualifier})
+}
+
+// RealDataHash는 실제 데이터의 hash를 return.
+func RealDataHash(data []byte) []byte {
+	return tmhash.Sum(data)
+}
+
+// RowLeaf는 app hash merkle tree의 leaf를 return.
+func RowLeaf(rowKey, metaHash, realHash []byte) []byte {
+	leaf := make([]byte, 0, len(rowKey)+len(metaHash)+len(realHash))
+	leaf = append(leaf, rowKey...)
+	leaf = append(leaf, metaHash...)
+	return append(leaf, realHash...)
+}
+
+// GetRowKey는 timestamp와 salt로 이루어진 이전 방식의 rowKey를 return. 새 데이터는 NewRowKey를 사용해야 함.
 func GetRowKey(timestamp uint64, salt uint16) []byte {
-	rowKey := make([]byte, 10)
+	rowKey := make([]byte, consts.LegacyRowKeyLen)
 	binary.BigEndian.PutUint64(rowKey[0:], timestamp)
 	binary.BigEndian.PutUint16(rowKey[8:], salt)
 
 	return rowKey
 }
+
+// NewRowKey는 timestamp와 ownerId, qualifier, data의 content hash로 이루어진 rowKey를 return.
+// 같은 내용의 데이터는 항상 같은 rowKey를 가지므로 재전송된 데이터는 master에서 다시 write하지 않고 성공으로 처리됨.
+func NewRowKey(timestamp uint64, ownerId string, qualifier, data []byte) []byte {
+	rowKey := make([]byte, 0, consts.RowKeyLen)
+	rowKey = append(rowKey, TimestampKey(timestamp)...)
+	contentHash := tmhash.Sum(append(MetaDataHash(ownerId, qualifier), RealDataHash(data)...))
+
+	return append(rowKey, contentHash[:consts.RowKeyLen-consts.TimestampLen]...)
+}
+
+// TimestampKey는 timestamp를 prefix로 갖는 모든 rowKey보다 작거나 같은 key를 return. time range scan의 경계로 사용함.
+func TimestampKey(timestamp uint64) []byte {
+	key := make([]byte, consts.TimestampLen)
+	binary.BigEndian.PutUint64(key, timestamp)
+
+	return key
+}
@@ -2,6 +2,7 @@ package types_test
 
 import (
 	"encoding/binary"
+	"github.com/paust-team/paust-db/consts"
 	"github.com/paust-team/paust-db/types"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -19,3 +20,20 @@ func TestGetRowKey(t *testing.T) {
 	require.Equal(t, timestamp, ts)
 	require.Equal(t, salt, slt)
 }
+
+func TestNewRowKey(t *testing.T) {
+	require := require.New(t)
+
+	timestamp := uint64(1544772882435375000)
+
+	rowKey := types.NewRowKey(timestamp, "owner1", []byte("qualifier"), []byte("data"))
+
+	require.Equal(consts.RowKeyLen, len(rowKey))
+	require.Equal(timestamp, binary.BigEndian.Uint64(rowKey[0:8]))
+	require.Equal(types.TimestampKey(timestamp), rowKey[0:8])
+	// 같은 내용은 같은 rowKey, 다른 내용은 다른 rowKey를 가짐
+	require.Equal(rowKey, types.NewRowKey(timestamp, "owner1", []byte("qualifier"), []byte("data")))
+	require.NotEqual(rowKey, types.NewRowKey(timestamp, "owner1", []byte("qualifier"), []byte("data2")))
+	require.NotEqual(rowKey, types.NewRowKey(timestamp, "owner2", []byte("qualifier"), []byte("data")))
+	require.NotEqual(rowKey, types.NewRowKey(timestamp, "owner1", []byte("qualifier2"), []byte("data")))
+}